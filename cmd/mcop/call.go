@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"mcop/src/config"
+	"mcop/src/mcp"
+	"mcop/src/types"
+)
+
+// Exit codes of the call command
+const (
+	exitToolError     = 1
+	exitProtocolError = 2
+)
+
+// runCall connects to a server, invokes one tool and prints the result. It
+// returns the process exit code.
+func runCall(serverID, toolName, argsSpec, output string, timeout time.Duration) int {
+	if output != "text" && output != "json" {
+		fmt.Fprintf(os.Stderr, "Unknown output format %q (expected text or json)\n", output)
+		return exitProtocolError
+	}
+
+	arguments, err := readToolArgs(argsSpec, os.Stdin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid arguments: %v\n", err)
+		return exitProtocolError
+	}
+
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return exitProtocolError
+	}
+	server, err := findServer(cfg, serverID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return exitProtocolError
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	client := mcp.NewMCPClient(server)
+	if err := client.ConnectContext(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error connecting to %s: %v\n", server.ID, err)
+		return exitProtocolError
+	}
+	defer client.Disconnect()
+
+	result, err := client.CallTool(ctx, toolName, arguments)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error calling %s: %v\n", toolName, err)
+		return exitProtocolError
+	}
+
+	if output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(result)
+	} else {
+		printToolResult(os.Stdout, result)
+	}
+
+	if result.IsError {
+		fmt.Fprintf(os.Stderr, "Tool %s reported an error\n", toolName)
+		return exitToolError
+	}
+	return 0
+}
+
+// readToolArgs resolves the --args value: inline JSON, @file, or - for stdin.
+// The result must be a JSON object; an empty spec yields an empty object.
+func readToolArgs(spec string, stdin io.Reader) (json.RawMessage, error) {
+	var data []byte
+	var err error
+	switch {
+	case spec == "":
+		return json.RawMessage(`{}`), nil
+	case spec == "-":
+		data, err = io.ReadAll(stdin)
+	case strings.HasPrefix(spec, "@"):
+		data, err = os.ReadFile(spec[1:])
+	default:
+		data = []byte(spec)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data = bytes.TrimSpace(data)
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil || object == nil {
+		return nil, fmt.Errorf("expected a JSON object")
+	}
+	return json.RawMessage(data), nil
+}
+
+// printToolResult writes the result content for humans: text as is, binary
+// content summarized, and structured content pretty-printed
+func printToolResult(w io.Writer, result *mcp.CallToolResult) {
+	for _, content := range result.Content {
+		switch content.Type {
+		case "text":
+			fmt.Fprintln(w, content.Text)
+		case "image", "audio":
+			fmt.Fprintf(w, "[%s %s, %d bytes]\n", content.Type, content.MimeType, content.DataSize())
+		case "resource":
+			if content.Resource == nil {
+				continue
+			}
+			if content.Resource.IsBinary() {
+				fmt.Fprintf(w, "[resource %s, %d bytes]\n", content.Resource.URI, content.Resource.BlobSize())
+			} else {
+				fmt.Fprintln(w, content.Resource.Text)
+			}
+		case "resource_link":
+			fmt.Fprintf(w, "[link %s] %s\n", content.URI, content.Name)
+		default:
+			fmt.Fprintf(w, "[%s content]\n", content.Type)
+		}
+	}
+
+	if len(result.Content) == 0 && len(result.StructuredContent) > 0 {
+		var pretty bytes.Buffer
+		if err := json.Indent(&pretty, result.StructuredContent, "", "  "); err != nil {
+			fmt.Fprintln(w, string(result.StructuredContent))
+		} else {
+			fmt.Fprintln(w, pretty.String())
+		}
+	}
+}
+
+// findServer looks up a configured server by ID and resolves the references
+// in its launch settings
+func findServer(cfg *config.AppConfig, serverID string) (types.MCPServer, error) {
+	for _, server := range cfg.Servers {
+		if server.ID == serverID {
+			return config.ResolveServer(server.RuntimeServer())
+		}
+	}
+	return types.MCPServer{}, fmt.Errorf("Server with ID '%s' not found", serverID)
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"mcop/src/config"
+)
+
+// runConfigShow prints the merged configuration, or with origin the files it
+// was merged from and the file each server and setting came from. It returns
+// the process exit code.
+func runConfigShow(origin bool) int {
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return 1
+	}
+
+	if !origin {
+		data, err := json.MarshalIndent(cfg, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding config: %v\n", err)
+			return 1
+		}
+		fmt.Println(string(data))
+		return 0
+	}
+
+	layers := cfg.Layers()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Files, lowest precedence first:")
+	if len(layers) == 0 {
+		fmt.Fprintln(w, "  (none, using built-in defaults)")
+	}
+	scopes := make(map[string]string, len(layers))
+	for _, layer := range layers {
+		scopes[layer.Path] = layer.Scope
+		fmt.Fprintf(w, "  %s\t%s\n", layer.Scope, layer.Path)
+	}
+	fmt.Fprintf(w, "Changes are saved to %s\n", cfg.Path())
+
+	describe := func(path string) string {
+		if path == "" {
+			return "default\t"
+		}
+		return scopes[path] + "\t" + path
+	}
+	fmt.Fprintln(w, "\nServers:")
+	for _, server := range cfg.Servers {
+		fmt.Fprintf(w, "  %s\t%s\n", server.ID, describe(cfg.Origin(server.ID)))
+	}
+	fmt.Fprintln(w, "\nSettings:")
+	for _, key := range []string{"auto_refresh", "refresh_rate", "default_theme"} {
+		fmt.Fprintf(w, "  %s\t%s\n", key, describe(cfg.SettingOrigin(key)))
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// runConfigValidate checks the named configuration files, or the files the
+// configuration is loaded from, and prints their problems. Loaded files are
+// checked together, so that their servers and profiles may refer to each
+// other. With resolve, references to unset variables and secrets are
+// problems too. It returns the process exit code.
+func runConfigValidate(files []string, resolve bool) int {
+	var resolver *config.Resolver
+	if resolve {
+		resolver = config.NewResolver()
+	}
+
+	if len(files) > 0 {
+		status := 0
+		for _, file := range files {
+			problems, err := config.ValidateFile(file, resolver)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				status = 1
+				continue
+			}
+			status = max(status, printProblems([]string{file}, problems))
+		}
+		return status
+	}
+
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return 1
+	}
+	layers := cfg.Layers()
+	if len(layers) == 0 {
+		fmt.Println("No configuration files found, using built-in defaults")
+		return 0
+	}
+	problems, err := config.ValidateLayers(layers, resolver)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	for _, layer := range layers {
+		files = append(files, layer.Path)
+	}
+	return printProblems(files, problems)
+}
+
+// printProblems prints the problems of each file in files, or that it is
+// valid. It returns 1 if any problem is an error.
+func printProblems(files []string, problems []config.Problem) int {
+	status := 0
+	for _, file := range files {
+		valid := true
+		for _, problem := range problems {
+			if problem.File != file {
+				continue
+			}
+			valid = false
+			fmt.Println(problem)
+			if problem.Severity == config.SeverityError {
+				status = 1
+			}
+		}
+		if valid {
+			fmt.Printf("%s: valid\n", file)
+		}
+	}
+	return status
+}
+
+// runConfigSchema writes the JSON Schema of configuration files to output,
+// or to stdout when output is empty. It returns the process exit code.
+func runConfigSchema(output string) int {
+	data, err := config.SchemaJSON()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if output == "" {
+		os.Stdout.Write(data)
+		return 0
+	}
+	if err := os.WriteFile(output, data, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", output, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"mcop/src/config"
+)
+
+// runImport adds the servers of a Claude Desktop, VS Code or Cursor file to
+// the configuration. It returns the process exit code, which is 1 when
+// conflicting servers were skipped.
+func runImport(path, format string, overwrite, dryRun bool) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", path, err)
+		return 1
+	}
+	if format == "" {
+		if format, err = config.DetectFormat(path, data); err != nil {
+			fmt.Fprintf(os.Stderr, "Error detecting format: %v (use --format)\n", err)
+			return 1
+		}
+	}
+	imported, err := config.ImportServers(data, format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error importing %s: %v\n", path, err)
+		return 1
+	}
+
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return 1
+	}
+	report := cfg.MergeServers(imported, overwrite)
+
+	fmt.Printf("Importing %d servers from %s (%s format)\n", len(imported), path, format)
+	for _, id := range report.Added {
+		fmt.Printf("  added      %s\n", id)
+	}
+	for _, id := range report.Replaced {
+		fmt.Printf("  replaced   %s\n", id)
+	}
+	for _, id := range report.Unchanged {
+		fmt.Printf("  unchanged  %s\n", id)
+	}
+	for _, id := range report.Conflicts {
+		fmt.Printf("  conflict   %s: a different server already has this ID (use --overwrite to replace it)\n", id)
+	}
+	for _, server := range imported {
+		for _, warning := range server.Warnings {
+			fmt.Printf("  warning    %s: %s\n", server.Server.ID, warning)
+		}
+	}
+
+	if !dryRun && len(report.Added)+len(report.Replaced) > 0 {
+		if err := cfg.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving config: %v\n", err)
+			return 1
+		}
+	}
+	// A partial import fails, so scripts notice the servers left out
+	if len(report.Conflicts) > 0 {
+		fmt.Fprintf(os.Stderr, "Error: skipped %d conflicting servers (use --overwrite to replace them)\n", len(report.Conflicts))
+		return 1
+	}
+	return 0
+}
+
+// runExport writes the configured servers in another client's format to
+// output, or stdout when output is empty. It returns the process exit code.
+func runExport(format, output string) int {
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return 1
+	}
+	data, warnings, err := cfg.ExportServers(format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error exporting servers: %v\n", err)
+		return 1
+	}
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "Skipped %s\n", warning)
+	}
+
+	data = append(data, '\n')
+	if output == "" {
+		os.Stdout.Write(data)
+		return 0
+	}
+	if err := os.WriteFile(output, data, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", output, err)
+		return 1
+	}
+	return 0
+}
@@ -3,16 +3,23 @@ package main
 import (
 	"fmt"
 	"os"
-	"time"
 
 	"github.com/spf13/cobra"
 	"mcop/src/config"
 	"mcop/src/discovery"
 	"mcop/src/generator"
-	"mcop/src/model"
+	"mcop/src/mcp"
 	"mcop/src/types"
 )
 
+// configPath is the --config flag: the only configuration file to use
+// instead of the merged system, user and project files
+var configPath string
+
+// profileName is the --profile flag: the configured profile selecting the
+// servers to work with
+var profileName string
+
 var rootCmd = &cobra.Command{
 	Use:   "mcop",
 	Short: "MCOP - MCP Operations Monitor",
@@ -42,19 +49,25 @@ var listCmd = &cobra.Command{
 	Short: "List all configured MCP servers",
 	Long:  `List all configured MCP servers from the configuration`,
 	Run: func(cmd *cobra.Command, args []string) {
-		cfg, err := config.LoadConfig("")
+		cfg, err := config.LoadConfig(configPath)
 		if err != nil {
 			fmt.Printf("Error loading config: %v\n", err)
 			os.Exit(1)
 		}
 
+		servers, err := cfg.ProfileServers(profileName)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
 		fmt.Println("Configured MCP Servers:")
-		for i, server := range cfg.Servers {
+		for i, server := range servers {
 			status := "stopped" // Default status
 			if server.Status != "" {
 				status = server.Status
 			}
-			fmt.Printf("%d. %s (%s) - %s\n", i+1, server.Name, status, server.URL)
+			fmt.Printf("%d. %s (%s) - %s\n", i+1, server.Name, status, server.RuntimeServer().Endpoint())
 		}
 	},
 }
@@ -68,7 +81,7 @@ var addCmd = &cobra.Command{
 		name := args[0]
 		url := args[1]
 
-		cfg, err := config.LoadConfig("")
+		cfg, err := config.LoadConfig(configPath)
 		if err != nil {
 			fmt.Printf("Error loading config: %v\n", err)
 			os.Exit(1)
@@ -99,7 +112,7 @@ var addCmd = &cobra.Command{
 		})
 
 		// Save the updated config
-		err = cfg.SaveConfig("config/default.json")
+		err = cfg.Save()
 		if err != nil {
 			fmt.Printf("Error saving config: %v\n", err)
 			os.Exit(1)
@@ -117,7 +130,7 @@ var removeCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		serverID := args[0]
 
-		cfg, err := config.LoadConfig("")
+		cfg, err := config.LoadConfig(configPath)
 		if err != nil {
 			fmt.Printf("Error loading config: %v\n", err)
 			os.Exit(1)
@@ -131,7 +144,7 @@ var removeCmd = &cobra.Command{
 		}
 
 		// Save the updated config
-		err = cfg.SaveConfig("config/default.json")
+		err = cfg.Save()
 		if err != nil {
 			fmt.Printf("Error saving config: %v\n", err)
 			os.Exit(1)
@@ -144,54 +157,200 @@ var removeCmd = &cobra.Command{
 var runCmd = &cobra.Command{
 	Use:   "run [server-id]",
 	Short: "Run a specific MCP server without TUI",
-	Long:  `Run a specific MCP server directly without the TUI`,
+	Long: `Run a specific stdio MCP server directly without the TUI.
+
+The server is started with its configured environment and working directory,
+signals are forwarded to it, its stderr is prefixed with the server ID, and
+mcop exits with the server's exit status. With --listen, the server's stdio is
+exposed on a local port (tcp://host:port for newline-delimited JSON-RPC,
+http://host:port for Streamable HTTP) instead of mcop's own stdin and stdout.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		listen, _ := cmd.Flags().GetString("listen")
+
+		os.Exit(runServer(args[0], listen))
+	},
+}
+
+var callCmd = &cobra.Command{
+	Use:   "call [server-id] [tool]",
+	Short: "Call a tool on an MCP server without TUI",
+	Long: `Connect to a configured MCP server, call one tool and print the result.
+
+Arguments are a JSON object given inline, read from a file with @path, or
+read from stdin with -. The exit status is 1 if the tool reports an error
+and 2 if the call could not be made.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		toolArgs, _ := cmd.Flags().GetString("args")
+		output, _ := cmd.Flags().GetString("output")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+
+		os.Exit(runCall(args[0], args[1], toolArgs, output, timeout))
+	},
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "Import servers from Claude Desktop, VS Code or Cursor",
+	Long: `Add the MCP servers defined in another client's configuration file.
+
+Supported files are claude_desktop_config.json (claude), .vscode/mcp.json or
+VS Code settings.json (vscode) and .cursor/mcp.json (cursor). The format is
+detected from the file unless --format is given. Servers whose ID is already
+used by a different server are reported as conflicts and skipped unless
+--overwrite is set, and the exit status is then 1. Names that map to the same
+ID get a numbered suffix.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		format, _ := cmd.Flags().GetString("format")
+		overwrite, _ := cmd.Flags().GetBool("overwrite")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		os.Exit(runImport(args[0], format, overwrite, dryRun))
+	},
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export servers for Claude Desktop, VS Code or Cursor",
+	Long: `Print the configured MCP servers in another client's configuration format.
+
+Servers the format cannot express, such as remote servers for Claude Desktop,
+are skipped with a message on stderr.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+
+		os.Exit(runExport(format, output))
+	},
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect and validate the configuration",
+	Long: `Inspect and validate the configuration mcop uses.
+
+Unless --config or $MCOP_CONFIG names a single file, mcop merges, from lowest
+to highest precedence, /etc/mcop/config.json, config.json in mcop's user
+config directory ($XDG_CONFIG_HOME/mcop), config/default.json in the working
+directory and the nearest .mcop/config.json in the working directory or a
+parent. Each file may be JSON, YAML (.yaml, .yml) or TOML (.toml). Changes are
+saved to the file with the highest precedence other than the system file.`,
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the merged configuration",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		origin, _ := cmd.Flags().GetBool("origin")
+
+		os.Exit(runConfigShow(origin))
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate [file...]",
+	Short: "Check configuration files for mistakes",
+	Long: `Check configuration files against the published JSON Schema
+(config/schema.json) and for mistakes the schema cannot express: duplicate
+server IDs, unknown transports and URL schemes, unresolved ${VAR} and
+${secret:name} references, servers claiming the same local port, unknown
+restart policies and profile entries that match no server.
+
+Without arguments the files the configuration is loaded from are checked, with
+ports and profiles checked on the merged configuration so that a file may
+refer to servers defined in another. Problems are printed as file:line:column and the exit status is 1 if any of
+them is an error, so the command can run as a pre-commit hook.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		noResolve, _ := cmd.Flags().GetBool("no-resolve")
+
+		os.Exit(runConfigValidate(args, !noResolve))
+	},
+}
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of configuration files",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		os.Exit(runConfigSchema(output))
+	},
+}
+
+var secretCmd = &cobra.Command{
+	Use:   "secret",
+	Short: "Manage secrets referenced from the configuration",
+	Long: `Manage the values of ${secret:name} references in server commands, args,
+env, cwd, URLs and headers.
+
+Secrets are kept in secrets.json in mcop's user config directory, readable by
+the current user only, or in the file named by $MCOP_SECRETS_FILE.`,
+}
+
+var secretSetCmd = &cobra.Command{
+	Use:   "set [name]",
+	Short: "Store a secret, read from the terminal or stdin",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		serverID := args[0]
+		os.Exit(runSecretSet(args[0]))
+	},
+}
 
-		cfg, err := config.LoadConfig("")
-		if err != nil {
-			fmt.Printf("Error loading config: %v\n", err)
-			os.Exit(1)
-		}
+var secretListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the names of stored secrets",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Exit(runSecretList())
+	},
+}
 
-		// Find the server
-		var targetServer *model.MCPServer
-		for i := range cfg.Servers {
-			if cfg.Servers[i].ID == serverID {
-				// Convert config.MCPServer to model.MCPServer
-				convertedServer := model.MCPServer{
-					ID:                cfg.Servers[i].ID,
-					Name:              cfg.Servers[i].Name,
-					URL:               cfg.Servers[i].URL,
-					Status:            cfg.Servers[i].Status,
-					StartTime:         time.Time{}, // Initialize as zero time
-					ResponseTime:      0, // Initialize as 0 duration
-					ActiveConnections: cfg.Servers[i].ActiveConnections,
-					Description:       cfg.Servers[i].Description,
-					Tools:             cfg.Servers[i].Tools,
-				}
-				targetServer = &convertedServer
-				break
-			}
-		}
+var secretRemoveCmd = &cobra.Command{
+	Use:   "remove [name]",
+	Short: "Remove a stored secret",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Exit(runSecretRemove(args[0]))
+	},
+}
 
-		if targetServer == nil {
-			fmt.Printf("Server with ID '%s' not found\n", serverID)
-			os.Exit(1)
-		}
+var recordCmd = &cobra.Command{
+	Use:   "record [server-id]",
+	Short: "Record a session with an MCP server",
+	Long: `Relay a session between a client on stdin and stdout and a configured MCP
+server, writing every frame with its timing to a JSON lines file.
 
-		// Run the server directly
-		if targetServer.URL[:7] == "stdio://" {
-			command := targetServer.URL[8:] // Remove "stdio://" prefix
-			fmt.Printf("Running server command: %s\n", command)
-			// For now, we'll just print the command - in a real implementation
-			// this would execute the command directly
-			fmt.Printf("This would run: %s\n", command)
-		} else {
-			fmt.Printf("Unsupported protocol for direct execution: %s\n", targetServer.URL[:7])
-			os.Exit(1)
-		}
+Point an MCP client at "mcop record <server-id>" in place of the server
+command. The recording can be served again with "mcop replay".`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		os.Exit(runRecord(args[0], output))
+	},
+}
+
+var replayCmd = &cobra.Command{
+	Use:   "replay [session-file]",
+	Short: "Serve a recorded session as a fake MCP server",
+	Long: `Act as a stdio MCP server answering requests with the responses of a
+session recorded by "mcop record".
+
+Requests are matched to recorded ones by method and params, and requests that
+were not recorded get an error. With --loose, a request whose params match no
+recording gets the next unused recording of the same method.
+Notifications the server sent while handling a request are replayed with it.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		realtime, _ := cmd.Flags().GetBool("realtime")
+		loose, _ := cmd.Flags().GetBool("loose")
+
+		os.Exit(runReplay(args[0], realtime, loose))
 	},
 }
 
@@ -244,7 +403,7 @@ var discoverCmd = &cobra.Command{
 	Long:  `Discover available MCP servers on the local network and from configuration`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Load configuration
-		cfg, err := config.LoadConfig("")
+		cfg, err := config.LoadConfig(configPath)
 		if err != nil {
 			fmt.Printf("Error loading config: %v\n", err)
 			os.Exit(1)
@@ -256,17 +415,7 @@ var discoverCmd = &cobra.Command{
 		// Convert config.MCPServer slice to types.MCPServer slice
 		convertedServers := make([]types.MCPServer, len(cfg.Servers))
 		for i, server := range cfg.Servers {
-			convertedServers[i] = types.MCPServer{
-				ID:                server.ID,
-				Name:              server.Name,
-				URL:               server.URL,
-				Status:            server.Status,
-				StartTime:         time.Time{},
-				ResponseTime:      0,
-				ActiveConnections: server.ActiveConnections,
-				Description:       server.Description,
-				Tools:             server.Tools,
-			}
+			convertedServers[i] = server.RuntimeServer()
 		}
 
 		// Discover all servers
@@ -287,9 +436,50 @@ func init() {
 	rootCmd.AddCommand(addCmd)
 	rootCmd.AddCommand(removeCmd)
 	rootCmd.AddCommand(runCmd)
+	rootCmd.AddCommand(callCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(secretCmd)
+	rootCmd.AddCommand(recordCmd)
+	rootCmd.AddCommand(replayCmd)
 	rootCmd.AddCommand(generateCmd)
 	rootCmd.AddCommand(discoverCmd)
 
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Configuration file to use instead of the system, user and project files")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "Work with the servers of a configured profile only")
+
+	// Add flags for the run command
+	runCmd.Flags().String("listen", "", "Expose the server's stdio on tcp://host:port or http://host:port")
+
+	// Add flags for the call command
+	callCmd.Flags().String("args", "", "Tool arguments as JSON, @file or - for stdin")
+	callCmd.Flags().StringP("output", "o", "text", "Output format (text, json)")
+	callCmd.Flags().Duration("timeout", mcp.DefaultRequestTimeout, "Timeout for connecting and calling the tool")
+
+	configCmd.AddCommand(configShowCmd)
+	configShowCmd.Flags().Bool("origin", false, "Print the file each server and setting came from")
+	configCmd.AddCommand(configValidateCmd)
+	configValidateCmd.Flags().Bool("no-resolve", false, "Do not check that environment variables and secrets are set")
+	configCmd.AddCommand(configSchemaCmd)
+	configSchemaCmd.Flags().StringP("output", "o", "", "File to write instead of stdout")
+
+	secretCmd.AddCommand(secretSetCmd)
+	secretCmd.AddCommand(secretListCmd)
+	secretCmd.AddCommand(secretRemoveCmd)
+
+	// Add flags for the import and export commands
+	importCmd.Flags().String("format", "", "File format (claude, vscode, cursor); detected when empty")
+	importCmd.Flags().Bool("overwrite", false, "Replace servers whose ID is already in use")
+	importCmd.Flags().Bool("dry-run", false, "Report what would be imported without saving")
+	exportCmd.Flags().String("format", "claude", "File format (claude, vscode, cursor)")
+	exportCmd.Flags().StringP("output", "o", "", "File to write instead of stdout")
+
+	// Add flags for the record and replay commands
+	recordCmd.Flags().StringP("output", "o", "session.jsonl", "File to write the session to")
+	replayCmd.Flags().Bool("realtime", false, "Reproduce the recorded response times")
+	replayCmd.Flags().Bool("loose", false, "Answer requests with unrecorded params from another recording of the method")
+
 	// Add flags for the generate command
 	generateCmd.Flags().String("description", "An MCP server for integration", "Description of the server")
 	generateCmd.Flags().String("api-endpoint", "https://api.example.com/v1", "API endpoint for the service")
@@ -305,14 +495,5 @@ func main() {
 
 // generateID creates a simple ID from a name
 func generateID(name string) string {
-	// Simple implementation - in a real app, you'd want a more robust ID generation
-	id := ""
-	for _, r := range name {
-		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '_' {
-			id += string(r)
-		} else {
-			id += "-"
-		}
-	}
-	return id
-}
\ No newline at end of file
+	return config.GenerateID(name)
+}
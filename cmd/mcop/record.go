@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"mcop/src/config"
+	"mcop/src/mcp"
+)
+
+// runRecord relays mcop's stdin and stdout to a configured server, writing
+// every frame of the session to output. It returns the process exit code.
+func runRecord(serverID, output string) int {
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return 1
+	}
+	server, err := findServer(cfg, serverID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	transport, err := mcp.NewTransport(server)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error connecting to %s: %v\n", serverID, err)
+		return 1
+	}
+	if stdio, ok := transport.(*mcp.StdioTransport); ok {
+		stdio.Stderr = &prefixWriter{w: os.Stderr, prefix: fmt.Sprintf("[%s] ", serverID)}
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating %s: %v\n", output, err)
+		return 1
+	}
+	defer file.Close()
+	w := bufio.NewWriter(file)
+	recording := mcp.NewRecordingTransport(transport, w)
+
+	ctx, stop := signal.NotifyContext(context.Background(), forwardedSignals...)
+	defer stop()
+
+	status := 0
+	if err := mcp.Relay(ctx, recording, os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error relaying session: %v\n", err)
+		status = 1
+	}
+	if err := recording.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", output, err)
+		return 1
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", output, err)
+		return 1
+	}
+	return status
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"mcop/src/mcp"
+)
+
+// runReplay serves a recorded session as a stdio MCP server on mcop's stdin
+// and stdout. It returns the process exit code.
+func runReplay(path string, realtime, loose bool) int {
+	file, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening session: %v\n", err)
+		return 1
+	}
+	frames, err := mcp.LoadSession(file)
+	file.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", path, err)
+		return 1
+	}
+
+	replay := mcp.NewReplayTransport(frames)
+	replay.Realtime = realtime
+	replay.Loose = loose
+
+	ctx, stop := signal.NotifyContext(context.Background(), forwardedSignals...)
+	defer stop()
+
+	if err := mcp.Relay(ctx, replay, os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error replaying session: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"mcop/src/config"
+	"mcop/src/proxy"
+	"mcop/src/types"
+)
+
+// forwardedSignals are relayed from mcop to the supervised server
+var forwardedSignals = []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT}
+
+// runServer launches a stdio server with its configured environment and
+// working directory, relays signals and stderr, and returns the exit status
+// of the child. With listen set, the server's stdio is served on that
+// address instead of being connected to mcop's own stdin and stdout.
+func runServer(serverID, listen string) int {
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return 1
+	}
+	server, err := findServer(cfg, serverID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	if server.TransportType() != types.TransportStdio || server.Command == "" {
+		fmt.Fprintf(os.Stderr, "Unsupported protocol for direct execution: %s\n", server.Endpoint())
+		return 1
+	}
+	command := server.Command
+
+	cmd := exec.Command(command, server.Args...)
+	cmd.Env = server.Environ(os.Environ())
+	cmd.Dir = server.Cwd
+	stderr := &prefixWriter{w: os.Stderr, prefix: fmt.Sprintf("[%s] ", serverID)}
+	cmd.Stderr = stderr
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var bridge *proxy.Bridge
+	var stdout *io.PipeWriter
+	if listen != "" {
+		scheme, addr, err := parseListenAddr(listen)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid listen address: %v\n", err)
+			return 1
+		}
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error listening on %s: %v\n", addr, err)
+			return 1
+		}
+
+		stdin, err := cmd.StdinPipe()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating stdin pipe: %v\n", err)
+			return 1
+		}
+		var stdoutReader *io.PipeReader
+		stdoutReader, stdout = io.Pipe()
+		cmd.Stdout = stdout
+
+		bridge = proxy.NewBridge(stdin)
+		go bridge.Run(stdoutReader)
+		go func() {
+			if err := bridge.Serve(ctx, scheme, ln); err != nil {
+				fmt.Fprintf(os.Stderr, "Error serving %s: %v\n", listen, err)
+			}
+		}()
+		fmt.Fprintf(os.Stderr, "Serving %s on %s://%s\n", serverID, scheme, ln.Addr())
+	} else {
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+	}
+
+	if err := cmd.Start(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error starting %s: %v\n", command, err)
+		return 1
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, forwardedSignals...)
+	defer signal.Stop(signals)
+	exited := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				cmd.Process.Signal(sig)
+			case <-exited:
+				return
+			}
+		}
+	}()
+
+	err = cmd.Wait()
+	close(exited)
+	stderr.Flush()
+	if stdout != nil {
+		stdout.Close()
+	}
+	if cmd.ProcessState == nil {
+		fmt.Fprintf(os.Stderr, "Error running %s: %v\n", command, err)
+		return 1
+	}
+	return exitStatus(cmd.ProcessState)
+}
+
+// parseListenAddr splits a --listen value into scheme and address; a bare
+// host:port means tcp
+func parseListenAddr(listen string) (string, string, error) {
+	if !strings.Contains(listen, "://") {
+		return "tcp", listen, nil
+	}
+	u, err := url.Parse(listen)
+	if err != nil {
+		return "", "", err
+	}
+	if u.Scheme != "tcp" && u.Scheme != "http" {
+		return "", "", fmt.Errorf("unsupported scheme %q (expected tcp or http)", u.Scheme)
+	}
+	return u.Scheme, u.Host, nil
+}
+
+// exitStatus maps a process state to a shell style exit status, reporting
+// death by signal as 128 plus the signal number
+func exitStatus(state *os.ProcessState) int {
+	if status, ok := state.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+	return state.ExitCode()
+}
+
+// prefixWriter writes each complete line with a prefix, buffering partial lines
+type prefixWriter struct {
+	w      io.Writer
+	prefix string
+	buf    []byte
+}
+
+func (p *prefixWriter) Write(data []byte) (int, error) {
+	p.buf = append(p.buf, data...)
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			break
+		}
+		fmt.Fprintf(p.w, "%s%s\n", p.prefix, p.buf[:i])
+		p.buf = p.buf[i+1:]
+	}
+	return len(data), nil
+}
+
+// Flush writes any trailing partial line
+func (p *prefixWriter) Flush() {
+	if len(p.buf) > 0 {
+		fmt.Fprintf(p.w, "%s%s\n", p.prefix, p.buf)
+		p.buf = nil
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	"mcop/src/config"
+)
+
+// runSecretSet stores a secret read from the terminal without echo, or from
+// the first line of stdin. It returns the process exit code.
+func runSecretSet(name string) int {
+	var value string
+	if term.IsTerminal(os.Stdin.Fd()) {
+		fmt.Fprintf(os.Stderr, "Value for %s: ", name)
+		data, err := term.ReadPassword(os.Stdin.Fd())
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading value: %v\n", err)
+			return 1
+		}
+		value = string(data)
+	} else {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			fmt.Fprintf(os.Stderr, "Error reading value: %v\n", err)
+			return 1
+		}
+		value = strings.TrimRight(line, "\r\n")
+	}
+
+	if err := config.SetSecret(name, value); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving secret: %v\n", err)
+		return 1
+	}
+	path, _ := config.SecretsPath()
+	fmt.Printf("Saved secret '%s' to %s\n", name, path)
+	return 0
+}
+
+// runSecretList prints the names of the stored secrets
+func runSecretList() int {
+	names, err := config.SecretNames()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading secrets: %v\n", err)
+		return 1
+	}
+	for _, name := range names {
+		fmt.Println(name)
+	}
+	return 0
+}
+
+// runSecretRemove deletes a stored secret
+func runSecretRemove(name string) int {
+	removed, err := config.RemoveSecret(name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error removing secret: %v\n", err)
+		return 1
+	}
+	if !removed {
+		fmt.Fprintf(os.Stderr, "Secret '%s' not found\n", name)
+		return 1
+	}
+	fmt.Printf("Removed secret '%s'\n", name)
+	return 0
+}
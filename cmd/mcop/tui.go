@@ -1,12 +1,19 @@
 package main
 
 import (
+	"fmt"
+	"os"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"mcop/src/ui"
 )
 
 func startTUI() {
-	appModel := ui.NewAppModel()
+	appModel := ui.NewAppModel(configPath)
+	if err := appModel.UseProfile(profileName); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 	p := tea.NewProgram(appModel, tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
 		panic(err)
@@ -14,7 +21,11 @@ func startTUI() {
 }
 
 func startTUIWithServer(url string) {
-	appModel := ui.NewAppModel()
+	appModel := ui.NewAppModel(configPath)
+	if err := appModel.UseProfile(profileName); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 	appModel.SetInitialServerURL(url)
 	p := tea.NewProgram(appModel, tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
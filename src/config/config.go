@@ -2,22 +2,39 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 )
 
 // MCPServer represents an MCP server configuration
 type MCPServer struct {
 	ID                string `json:"id"`
 	Name              string `json:"name"`
-	URL               string `json:"url"`
+	// Transport is "stdio", "http" or "sse". Stdio servers run Command with
+	// Args in Cwd and Env added to the environment; remote servers are
+	// reached at URL with Headers. A stdio:// URL is accepted as shorthand
+	// for Command and Args and is rewritten when the file is saved.
+	Transport         string            `json:"transport,omitempty"`
+	Command           string            `json:"command,omitempty"`
+	Args              []string          `json:"args,omitempty"`
+	Env               map[string]string `json:"env,omitempty"`
+	Cwd               string            `json:"cwd,omitempty"`
+	URL               string            `json:"url,omitempty"`
+	Headers           map[string]string `json:"headers,omitempty"`
 	Status            string `json:"status,omitempty"`
 	StartTime         interface{} `json:"start_time,omitempty"`  // Using interface{} to avoid import cycle
 	ResponseTime      interface{} `json:"response_time,omitempty"` // Using interface{} to avoid import cycle
 	ActiveConnections int    `json:"active_connections,omitempty"`
 	Description       string `json:"description"`
 	Tools             []string `json:"tools,omitempty"`
+	// Group is shown as a heading in the server list, and the servers of a
+	// group can be started and stopped together. Profiles select servers by
+	// ID, Group or Tags.
+	Group             string   `json:"group,omitempty"`
+	Tags              []string `json:"tags,omitempty"`
 }
 
 // AppConfig represents the application configuration
@@ -28,6 +45,15 @@ type AppConfig struct {
 	DefaultTheme  string      `json:"default_theme"`
 	APIKeys       map[string]string `json:"api_keys,omitempty"`
 	ServerConfigs map[string]ServerConfig `json:"server_configs,omitempty"`
+	Profiles      map[string]Profile      `json:"profiles,omitempty"`
+
+	// path is the file Save writes to, layers the files merged into the
+	// configuration; origins and settingOrigins record the file that defined
+	// each server and top-level setting
+	path           string
+	layers         []Layer
+	origins        map[string]string
+	settingOrigins map[string]string
 }
 
 // ServerConfig represents configuration specific to a server
@@ -36,53 +62,90 @@ type ServerConfig struct {
 	BaseURL     string            `json:"base_url,omitempty"`
 	Parameters  map[string]interface{} `json:"parameters,omitempty"`
 	Environment map[string]string `json:"environment,omitempty"`
+	WorkingDir  string            `json:"working_dir,omitempty"`
+
+	// Restart settings used by the process supervisor. RestartPolicy is one of
+	// "never" (the default), "on-failure" or "always"; at most MaxRestarts
+	// restarts are attempted within RestartWindow seconds, waiting
+	// RestartBackoff milliseconds before the first and doubling up to
+	// RestartMaxBackoff.
+	RestartPolicy     string `json:"restart_policy,omitempty"`
+	MaxRestarts       int    `json:"max_restarts,omitempty"`
+	RestartWindow     int    `json:"restart_window,omitempty"`
+	RestartBackoff    int    `json:"restart_backoff_ms,omitempty"`
+	RestartMaxBackoff int    `json:"restart_max_backoff_ms,omitempty"`
 }
 
-// LoadConfig loads the application configuration from a file
+// LoadConfig loads the application configuration. A non-empty configPath,
+// or else $MCOP_CONFIG, names the only file to read; otherwise the system,
+// user, config/default.json and project files found by DiscoverLayers are
+// merged, later files taking precedence.
 func LoadConfig(configPath string) (*AppConfig, error) {
 	if configPath == "" {
-		// Default to config/default.json
-		configPath = "config/default.json"
-	}
-
-	// Check if the config file exists
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		// If the file doesn't exist, return a default config
-		return DefaultConfig(), nil
+		configPath = os.Getenv("MCOP_CONFIG")
 	}
 
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+	var layers []Layer
+	path := configPath
+	if configPath != "" {
+		// Check if the config file exists
+		if _, err := os.Stat(configPath); err == nil {
+			layers = []Layer{{Scope: ScopeExplicit, Path: configPath}}
+		} else if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+	} else {
+		layers = DiscoverLayers()
+		path = savePath(layers)
 	}
 
-	var config AppConfig
-	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	if len(layers) == 0 {
+		// If no file exists, return a default config
+		config := DefaultConfig()
+		config.path = path
+		config.Migrate()
+		return config, nil
 	}
 
-	// Validate and set defaults
-	if config.RefreshRate <= 0 {
-		config.RefreshRate = 5 // Default to 5 seconds
+	config, err := mergeLayers(layers)
+	if err != nil {
+		return nil, err
 	}
+	config.path = path
+	config.layers = layers
 
-	// Load any environment-specific configurations
-	config.loadEnvironmentVars()
+	// Validate and set defaults, then load any environment-specific configurations
+	config.finish()
 
-	return &config, nil
+	return config, nil
 }
 
-// SaveConfig saves the application configuration to a file
+// SaveConfig saves the complete application configuration to a file
 func (c *AppConfig) SaveConfig(configPath string) error {
+	c.Migrate()
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	return writeConfigFile(configPath, data)
+}
+
+// writeConfigFile writes JSON data to configPath in the format of its
+// extension, creating its directory
+func writeConfigFile(configPath string, data []byte) error {
 	// Create directory if it doesn't exist
 	dir := filepath.Dir(configPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	data, err := json.MarshalIndent(c, "", "  ")
+	previous, err := os.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	data, err = encodeConfigFile(configPath, data, previous)
 	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
+		return err
 	}
 
 	if err := os.WriteFile(configPath, data, 0644); err != nil {
@@ -133,34 +196,6 @@ func (c *AppConfig) GetServer(serverID string) *MCPServer {
 	return nil
 }
 
-// loadEnvironmentVars loads configuration from environment variables
-func (c *AppConfig) loadEnvironmentVars() {
-	// Load global API keys
-	if apiKey := os.Getenv("MODEL_API_KEY"); apiKey != "" {
-		if c.APIKeys == nil {
-			c.APIKeys = make(map[string]string)
-		}
-		c.APIKeys["default"] = apiKey
-	}
-	
-	if provider := os.Getenv("MODEL_PROVIDER"); provider != "" {
-		if c.APIKeys == nil {
-			c.APIKeys = make(map[string]string)
-		}
-		c.APIKeys["provider"] = provider
-	}
-	
-	// Apply environment variables to server-specific configurations
-	for i := range c.Servers {
-		if c.Servers[i].URL == "" {
-			// Set default URL from environment if available
-			if defaultURL := os.Getenv("DEFAULT_MCP_URL"); defaultURL != "" {
-				c.Servers[i].URL = defaultURL
-			}
-		}
-	}
-}
-
 // DefaultConfig returns a default configuration
 func DefaultConfig() *AppConfig {
 	return &AppConfig{
@@ -191,18 +226,16 @@ func DefaultConfig() *AppConfig {
 	}
 }
 
-// Validate validates the configuration
+// Validate checks the configuration for mistakes that do not depend on the
+// environment, such as duplicate server IDs, and returns them joined
 func (c *AppConfig) Validate() error {
-	for _, server := range c.Servers {
-		if server.ID == "" {
-			return fmt.Errorf("server ID cannot be empty")
-		}
-		if server.URL == "" {
-			return fmt.Errorf("server URL cannot be empty for server %s", server.ID)
+	var errs []error
+	for _, problem := range c.Problems(nil) {
+		if problem.Severity == SeverityError {
+			errs = append(errs, errors.New(problem.String()))
 		}
 	}
-	
-	return nil
+	return errors.Join(errs...)
 }
 
 // GetServerConfig returns the configuration for a specific server
@@ -225,6 +258,22 @@ func (c *AppConfig) SetServerConfig(serverID string, config ServerConfig) {
 	c.ServerConfigs[serverID] = config
 }
 
+// Environ returns base with the server's environment variables applied on top,
+// in a stable order
+func (c ServerConfig) Environ(base []string) []string {
+	keys := make([]string, 0, len(c.Environment))
+	for key := range c.Environment {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	env := append([]string(nil), base...)
+	for _, key := range keys {
+		env = append(env, key+"="+c.Environment[key])
+	}
+	return env
+}
+
 // GetServersAsModelServers converts the config servers to model servers format
 // This function is designed to be used when importing in model package to avoid cycle
 func (c *AppConfig) GetServersAsModelServers() interface{} {
@@ -0,0 +1,264 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Configuration file formats, chosen by file extension
+const (
+	FileFormatJSON = "json"
+	FileFormatYAML = "yaml"
+	FileFormatTOML = "toml"
+)
+
+// configExtensions are the extensions a configuration file is looked up
+// with, in order of preference
+var configExtensions = []string{".json", ".yaml", ".yml", ".toml"}
+
+// FileFormat returns the format of a configuration file from its extension;
+// files without a known extension are JSON
+func FileFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FileFormatYAML
+	case ".toml":
+		return FileFormatTOML
+	default:
+		return FileFormatJSON
+	}
+}
+
+// findConfigFile returns the file named base with a configuration
+// extension in dir, preferring JSON
+func findConfigFile(dir, base string) (string, bool) {
+	for _, ext := range configExtensions {
+		path := filepath.Join(dir, base+ext)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// decodeConfigFile parses a configuration file in the format of its
+// extension into JSON, so that the json tags of AppConfig apply to every format
+func decodeConfigFile(path string, data []byte) ([]byte, error) {
+	var value interface{}
+	switch FileFormat(path) {
+	case FileFormatYAML:
+		if err := yaml.Unmarshal(data, &value); err != nil {
+			return nil, err
+		}
+		if value == nil {
+			value = map[string]interface{}{}
+		}
+	case FileFormatTOML:
+		var table map[string]interface{}
+		if err := toml.Unmarshal(data, &table); err != nil {
+			return nil, err
+		}
+		value = table
+	default:
+		return data, nil
+	}
+	return json.Marshal(value)
+}
+
+// encodeConfigFile converts JSON to the format of path. previous holds the
+// current contents of the file, whose comments are kept where possible: all
+// of them in YAML, the leading comment block in TOML.
+func encodeConfigFile(path string, data, previous []byte) ([]byte, error) {
+	switch FileFormat(path) {
+	case FileFormatYAML:
+		return encodeYAML(data, previous)
+	case FileFormatTOML:
+		return encodeTOML(data, previous)
+	default:
+		return data, nil
+	}
+}
+
+// encodeYAML converts JSON to block-style YAML, merged into the document
+// in previous so that its comments and formatting survive
+func encodeYAML(data, previous []byte) ([]byte, error) {
+	// JSON is YAML, so parsing it keeps the order of the keys
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to convert config to YAML: %w", err)
+	}
+	blockStyle(&doc)
+
+	var old yaml.Node
+	if len(previous) > 0 && yaml.Unmarshal(previous, &old) == nil && len(old.Content) > 0 {
+		doc.HeadComment = old.HeadComment
+		doc.FootComment = old.FootComment
+		doc.Content[0] = mergeYAML(old.Content[0], doc.Content[0])
+	}
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to convert config to YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to convert config to YAML: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+// blockStyle clears the flow and quoting styles of a node parsed from JSON
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// mergeYAML returns updated with the comments of the matching nodes in old.
+// Mapping keys keep their position in old, new keys are appended, and
+// sequence items are matched by their "id" key or else by position.
+func mergeYAML(old, updated *yaml.Node) *yaml.Node {
+	switch {
+	case old.Kind == yaml.MappingNode && updated.Kind == yaml.MappingNode:
+		merged := *old
+		merged.Content = nil
+		used := make(map[string]bool)
+		for i := 0; i+1 < len(old.Content); i += 2 {
+			key := old.Content[i].Value
+			if value := mappingValue(updated, key); value != nil {
+				merged.Content = append(merged.Content, old.Content[i], mergeYAML(old.Content[i+1], value))
+				used[key] = true
+			}
+		}
+		for i := 0; i+1 < len(updated.Content); i += 2 {
+			if !used[updated.Content[i].Value] {
+				merged.Content = append(merged.Content, updated.Content[i], updated.Content[i+1])
+			}
+		}
+		return &merged
+
+	case old.Kind == yaml.SequenceNode && updated.Kind == yaml.SequenceNode:
+		merged := *old
+		merged.Content = make([]*yaml.Node, len(updated.Content))
+		for i, item := range updated.Content {
+			merged.Content[i] = item
+			if match := matchingItem(old, item, i); match != nil {
+				merged.Content[i] = mergeYAML(match, item)
+			}
+		}
+		return &merged
+
+	case old.Kind == yaml.ScalarNode && updated.Kind == yaml.ScalarNode && old.Value == updated.Value:
+		return old
+
+	default:
+		merged := *updated
+		merged.HeadComment = old.HeadComment
+		merged.LineComment = old.LineComment
+		merged.FootComment = old.FootComment
+		return &merged
+	}
+}
+
+// mappingValue returns the value of key in a mapping node
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// matchingItem finds the item of the old sequence corresponding to the
+// item at index i of the updated one
+func matchingItem(old, item *yaml.Node, i int) *yaml.Node {
+	if id := mappingValue(item, "id"); item.Kind == yaml.MappingNode && id != nil {
+		for _, candidate := range old.Content {
+			if value := mappingValue(candidate, "id"); candidate.Kind == yaml.MappingNode && value != nil && value.Value == id.Value {
+				return candidate
+			}
+		}
+		return nil
+	}
+	if i < len(old.Content) {
+		return old.Content[i]
+	}
+	return nil
+}
+
+// encodeTOML converts JSON to TOML, keeping the comments at the top of previous
+func encodeTOML(data, previous []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to convert config to TOML: %w", err)
+	}
+	encoded, err := toml.Marshal(tomlValue(value))
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert config to TOML: %w", err)
+	}
+	return append(leadingComments(previous), encoded...), nil
+}
+
+// tomlValue prepares a decoded JSON value for TOML, which has no null and
+// distinguishes integers from floats
+func tomlValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		table := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if item != nil {
+				table[key] = tomlValue(item)
+			}
+		}
+		return table
+	case []interface{}:
+		items := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			if item != nil {
+				items = append(items, tomlValue(item))
+			}
+		}
+		return items
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	default:
+		return v
+	}
+}
+
+// leadingComments returns the comment and blank lines at the start of a file
+func leadingComments(data []byte) []byte {
+	var header []byte
+	for len(data) > 0 {
+		line := data
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			line = data[:i+1]
+		}
+		trimmed := bytes.TrimSpace(line)
+		if len(trimmed) > 0 && trimmed[0] != '#' {
+			break
+		}
+		header = append(header, line...)
+		data = data[len(line):]
+	}
+	if len(header) > 0 && header[len(header)-1] != '\n' {
+		header = append(header, '\n')
+	}
+	return header
+}
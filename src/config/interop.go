@@ -0,0 +1,346 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"mcop/src/mcp"
+	"mcop/src/types"
+)
+
+// Formats of the MCP server files written by other clients
+const (
+	FormatClaude = "claude" // claude_desktop_config.json
+	FormatVSCode = "vscode" // .vscode/mcp.json or the "mcp" key of settings.json
+	FormatCursor = "cursor" // .cursor/mcp.json
+)
+
+// ExternalServer is a server entry in a Claude Desktop, VS Code or Cursor file
+type ExternalServer struct {
+	// Type is "stdio", "http" or "sse"; Claude Desktop and Cursor leave it out
+	Type    string            `json:"type,omitempty"`
+	Command string            `json:"command,omitempty"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+	Cwd     string            `json:"cwd,omitempty"`
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	EnvFile string            `json:"envFile,omitempty"`
+}
+
+// externalFile is the union of the file layouts: Claude Desktop and Cursor
+// use mcpServers, VS Code uses servers, nested under mcp in settings.json
+type externalFile struct {
+	MCPServers map[string]ExternalServer `json:"mcpServers"`
+	Servers    map[string]ExternalServer `json:"servers"`
+	MCP        *struct {
+		Servers map[string]ExternalServer `json:"servers"`
+	} `json:"mcp"`
+}
+
+// ImportedServer is a server converted from another client's file
+type ImportedServer struct {
+	Server   MCPServer
+	Warnings []string
+}
+
+// DetectFormat guesses the format of a file from its path and contents
+func DetectFormat(path string, data []byte) (string, error) {
+	var file externalFile
+	if err := json.Unmarshal(stripJSONC(data), &file); err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	switch {
+	case file.Servers != nil || file.MCP != nil:
+		return FormatVSCode, nil
+	case file.MCPServers != nil:
+		if filepath.Base(filepath.Dir(path)) == ".cursor" {
+			return FormatCursor, nil
+		}
+		return FormatClaude, nil
+	default:
+		return "", fmt.Errorf("%s has no mcpServers or servers", path)
+	}
+}
+
+// stripJSONC turns JSON with comments and trailing commas, as VS Code writes
+// settings.json and mcp.json, into plain JSON. Comments and trailing commas
+// are replaced with spaces, so error offsets still match the original.
+func stripJSONC(data []byte) []byte {
+	out := append([]byte(nil), data...)
+	inString := false
+	for i := 0; i < len(out); i++ {
+		switch c := out[i]; {
+		case inString:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '/' && i+1 < len(out) && out[i+1] == '/':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				out[i] = ' '
+			}
+		case c == '/' && i+1 < len(out) && out[i+1] == '*':
+			end := bytes.Index(out[i+2:], []byte("*/"))
+			if end < 0 {
+				end = len(out) - i - 2
+			} else {
+				end += 2
+			}
+			for j := i; j < i+2+end && j < len(out); j++ {
+				if out[j] != '\n' {
+					out[j] = ' '
+				}
+			}
+			i += 1 + end
+		}
+	}
+
+	inString = false
+	for i := 0; i < len(out); i++ {
+		switch c := out[i]; {
+		case inString:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == ',':
+			next := bytes.TrimLeft(out[i+1:], " \t\r\n")
+			if len(next) > 0 && (next[0] == '}' || next[0] == ']') {
+				out[i] = ' '
+			}
+		}
+	}
+	return out
+}
+
+// ImportServers converts the servers of a file in format, sorted by ID
+func ImportServers(data []byte, format string) ([]ImportedServer, error) {
+	var file externalFile
+	if err := json.Unmarshal(stripJSONC(data), &file); err != nil {
+		return nil, fmt.Errorf("failed to parse file: %w", err)
+	}
+
+	var entries map[string]ExternalServer
+	switch format {
+	case FormatClaude, FormatCursor:
+		entries = file.MCPServers
+	case FormatVSCode:
+		entries = file.Servers
+		if entries == nil && file.MCP != nil {
+			entries = file.MCP.Servers
+		}
+	default:
+		return nil, fmt.Errorf("unknown format %q (expected claude, vscode or cursor)", format)
+	}
+	if entries == nil {
+		return nil, fmt.Errorf("no servers found for format %s", format)
+	}
+
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	imported := make([]ImportedServer, 0, len(entries))
+	taken := make(map[string]string) // ID -> name it was generated from
+	for _, name := range names {
+		server, err := importServer(name, entries[name], format)
+		if err != nil {
+			return nil, fmt.Errorf("server %s: %w", name, err)
+		}
+		// Distinct names may map to the same ID, such as "my files" and
+		// "my-files"
+		id := server.Server.ID
+		for n := 2; taken[server.Server.ID] != ""; n++ {
+			server.Server.ID = fmt.Sprintf("%s-%d", id, n)
+		}
+		if server.Server.ID != id {
+			server.Warnings = append(server.Warnings, fmt.Sprintf("ID %s is already used by %q", id, taken[id]))
+		}
+		taken[server.Server.ID] = name
+		imported = append(imported, server)
+	}
+	sort.Slice(imported, func(i, j int) bool {
+		return imported[i].Server.ID < imported[j].Server.ID
+	})
+	return imported, nil
+}
+
+// importServer converts one entry of a file
+func importServer(name string, entry ExternalServer, format string) (ImportedServer, error) {
+	imported := ImportedServer{
+		Server: MCPServer{
+			ID:          GenerateID(name),
+			Name:        name,
+			Status:      "stopped",
+			Description: fmt.Sprintf("Imported from %s", format),
+			Tools:       []string{},
+		},
+	}
+	warn := func(msg string, args ...interface{}) {
+		imported.Warnings = append(imported.Warnings, fmt.Sprintf(msg, args...))
+	}
+
+	switch {
+	case entry.Command != "":
+		if entry.Type != "" && entry.Type != types.TransportStdio {
+			return imported, fmt.Errorf("type %q with a command", entry.Type)
+		}
+		imported.Server.Transport = types.TransportStdio
+		imported.Server.Command = entry.Command
+		imported.Server.Args = entry.Args
+		imported.Server.Env = entry.Env
+		imported.Server.Cwd = entry.Cwd
+	case entry.URL != "":
+		imported.Server.URL = entry.URL
+		imported.Server.Headers = entry.Headers
+		imported.Server.Transport = imported.Server.RuntimeServer().TransportType()
+		if entry.Type == types.TransportSSE || entry.Type == types.TransportHTTP {
+			imported.Server.Transport = entry.Type
+		}
+		if len(entry.Env) > 0 || entry.Cwd != "" {
+			warn("env and cwd are ignored for remote servers")
+		}
+	default:
+		return imported, fmt.Errorf("neither command nor url is set")
+	}
+
+	if entry.EnvFile != "" {
+		warn("envFile %s is not supported; copy its variables into env", entry.EnvFile)
+	}
+	for key, value := range entry.Env {
+		if strings.Contains(value, "${input:") {
+			warn("%s refers to a VS Code input and must be filled in by hand", key)
+		}
+	}
+	sort.Strings(imported.Warnings)
+	return imported, nil
+}
+
+// ImportReport lists what MergeServers did with each imported server ID
+type ImportReport struct {
+	Added     []string
+	Replaced  []string
+	Unchanged []string
+	// Conflicts are IDs already used by a different server; they are
+	// skipped unless overwriting
+	Conflicts []string
+}
+
+// MergeServers adds imported servers to the configuration. Servers whose ID
+// is taken by a different server are only replaced when overwrite is set.
+func (c *AppConfig) MergeServers(imported []ImportedServer, overwrite bool) ImportReport {
+	var report ImportReport
+	for _, entry := range imported {
+		id := entry.Server.ID
+		if existing := c.GetServer(id); existing != nil {
+			if existing.RuntimeServer().SameLaunch(entry.Server.RuntimeServer()) {
+				report.Unchanged = append(report.Unchanged, id)
+				continue
+			}
+			if !overwrite {
+				report.Conflicts = append(report.Conflicts, id)
+				continue
+			}
+			report.Replaced = append(report.Replaced, id)
+		} else {
+			report.Added = append(report.Added, id)
+		}
+		c.AddServer(entry.Server)
+	}
+	return report
+}
+
+// ExportServers converts the configured servers to a file in format. Servers
+// the format cannot express are left out and reported as warnings.
+func (c *AppConfig) ExportServers(format string) ([]byte, []string, error) {
+	if format != FormatClaude && format != FormatVSCode && format != FormatCursor {
+		return nil, nil, fmt.Errorf("unknown format %q (expected claude, vscode or cursor)", format)
+	}
+
+	entries := make(map[string]ExternalServer)
+	var warnings []string
+	for _, server := range c.Servers {
+		entry, err := exportServer(server, format)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: %v", server.ID, err))
+			continue
+		}
+		entries[server.ID] = entry
+	}
+
+	key := "mcpServers"
+	if format == FormatVSCode {
+		key = "servers"
+	}
+	data, err := json.MarshalIndent(map[string]interface{}{key: entries}, "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal servers: %w", err)
+	}
+	return data, warnings, nil
+}
+
+// exportServer converts one configured server
+func exportServer(server MCPServer, format string) (ExternalServer, error) {
+	runtime := server.RuntimeServer()
+	var entry ExternalServer
+
+	switch transport := runtime.TransportType(); transport {
+	case types.TransportStdio:
+		entry.Command, entry.Args = server.Command, server.Args
+		if entry.Command == "" {
+			command, args, err := mcp.ParseStdioURL(server.URL)
+			if err != nil {
+				return entry, err
+			}
+			entry.Command, entry.Args = command, args
+		}
+		entry.Env = server.Env
+		if server.Cwd != "" {
+			if format != FormatVSCode {
+				return entry, fmt.Errorf("cwd cannot be expressed in %s format", format)
+			}
+			entry.Cwd = server.Cwd
+		}
+		if format == FormatVSCode {
+			entry.Type = transport
+		}
+	case types.TransportHTTP, types.TransportSSE:
+		if format == FormatClaude {
+			return entry, fmt.Errorf("remote servers cannot be expressed in claude format")
+		}
+		entry.URL, entry.Headers = server.URL, server.Headers
+		if format == FormatVSCode {
+			entry.Type = transport
+		}
+	default:
+		return entry, fmt.Errorf("unsupported transport %q", transport)
+	}
+	return entry, nil
+}
+
+// GenerateID derives a server ID from a name
+func GenerateID(name string) string {
+	var id strings.Builder
+	id.Grow(len(name))
+	for _, r := range name {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '_' {
+			id.WriteRune(r)
+		} else {
+			id.WriteByte('-')
+		}
+	}
+	return id.String()
+}
@@ -0,0 +1,241 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"mcop/src/types"
+)
+
+// Reference kinds
+const (
+	RefEnv    = "env"
+	RefSecret = "secret"
+)
+
+// Reference is a ${...} reference in a configuration value
+type Reference struct {
+	Kind string // RefEnv or RefSecret
+	Name string
+	// Default replaces an unset or empty environment variable when HasDefault is set
+	Default    string
+	HasDefault bool
+}
+
+// String returns the reference as written in the configuration
+func (r Reference) String() string {
+	switch {
+	case r.Kind == RefSecret:
+		return "${secret:" + r.Name + "}"
+	case r.HasDefault:
+		return "${" + r.Name + ":-" + r.Default + "}"
+	default:
+		return "${" + r.Name + "}"
+	}
+}
+
+var (
+	envNamePattern    = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	secretNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+)
+
+// segment is literal text, or a reference when ref is set
+type segment struct {
+	text string
+	ref  *Reference
+}
+
+// parseValue splits a value into literal text and references. "$${" stands
+// for a literal "${"; any other "$" is literal.
+func parseValue(value string) ([]segment, error) {
+	var segments []segment
+	var literal strings.Builder
+	for i := 0; i < len(value); {
+		switch {
+		case strings.HasPrefix(value[i:], "$${"):
+			literal.WriteString("${")
+			i += 3
+		case strings.HasPrefix(value[i:], "${"):
+			end := strings.IndexByte(value[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated reference in %q", value)
+			}
+			ref, err := parseReference(value[i+2 : i+end])
+			if err != nil {
+				return nil, err
+			}
+			if literal.Len() > 0 {
+				segments = append(segments, segment{text: literal.String()})
+				literal.Reset()
+			}
+			segments = append(segments, segment{ref: ref})
+			i += end + 1
+		default:
+			literal.WriteByte(value[i])
+			i++
+		}
+	}
+	if literal.Len() > 0 {
+		segments = append(segments, segment{text: literal.String()})
+	}
+	return segments, nil
+}
+
+// parseReference parses the inside of ${...}
+func parseReference(body string) (*Reference, error) {
+	if name, ok := strings.CutPrefix(body, "secret:"); ok {
+		if !secretNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid secret name %q", name)
+		}
+		return &Reference{Kind: RefSecret, Name: name}, nil
+	}
+	ref := &Reference{Kind: RefEnv, Name: body}
+	if name, def, ok := strings.Cut(body, ":-"); ok {
+		ref.Name, ref.Default, ref.HasDefault = name, def, true
+	}
+	if !envNamePattern.MatchString(ref.Name) {
+		return nil, fmt.Errorf("invalid variable name %q", ref.Name)
+	}
+	return ref, nil
+}
+
+// References returns the references in a value
+func References(value string) ([]Reference, error) {
+	segments, err := parseValue(value)
+	if err != nil {
+		return nil, err
+	}
+	var refs []Reference
+	for _, seg := range segments {
+		if seg.ref != nil {
+			refs = append(refs, *seg.ref)
+		}
+	}
+	return refs, nil
+}
+
+// Resolver expands references from the environment and the secret store
+type Resolver struct {
+	LookupEnv func(name string) (string, bool)
+	// LoadSecrets is called at most once, when a value first refers to a secret
+	LoadSecrets func() (map[string]string, error)
+
+	secrets map[string]string
+}
+
+// NewResolver creates a resolver reading mcop's environment and secrets file
+func NewResolver() *Resolver {
+	return &Resolver{LookupEnv: os.LookupEnv, LoadSecrets: LoadSecrets}
+}
+
+// Expand replaces the references in value. Unset variables without a
+// default and unknown secrets are errors.
+func (r *Resolver) Expand(value string) (string, error) {
+	if !strings.Contains(value, "${") {
+		return value, nil
+	}
+	segments, err := parseValue(value)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for _, seg := range segments {
+		if seg.ref == nil {
+			sb.WriteString(seg.text)
+			continue
+		}
+		resolved, err := r.lookup(*seg.ref)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(resolved)
+	}
+	return sb.String(), nil
+}
+
+// lookup resolves a single reference
+func (r *Resolver) lookup(ref Reference) (string, error) {
+	if ref.Kind == RefSecret {
+		if r.secrets == nil {
+			secrets, err := r.LoadSecrets()
+			if err != nil {
+				return "", err
+			}
+			r.secrets = secrets
+		}
+		value, ok := r.secrets[ref.Name]
+		if !ok {
+			return "", fmt.Errorf("secret %q is not set (use mcop secret set %s)", ref.Name, ref.Name)
+		}
+		return value, nil
+	}
+
+	value, ok := r.LookupEnv(ref.Name)
+	if ref.HasDefault && value == "" {
+		return ref.Default, nil
+	}
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", ref.Name)
+	}
+	return value, nil
+}
+
+// ResolveServer returns a copy of server with the references in its
+// command, args, env, cwd, URL and headers expanded. Servers with neither a
+// command nor a URL are reached at $DEFAULT_MCP_URL when it is set.
+func (r *Resolver) ResolveServer(server types.MCPServer) (types.MCPServer, error) {
+	var err error
+	expand := func(field, value string) string {
+		if err != nil {
+			return value
+		}
+		expanded, expandErr := r.Expand(value)
+		if expandErr != nil {
+			err = fmt.Errorf("%s: %w", field, expandErr)
+		}
+		return expanded
+	}
+
+	server.Command = expand("command", server.Command)
+	server.Cwd = expand("cwd", server.Cwd)
+	server.URL = expand("url", server.URL)
+	if server.Args != nil {
+		args := make([]string, len(server.Args))
+		for i, arg := range server.Args {
+			args[i] = expand(fmt.Sprintf("args[%d]", i), arg)
+		}
+		server.Args = args
+	}
+	server.Env = expandMap(server.Env, "env", expand)
+	server.Headers = expandMap(server.Headers, "headers", expand)
+	if err != nil {
+		return server, fmt.Errorf("server %s: %w", server.ID, err)
+	}
+	if server.Command == "" && server.URL == "" {
+		if url, _ := r.LookupEnv("DEFAULT_MCP_URL"); url != "" {
+			server.URL = url
+		}
+	}
+	return server, nil
+}
+
+// expandMap returns a copy of values with each value expanded
+func expandMap(values map[string]string, field string, expand func(field, value string) string) map[string]string {
+	if values == nil {
+		return nil
+	}
+	expanded := make(map[string]string, len(values))
+	for key, value := range values {
+		expanded[key] = expand(field+"."+key, value)
+	}
+	return expanded
+}
+
+// ResolveServer expands the references in a server's launch settings from
+// mcop's environment and secrets file. It is called when connecting, so the
+// configuration itself keeps the references.
+func ResolveServer(server types.MCPServer) (types.MCPServer, error) {
+	return NewResolver().ResolveServer(server)
+}
@@ -0,0 +1,317 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+)
+
+// Configuration scopes, from lowest to highest precedence
+const (
+	ScopeSystem   = "system"   // SystemConfigDir
+	ScopeUser     = "user"     // $XDG_CONFIG_HOME/mcop
+	ScopeLegacy   = "legacy"   // config/default.* in the working directory
+	ScopeProject  = "project"  // .mcop in the working directory or a parent
+	ScopeExplicit = "explicit" // --config or $MCOP_CONFIG
+)
+
+// SystemConfigDir holds configuration shared by every user of the machine
+var SystemConfigDir = "/etc/mcop"
+
+const (
+	configFileName   = "config.json"
+	legacyConfigDir  = "config"
+	projectConfigDir = ".mcop"
+)
+
+// Layer is a configuration file merged into the configuration
+type Layer struct {
+	Scope string
+	Path  string
+}
+
+// settingKeys are the top-level settings a layer overrides when it sets them
+var settingKeys = []string{"auto_refresh", "refresh_rate", "default_theme"}
+
+// UserConfigDir returns mcop's directory in the user's config directory
+func UserConfigDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "mcop"), nil
+}
+
+// DiscoverLayers returns the configuration files that exist, from lowest to
+// highest precedence
+func DiscoverLayers() []Layer {
+	var layers []Layer
+	if path, ok := findConfigFile(SystemConfigDir, "config"); ok {
+		layers = append(layers, Layer{ScopeSystem, path})
+	}
+	if dir, err := UserConfigDir(); err == nil {
+		if path, ok := findConfigFile(dir, "config"); ok {
+			layers = append(layers, Layer{ScopeUser, path})
+		}
+	}
+	if path, ok := findConfigFile(legacyConfigDir, "default"); ok {
+		layers = append(layers, Layer{ScopeLegacy, path})
+	}
+	if path, ok := findProjectConfig(); ok {
+		layers = append(layers, Layer{ScopeProject, path})
+	}
+	return layers
+}
+
+// findProjectConfig looks for .mcop/config.* in the working directory and its parents
+func findProjectConfig() (string, bool) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", false
+	}
+	for {
+		if path, ok := findConfigFile(filepath.Join(dir, projectConfigDir), "config"); ok {
+			return path, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// savePath returns the file changes are written to: the layer with the
+// highest precedence, or the user's config file when there is none. The
+// system file is shared by every user and is never written.
+func savePath(layers []Layer) string {
+	if len(layers) > 0 && layers[len(layers)-1].Scope != ScopeSystem {
+		return layers[len(layers)-1].Path
+	}
+	if dir, err := UserConfigDir(); err == nil {
+		return filepath.Join(dir, configFileName)
+	}
+	return filepath.Join(legacyConfigDir, "default.json")
+}
+
+// readLayer parses a configuration file in any format, also returning the top-level keys it sets
+func readLayer(path string) (*AppConfig, map[string]bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	data, err = decodeConfigFile(path, data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	var layer AppConfig
+	if err := json.Unmarshal(data, &layer); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	present := make(map[string]bool, len(keys))
+	for key := range keys {
+		present[key] = true
+	}
+	return &layer, present, nil
+}
+
+// mergeLayers reads and merges layers in order. Servers, server configs,
+// profiles and API keys are merged by ID or name, a later layer replacing an
+// entry entirely; settings are taken from the last layer that sets them.
+func mergeLayers(layers []Layer) (*AppConfig, error) {
+	merged := &AppConfig{
+		origins:        make(map[string]string),
+		settingOrigins: make(map[string]string),
+	}
+	for _, layer := range layers {
+		cfg, present, err := readLayer(layer.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, server := range cfg.Servers {
+			merged.AddServer(server)
+			merged.origins[server.ID] = layer.Path
+		}
+		for id, serverConfig := range cfg.ServerConfigs {
+			merged.SetServerConfig(id, serverConfig)
+		}
+		for name, profile := range cfg.Profiles {
+			merged.SetProfile(name, profile)
+		}
+		for name, key := range cfg.APIKeys {
+			if merged.APIKeys == nil {
+				merged.APIKeys = make(map[string]string)
+			}
+			merged.APIKeys[name] = key
+		}
+		for _, key := range settingKeys {
+			if !present[key] {
+				continue
+			}
+			merged.settingOrigins[key] = layer.Path
+			switch key {
+			case "auto_refresh":
+				merged.AutoRefresh = cfg.AutoRefresh
+			case "refresh_rate":
+				merged.RefreshRate = cfg.RefreshRate
+			case "default_theme":
+				merged.DefaultTheme = cfg.DefaultTheme
+			}
+		}
+	}
+	return merged, nil
+}
+
+// finish applies defaults and migrations to a loaded configuration
+func (c *AppConfig) finish() {
+	if c.RefreshRate <= 0 {
+		c.RefreshRate = 5 // Default to 5 seconds
+	}
+	c.Migrate()
+}
+
+// Layers returns the files the configuration was merged from, lowest precedence first
+func (c *AppConfig) Layers() []Layer {
+	return append([]Layer(nil), c.layers...)
+}
+
+// Path returns the file Save writes to
+func (c *AppConfig) Path() string {
+	return c.path
+}
+
+// Origin returns the file a server was defined in, or "" for servers added
+// since loading or taken from the built-in defaults
+func (c *AppConfig) Origin(serverID string) string {
+	return c.origins[serverID]
+}
+
+// SettingOrigin returns the file that set a top-level setting such as
+// "refresh_rate", or "" when it has its default value
+func (c *AppConfig) SettingOrigin(key string) string {
+	return c.settingOrigins[key]
+}
+
+// Save writes the configuration to Path. When lower layers were merged in,
+// only what differs from them is written, so that settings inherited from
+// the system or user configuration are not copied into the file.
+func (c *AppConfig) Save() error {
+	var lower []Layer
+	for _, layer := range c.layers {
+		if layer.Path != c.path {
+			lower = append(lower, layer)
+		}
+	}
+	if len(lower) == 0 {
+		return c.SaveConfig(c.path)
+	}
+
+	base, err := mergeLayers(lower)
+	if err != nil {
+		return err
+	}
+	base.finish()
+	c.Migrate()
+
+	diff := &AppConfig{
+		AutoRefresh:  c.AutoRefresh,
+		RefreshRate:  c.RefreshRate,
+		DefaultTheme: c.DefaultTheme,
+		Servers:      []MCPServer{},
+	}
+	for _, server := range c.Servers {
+		if inherited := base.GetServer(server.ID); inherited == nil || !reflect.DeepEqual(*inherited, server) {
+			diff.Servers = append(diff.Servers, server)
+		}
+	}
+	for _, server := range base.Servers {
+		if c.GetServer(server.ID) == nil {
+			return fmt.Errorf("server %s is defined in %s and cannot be removed from %s", server.ID, base.origins[server.ID], c.path)
+		}
+	}
+	for id, serverConfig := range c.ServerConfigs {
+		if inherited, ok := base.ServerConfigs[id]; !ok || !reflect.DeepEqual(inherited, serverConfig) {
+			diff.SetServerConfig(id, serverConfig)
+		}
+	}
+	for name, profile := range c.Profiles {
+		if inherited, ok := base.Profiles[name]; !ok || !reflect.DeepEqual(inherited, profile) {
+			diff.SetProfile(name, profile)
+		}
+	}
+	for name, key := range c.APIKeys {
+		if inherited, ok := base.APIKeys[name]; !ok || inherited != key {
+			if diff.APIKeys == nil {
+				diff.APIKeys = make(map[string]string)
+			}
+			diff.APIKeys[name] = key
+		}
+	}
+
+	drop := make(map[string]bool)
+	if diff.AutoRefresh == base.AutoRefresh {
+		drop["auto_refresh"] = true
+	}
+	if diff.RefreshRate == base.RefreshRate {
+		drop["refresh_rate"] = true
+	}
+	if diff.DefaultTheme == base.DefaultTheme {
+		drop["default_theme"] = true
+	}
+	data, err := marshalWithout(diff, drop)
+	if err != nil {
+		return err
+	}
+	return writeConfigFile(c.path, data)
+}
+
+// marshalWithout encodes v as indented JSON without the top-level keys in drop,
+// keeping the order of the remaining keys
+func marshalWithout(v interface{}, drop map[string]bool) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	out.WriteByte('{')
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		key := token.(string)
+		if drop[key] {
+			continue
+		}
+		if out.Len() > 1 {
+			out.WriteByte(',')
+		}
+		encodedKey, _ := json.Marshal(key)
+		out.Write(encodedKey)
+		out.WriteByte(':')
+		out.Write(value)
+	}
+	out.WriteByte('}')
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, out.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	return indented.Bytes(), nil
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"strings"
+
+	"mcop/src/mcp"
+	"mcop/src/types"
+)
+
+// Migrate rewrites servers in the current schema: stdio:// URLs become
+// Command and Args, the environment and working directory kept in
+// ServerConfigs move to the server, and the transport is made explicit.
+// Values set on the server win over those moved from ServerConfigs.
+func (c *AppConfig) Migrate() {
+	for i := range c.Servers {
+		server := &c.Servers[i]
+		if strings.HasPrefix(server.URL, "stdio://") && server.Command == "" {
+			if command, args, err := mcp.ParseStdioURL(server.URL); err == nil {
+				server.Command, server.Args, server.URL = command, args, ""
+			}
+		}
+
+		if serverConfig, ok := c.ServerConfigs[server.ID]; ok {
+			for key, value := range serverConfig.Environment {
+				if _, set := server.Env[key]; !set {
+					if server.Env == nil {
+						server.Env = make(map[string]string)
+					}
+					server.Env[key] = value
+				}
+			}
+			if server.Cwd == "" {
+				server.Cwd = serverConfig.WorkingDir
+			}
+			serverConfig.Environment, serverConfig.WorkingDir = nil, ""
+			if serverConfig.isEmpty() {
+				delete(c.ServerConfigs, server.ID)
+			} else {
+				c.ServerConfigs[server.ID] = serverConfig
+			}
+		}
+
+		if server.Transport == "" {
+			server.Transport = server.RuntimeServer().TransportType()
+		}
+	}
+}
+
+// isEmpty reports whether no setting is configured
+func (c ServerConfig) isEmpty() bool {
+	return c.APIKey == "" && c.BaseURL == "" && len(c.Parameters) == 0 &&
+		len(c.Environment) == 0 && c.WorkingDir == "" && c.RestartPolicy == "" &&
+		c.MaxRestarts == 0 && c.RestartWindow == 0 && c.RestartBackoff == 0 && c.RestartMaxBackoff == 0
+}
+
+// RuntimeServer converts a configured server to the form used to connect to it
+func (s MCPServer) RuntimeServer() types.MCPServer {
+	return types.MCPServer{
+		ID:                s.ID,
+		Name:              s.Name,
+		URL:               s.URL,
+		Status:            s.Status,
+		ActiveConnections: s.ActiveConnections,
+		Description:       s.Description,
+		Tools:             types.ToolsFromNames(s.Tools),
+		Transport:         s.Transport,
+		Command:           s.Command,
+		Args:              s.Args,
+		Env:               s.Env,
+		Cwd:               s.Cwd,
+		Headers:           s.Headers,
+		Group:             s.Group,
+		Tags:              s.Tags,
+	}
+}
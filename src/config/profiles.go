@@ -0,0 +1,79 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Profile selects the servers needed for a task, such as "dev" or "review".
+// A server belongs to the profile if its ID, its group or one of its tags
+// is listed.
+type Profile struct {
+	Description string   `json:"description,omitempty"`
+	Servers     []string `json:"servers,omitempty"`
+	Groups      []string `json:"groups,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// Includes reports whether the profile selects server
+func (p Profile) Includes(server MCPServer) bool {
+	if contains(p.Servers, server.ID) || (server.Group != "" && contains(p.Groups, server.Group)) {
+		return true
+	}
+	for _, tag := range server.Tags {
+		if contains(p.Tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// SetProfile adds or replaces a named profile
+func (c *AppConfig) SetProfile(name string, profile Profile) {
+	if c.Profiles == nil {
+		c.Profiles = make(map[string]Profile)
+	}
+	c.Profiles[name] = profile
+}
+
+// ProfileNames returns the names of the profiles in alphabetical order
+func (c *AppConfig) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ProfileServers returns the servers a profile selects, in configuration
+// order. An empty name selects every server.
+func (c *AppConfig) ProfileServers(name string) ([]MCPServer, error) {
+	if name == "" {
+		return c.Servers, nil
+	}
+	profile, ok := c.Profiles[name]
+	if !ok {
+		if len(c.Profiles) == 0 {
+			return nil, fmt.Errorf("unknown profile %q: no profiles are configured", name)
+		}
+		return nil, fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(c.ProfileNames(), ", "))
+	}
+	var servers []MCPServer
+	for _, server := range c.Servers {
+		if profile.Includes(server) {
+			servers = append(servers, server)
+		}
+	}
+	return servers, nil
+}
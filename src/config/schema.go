@@ -0,0 +1,159 @@
+package config
+
+//go:generate go run ../../cmd/mcop config schema -o ../../config/schema.json
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"mcop/src/mcp"
+	"mcop/src/types"
+)
+
+// RestartPolicies are the accepted values of ServerConfig.RestartPolicy
+var RestartPolicies = []string{"never", "on-failure", "always"}
+
+// schemaHints adds what the Go types cannot express to the generated
+// schema, keyed by type name and JSON property
+var schemaHints = map[string]func(*mcp.Schema){
+	"AppConfig.servers":                   describe("MCP servers, merged by id with the servers of other configuration files"),
+	"AppConfig.auto_refresh":              describe("Ping running servers and sample their processes periodically"),
+	"AppConfig.refresh_rate":              minimum(1, "Seconds between refreshes"),
+	"AppConfig.default_theme":             describe("Theme of the TUI"),
+	"AppConfig.api_keys":                  describe("API keys by name; values may use ${VAR} and ${secret:name} references"),
+	"AppConfig.server_configs":            describe("Settings keyed by server id"),
+	"AppConfig.profiles":                  describe("Named subsets of the servers, selected with mcop --profile"),
+	"MCPServer.id":                        pattern(`^\S+$`, "Unique identifier of the server"),
+	"MCPServer.name":                      describe("Display name"),
+	"MCPServer.transport":                 enum("How the server is reached; inferred from command or url when left out", types.TransportStdio, types.TransportHTTP, types.TransportSSE),
+	"MCPServer.command":                   describe("Executable started for stdio servers"),
+	"MCPServer.args":                      describe("Arguments of command"),
+	"MCPServer.env":                       describe("Variables added to the environment of command"),
+	"MCPServer.cwd":                       describe("Working directory of command"),
+	"MCPServer.url":                       describe("Endpoint of http and sse servers; stdio://command args is shorthand for command and args"),
+	"MCPServer.headers":                   describe("HTTP headers sent with every request"),
+	"MCPServer.description":               describe("What the server is for"),
+	"MCPServer.tools":                     describe("Tool names shown before the server is first started"),
+	"MCPServer.status":                    describe("Runtime state written by mcop"),
+	"MCPServer.start_time":                describe("Runtime state written by mcop"),
+	"MCPServer.response_time":             describe("Runtime state written by mcop"),
+	"MCPServer.active_connections":        describe("Runtime state written by mcop"),
+	"MCPServer.group":                     describe("Heading the server is listed under; a group's servers can be started and stopped together"),
+	"MCPServer.tags":                      describe("Labels profiles can select the server by"),
+	"Profile.description":                 describe("What the profile is for"),
+	"Profile.servers":                     describe("IDs of servers in the profile"),
+	"Profile.groups":                      describe("Groups whose servers are in the profile"),
+	"Profile.tags":                        describe("Tags whose servers are in the profile"),
+	"ServerConfig.restart_policy":         enum("Whether the server is restarted after its process exits", RestartPolicies...),
+	"ServerConfig.max_restarts":           minimum(0, "Restarts allowed within restart_window before giving up"),
+	"ServerConfig.restart_window":         minimum(0, "Seconds over which max_restarts is counted"),
+	"ServerConfig.restart_backoff_ms":     minimum(0, "Milliseconds to wait before the first restart"),
+	"ServerConfig.restart_max_backoff_ms": minimum(0, "Upper bound of the doubling restart delay, in milliseconds"),
+	"ServerConfig.environment":            describe("Deprecated: moved to the server's env when the file is saved"),
+	"ServerConfig.working_dir":            describe("Deprecated: moved to the server's cwd when the file is saved"),
+}
+
+func describe(description string) func(*mcp.Schema) {
+	return func(s *mcp.Schema) { s.Description = description }
+}
+
+func enum(description string, values ...string) func(*mcp.Schema) {
+	return func(s *mcp.Schema) {
+		s.Description = description
+		for _, value := range values {
+			s.Enum = append(s.Enum, value)
+		}
+	}
+}
+
+func minimum(min float64, description string) func(*mcp.Schema) {
+	return func(s *mcp.Schema) {
+		s.Description = description
+		s.Minimum = &min
+	}
+}
+
+func pattern(re, description string) func(*mcp.Schema) {
+	return func(s *mcp.Schema) {
+		s.Description = description
+		s.Pattern = re
+	}
+}
+
+// Schema returns the JSON Schema of a configuration file, generated from
+// the json tags of AppConfig and the types it contains
+func Schema() *mcp.Schema {
+	schema := schemaFor(reflect.TypeOf(AppConfig{}))
+	schema.Title = "mcop configuration"
+	schema.Properties["$schema"] = &mcp.Schema{
+		Type:        mcp.SchemaType{"string"},
+		Description: "Location of this schema, for editors",
+	}
+	servers := schema.Properties["servers"].Items
+	servers.Required = []string{"id"}
+	return schema
+}
+
+// SchemaJSON returns the schema as published in config/schema.json
+func SchemaJSON() ([]byte, error) {
+	body, err := json.Marshal(Schema())
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal schema: %w", err)
+	}
+	// Put $schema first, as is customary
+	doc := append([]byte(`{"$schema":"https://json-schema.org/draft/2020-12/schema",`), body[1:]...)
+	data, err := marshalWithout(json.RawMessage(doc), nil)
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// schemaFor describes a Go type
+func schemaFor(t reflect.Type) *mcp.Schema {
+	switch t.Kind() {
+	case reflect.String:
+		return &mcp.Schema{Type: mcp.SchemaType{"string"}}
+	case reflect.Bool:
+		return &mcp.Schema{Type: mcp.SchemaType{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &mcp.Schema{Type: mcp.SchemaType{"integer"}}
+	case reflect.Float32, reflect.Float64:
+		return &mcp.Schema{Type: mcp.SchemaType{"number"}}
+	case reflect.Slice, reflect.Array:
+		return &mcp.Schema{Type: mcp.SchemaType{"array"}, Items: schemaFor(t.Elem())}
+	case reflect.Map:
+		return &mcp.Schema{
+			Type:                 mcp.SchemaType{"object"},
+			AdditionalProperties: &mcp.AdditionalProperties{Schema: schemaFor(t.Elem())},
+		}
+	case reflect.Ptr:
+		return schemaFor(t.Elem())
+	case reflect.Struct:
+		schema := &mcp.Schema{
+			Type:                 mcp.SchemaType{"object"},
+			Properties:           make(map[string]*mcp.Schema),
+			AdditionalProperties: &mcp.AdditionalProperties{Forbidden: true},
+		}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if !field.IsExported() || name == "-" || name == "" {
+				continue
+			}
+			property := schemaFor(field.Type)
+			if hint, ok := schemaHints[t.Name()+"."+name]; ok {
+				hint(property)
+			}
+			schema.Properties[name] = property
+			schema.PropertyOrder = append(schema.PropertyOrder, name)
+		}
+		return schema
+	default:
+		// interface{} accepts any value
+		return &mcp.Schema{}
+	}
+}
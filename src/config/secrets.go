@@ -0,0 +1,102 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// SecretsPath returns the file holding the values of ${secret:name}
+// references: $MCOP_SECRETS_FILE, or secrets.json in mcop's user config directory
+func SecretsPath() (string, error) {
+	if path := os.Getenv("MCOP_SECRETS_FILE"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate secrets file: %w", err)
+	}
+	return filepath.Join(dir, "mcop", "secrets.json"), nil
+}
+
+// LoadSecrets reads the secrets file; a missing file holds no secrets
+func LoadSecrets() (map[string]string, error) {
+	path, err := SecretsPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets file: %w", err)
+	}
+	secrets := make(map[string]string)
+	if err := json.Unmarshal(data, &secrets); err != nil {
+		return nil, fmt.Errorf("failed to parse secrets file %s: %w", path, err)
+	}
+	return secrets, nil
+}
+
+// saveSecrets writes the secrets file, readable by the user only
+func saveSecrets(secrets map[string]string) error {
+	path, err := SecretsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create secrets directory: %w", err)
+	}
+	data, err := json.MarshalIndent(secrets, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal secrets: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write secrets file: %w", err)
+	}
+	// WriteFile keeps the mode of an existing file
+	return os.Chmod(path, 0600)
+}
+
+// SetSecret stores the value of a secret
+func SetSecret(name, value string) error {
+	if !secretNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid secret name %q", name)
+	}
+	secrets, err := LoadSecrets()
+	if err != nil {
+		return err
+	}
+	secrets[name] = value
+	return saveSecrets(secrets)
+}
+
+// RemoveSecret deletes a secret and reports whether it existed
+func RemoveSecret(name string) (bool, error) {
+	secrets, err := LoadSecrets()
+	if err != nil {
+		return false, err
+	}
+	if _, ok := secrets[name]; !ok {
+		return false, nil
+	}
+	delete(secrets, name)
+	return true, saveSecrets(secrets)
+}
+
+// SecretNames returns the names of the stored secrets, sorted
+func SecretNames() ([]string, error) {
+	secrets, err := LoadSecrets()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(secrets))
+	for name := range secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,699 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+
+	"mcop/src/types"
+)
+
+// Severities of validation problems
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Problem is something wrong with a configuration
+type Problem struct {
+	File string
+	// Line and Column are 1-based, or 0 when unknown
+	Line   int
+	Column int
+	// Path locates the offending value, as in "servers[1].transport"
+	Path     string
+	Severity string
+	Message  string
+}
+
+// String formats the problem as file:line:column: severity: path: message
+func (p Problem) String() string {
+	var sb strings.Builder
+	if p.File != "" {
+		sb.WriteString(p.File)
+		if p.Line > 0 {
+			fmt.Fprintf(&sb, ":%d", p.Line)
+			if p.Column > 0 {
+				fmt.Fprintf(&sb, ":%d", p.Column)
+			}
+		}
+		sb.WriteString(": ")
+	}
+	sb.WriteString(p.Severity + ": ")
+	if p.Path != "" {
+		sb.WriteString(p.Path + ": ")
+	}
+	sb.WriteString(p.Message)
+	return sb.String()
+}
+
+// Problems runs the semantic checks on the configuration. With a resolver,
+// references to unset environment variables and unknown secrets are
+// reported too.
+func (c *AppConfig) Problems(resolver *Resolver) []Problem {
+	return append(c.fileProblems(resolver), c.mergedProblems()...)
+}
+
+// collect returns a function reporting problems into problems
+func collect(problems *[]Problem) func(severity, path, format string, args ...interface{}) {
+	return func(severity, path, format string, args ...interface{}) {
+		*problems = append(*problems, Problem{Path: path, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+}
+
+// fileProblems runs the checks that hold for each file on its own
+func (c *AppConfig) fileProblems(resolver *Resolver) []Problem {
+	var problems []Problem
+	report := collect(&problems)
+
+	seen := make(map[string]int)
+	for i, server := range c.Servers {
+		path := fmt.Sprintf("servers[%d]", i)
+		switch first, ok := seen[server.ID]; {
+		case server.ID == "":
+			report(SeverityError, path+".id", "server ID cannot be empty")
+		case ok:
+			report(SeverityError, path+".id", "duplicate server ID %q, already used by servers[%d]", server.ID, first)
+		default:
+			seen[server.ID] = i
+		}
+		checkLaunch(server, path, resolver, report)
+		checkReferences(server, path, resolver, report)
+	}
+	for _, name := range sortedKeys(c.APIKeys) {
+		checkValue(c.APIKeys[name], "api_keys."+name, resolver, report)
+	}
+
+	for _, id := range sortedServerConfigs(c.ServerConfigs) {
+		serverConfig := c.ServerConfigs[id]
+		path := "server_configs." + id
+		if !validRestartPolicy(serverConfig.RestartPolicy) {
+			report(SeverityError, path+".restart_policy", "unknown restart policy %q (expected %s)",
+				serverConfig.RestartPolicy, strings.Join(RestartPolicies, ", "))
+		}
+		if serverConfig.RestartMaxBackoff > 0 && serverConfig.RestartMaxBackoff < serverConfig.RestartBackoff {
+			report(SeverityWarning, path+".restart_max_backoff_ms", "is less than restart_backoff_ms")
+		}
+	}
+	return problems
+}
+
+// mergedProblems runs the checks that look across servers and profiles,
+// which a layered configuration may define in different files
+func (c *AppConfig) mergedProblems() []Problem {
+	var problems []Problem
+	report := collect(&problems)
+	checkPorts(c.Servers, report)
+	checkProfiles(c, report)
+	return problems
+}
+
+// checkProfiles reports profile entries that match no server, which are
+// usually typos
+func checkProfiles(c *AppConfig, report func(severity, path, format string, args ...interface{})) {
+	ids, groups, tags := make(map[string]bool), make(map[string]bool), make(map[string]bool)
+	for _, server := range c.Servers {
+		ids[server.ID] = true
+		groups[server.Group] = true
+		for _, tag := range server.Tags {
+			tags[tag] = true
+		}
+	}
+	for _, name := range c.ProfileNames() {
+		profile := c.Profiles[name]
+		path := "profiles." + name
+		for i, id := range profile.Servers {
+			if !ids[id] {
+				report(SeverityWarning, fmt.Sprintf("%s.servers[%d]", path, i), "no server has ID %q", id)
+			}
+		}
+		for i, group := range profile.Groups {
+			if !groups[group] {
+				report(SeverityWarning, fmt.Sprintf("%s.groups[%d]", path, i), "no server is in group %q", group)
+			}
+		}
+		for i, tag := range profile.Tags {
+			if !tags[tag] {
+				report(SeverityWarning, fmt.Sprintf("%s.tags[%d]", path, i), "no server is tagged %q", tag)
+			}
+		}
+	}
+}
+
+// validRestartPolicy reports whether policy is empty or one of RestartPolicies
+func validRestartPolicy(policy string) bool {
+	if policy == "" {
+		return true
+	}
+	for _, valid := range RestartPolicies {
+		if policy == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// checkLaunch reports servers whose transport cannot be determined or does
+// not fit their other settings. Servers with neither a command nor a URL are
+// reached at $DEFAULT_MCP_URL, so they are only an error when a resolver
+// finds it unset.
+func checkLaunch(server MCPServer, path string, resolver *Resolver, report func(severity, path, format string, args ...interface{})) {
+	if server.Command == "" && server.URL == "" {
+		if resolver == nil {
+			report(SeverityWarning, path, "has no command or URL, so it connects to $DEFAULT_MCP_URL")
+		} else if url, _ := resolver.LookupEnv("DEFAULT_MCP_URL"); url == "" {
+			report(SeverityError, path, "server command or URL cannot be empty unless DEFAULT_MCP_URL is set")
+		}
+		return
+	}
+	if server.URL != "" && !strings.HasPrefix(server.URL, "${") {
+		// stdio:// URLs hold a command line, which url.Parse may reject
+		scheme, _, _ := strings.Cut(server.URL, "://")
+		switch scheme {
+		case "stdio", "http", "https":
+		default:
+			report(SeverityError, path+".url", "unknown transport scheme %q (expected stdio, http or https)", scheme)
+			return
+		}
+	}
+
+	switch server.Transport {
+	case "", types.TransportStdio:
+		if server.Transport != "" && server.Command == "" && !strings.HasPrefix(server.URL, "stdio://") {
+			report(SeverityError, path+".transport", "stdio servers need a command")
+		}
+	case types.TransportHTTP, types.TransportSSE:
+		if server.URL == "" || strings.HasPrefix(server.URL, "stdio://") {
+			report(SeverityError, path+".transport", "%s servers need an http or https url", server.Transport)
+		} else if server.Command != "" {
+			report(SeverityWarning, path+".command", "is ignored for %s servers", server.Transport)
+		}
+	default:
+		report(SeverityError, path+".transport", "unknown transport %q (expected stdio, http or sse)", server.Transport)
+	}
+}
+
+// checkReferences checks the ${...} references in a server's launch settings
+func checkReferences(server MCPServer, path string, resolver *Resolver, report func(severity, path, format string, args ...interface{})) {
+	checkValue(server.Command, path+".command", resolver, report)
+	checkValue(server.Cwd, path+".cwd", resolver, report)
+	checkValue(server.URL, path+".url", resolver, report)
+	for i, arg := range server.Args {
+		checkValue(arg, fmt.Sprintf("%s.args[%d]", path, i), resolver, report)
+	}
+	for _, key := range sortedKeys(server.Env) {
+		checkValue(server.Env[key], path+".env."+key, resolver, report)
+	}
+	for _, key := range sortedKeys(server.Headers) {
+		checkValue(server.Headers[key], path+".headers."+key, resolver, report)
+	}
+}
+
+// checkValue reports malformed references in value and, with a resolver,
+// references that cannot be resolved
+func checkValue(value, path string, resolver *Resolver, report func(severity, path, format string, args ...interface{})) {
+	refs, err := References(value)
+	if err != nil {
+		report(SeverityError, path, "%v", err)
+		return
+	}
+	if resolver == nil {
+		return
+	}
+	for _, ref := range refs {
+		if _, err := resolver.lookup(ref); err != nil {
+			report(SeverityError, path, "%v", err)
+		}
+	}
+}
+
+// portClaim is a local port a server listens on or connects to
+type portClaim struct {
+	index int
+	path  string
+	// listens is set for ports a stdio server's process binds
+	listens bool
+}
+
+var portArgPattern = regexp.MustCompile(`^--?(?:port|p)(?:=(\d+))?$`)
+
+// checkPorts reports stdio servers that would bind a port another server
+// binds or is reached at. Several remote servers may share a port, since
+// one process can serve several endpoints.
+func checkPorts(servers []MCPServer, report func(severity, path, format string, args ...interface{})) {
+	claims := make(map[int][]portClaim)
+	for i, server := range servers {
+		path := fmt.Sprintf("servers[%d]", i)
+		if server.URL != "" && !strings.HasPrefix(server.URL, "stdio://") {
+			if port, ok := localPort(server.URL); ok {
+				claims[port] = append(claims[port], portClaim{i, path + ".url", false})
+			}
+		}
+		if server.Command == "" {
+			continue
+		}
+		for j, arg := range server.Args {
+			match := portArgPattern.FindStringSubmatch(arg)
+			if match == nil {
+				continue
+			}
+			value := match[1]
+			if value == "" && j+1 < len(server.Args) {
+				value = server.Args[j+1]
+			}
+			if port, err := strconv.Atoi(value); err == nil {
+				claims[port] = append(claims[port], portClaim{i, fmt.Sprintf("%s.args[%d]", path, j), true})
+			}
+		}
+		if port, err := strconv.Atoi(server.Env["PORT"]); err == nil {
+			claims[port] = append(claims[port], portClaim{i, path + ".env.PORT", true})
+		}
+	}
+
+	ports := make([]int, 0, len(claims))
+	for port := range claims {
+		ports = append(ports, port)
+	}
+	sort.Ints(ports)
+	for _, port := range ports {
+		var first *portClaim
+		for _, claim := range claims[port] {
+			claim := claim
+			if first == nil || first.index == claim.index {
+				if first == nil {
+					first = &claim
+				}
+				continue
+			}
+			if first.listens || claim.listens {
+				report(SeverityError, claim.path, "port %d conflicts with server %s", port, servers[first.index].ID)
+			}
+		}
+	}
+}
+
+// localPort returns the port of a URL on the local machine
+func localPort(rawURL string) (int, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Port() == "" {
+		return 0, false
+	}
+	host := u.Hostname()
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !(ip.IsLoopback() || ip.IsUnspecified())) {
+		return 0, false
+	}
+	port, err := strconv.Atoi(u.Port())
+	return port, err == nil
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedServerConfigs(configs map[string]ServerConfig) []string {
+	ids := make([]string, 0, len(configs))
+	for id := range configs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// ValidateFile checks a configuration file against Schema and the semantic
+// checks of Problems, locating each problem in the file. With a resolver,
+// unresolved references are reported too. The error is only set when the
+// file cannot be read.
+func ValidateFile(path string, resolver *Resolver) ([]Problem, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	return ValidateData(path, data, resolver), nil
+}
+
+// ValidateData checks the contents of a configuration file named path,
+// whose extension selects the format
+func ValidateData(path string, data []byte, resolver *Resolver) []Problem {
+	return locateProblems(path, data, validateData(path, data, resolver, (*AppConfig).Problems))
+}
+
+// ValidateLayers checks the files of a layered configuration. Each file is
+// checked against Schema and for its own mistakes, while ports and profiles
+// are checked on the merged configuration, since a profile may name a server
+// defined in another file. Those problems are located in the file defining
+// the server or profile at fault. The error is only set when a file cannot
+// be read.
+func ValidateLayers(layers []Layer, resolver *Resolver) ([]Problem, error) {
+	var problems []Problem
+	files := make(map[string][]byte, len(layers))
+	for _, layer := range layers {
+		data, err := os.ReadFile(layer.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		files[layer.Path] = data
+		problems = append(problems, locateProblems(layer.Path, data, validateData(layer.Path, data, resolver, (*AppConfig).fileProblems))...)
+	}
+
+	merged, err := mergeLayers(layers)
+	if err != nil {
+		// The files that cannot be parsed are already reported
+		return problems, nil
+	}
+	for _, problem := range merged.mergedProblems() {
+		file, path, ok := merged.layerPath(problem.Path, layers)
+		if !ok {
+			// Report it against the top file, where it cannot be located
+			file, path = layers[len(layers)-1].Path, ""
+			problem.Message = problem.Path + ": " + problem.Message
+		}
+		problem.Path = path
+		problems = append(problems, locateProblems(file, files[file], []Problem{problem})...)
+	}
+
+	order := make(map[string]int, len(layers))
+	for i, layer := range layers {
+		order[layer.Path] = i
+	}
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].File != problems[j].File {
+			return order[problems[i].File] < order[problems[j].File]
+		}
+		if problems[i].Line != problems[j].Line {
+			return problems[i].Line < problems[j].Line
+		}
+		return problems[i].Column < problems[j].Column
+	})
+	return problems, nil
+}
+
+// layerPath finds the value at path in the merged configuration in the
+// file of layers defining it, returning the file and the value's path there
+func (c *AppConfig) layerPath(path string, layers []Layer) (string, string, bool) {
+	var index int
+	if _, err := fmt.Sscanf(path, "servers[%d]", &index); err == nil && index < len(c.Servers) {
+		id := c.Servers[index].ID
+		file := c.origins[id]
+		cfg, _, err := readLayer(file)
+		if err != nil {
+			return "", "", false
+		}
+		// A later definition in the same file replaces an earlier one
+		for i := len(cfg.Servers) - 1; i >= 0; i-- {
+			if cfg.Servers[i].ID == id {
+				rest := path[strings.IndexByte(path, ']')+1:]
+				return file, fmt.Sprintf("servers[%d]%s", i, rest), true
+			}
+		}
+		return "", "", false
+	}
+	// Profile names may contain dots, so take the longest that matches
+	profile := ""
+	for _, name := range c.ProfileNames() {
+		if (path == "profiles."+name || strings.HasPrefix(path, "profiles."+name+".")) && len(name) > len(profile) {
+			profile = name
+		}
+	}
+	if profile == "" {
+		return "", "", false
+	}
+	for i := len(layers) - 1; i >= 0; i-- {
+		if cfg, _, err := readLayer(layers[i].Path); err == nil {
+			if _, ok := cfg.Profiles[profile]; ok {
+				return layers[i].Path, path, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// locateProblems sets the file and position of problems found in data, and
+// sorts them by position
+func locateProblems(path string, data []byte, problems []Problem) []Problem {
+	positions := locate(path, data)
+	for i := range problems {
+		problems[i].File = path
+		if problems[i].Line == 0 {
+			problems[i].Line, problems[i].Column = positions.find(problems[i].Path)
+		}
+	}
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Line != problems[j].Line {
+			return problems[i].Line < problems[j].Line
+		}
+		return problems[i].Column < problems[j].Column
+	})
+	return problems
+}
+
+// validateData parses a configuration file and runs the schema and checks
+// on it
+func validateData(path string, data []byte, resolver *Resolver, checks func(*AppConfig, *Resolver) []Problem) []Problem {
+	decoded, err := decodeConfigFile(path, data)
+	var doc interface{}
+	if err == nil {
+		err = json.Unmarshal(decoded, &doc)
+	}
+	if err != nil {
+		line, column := errorPosition(data, err)
+		return []Problem{{Line: line, Column: column, Severity: SeverityError, Message: err.Error()}}
+	}
+
+	var problems []Problem
+	reported := make(map[string]bool)
+	for _, problem := range Schema().Problems(doc) {
+		problems = append(problems, Problem{Path: problem.Path, Severity: SeverityError, Message: problem.Message})
+		reported[problem.Path] = true
+	}
+
+	var cfg AppConfig
+	// Values of the wrong type are left out, and the schema problems already
+	// report them
+	var typeErr *json.UnmarshalTypeError
+	if err := json.Unmarshal(decoded, &cfg); err != nil && !errors.As(err, &typeErr) {
+		return problems
+	}
+	for _, problem := range checks(&cfg, resolver) {
+		if !reported[problem.Path] {
+			problems = append(problems, problem)
+		}
+	}
+	return problems
+}
+
+// errorPosition extracts the line and column of a parse error
+func errorPosition(data []byte, err error) (int, int) {
+	var syntaxErr *json.SyntaxError
+	var decodeErr *toml.DecodeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		return offsetPosition(data, int(syntaxErr.Offset))
+	case errors.As(err, &decodeErr):
+		return decodeErr.Position()
+	}
+	if match := yamlLinePattern.FindStringSubmatch(err.Error()); match != nil {
+		line, _ := strconv.Atoi(match[1])
+		return line, 0
+	}
+	return 0, 0
+}
+
+var yamlLinePattern = regexp.MustCompile(`line (\d+)`)
+
+// offsetPosition converts a byte offset to a line and column
+func offsetPosition(data []byte, offset int) (int, int) {
+	if offset > len(data) {
+		offset = len(data)
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := offset - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
+
+// positions maps value paths to their line and column in a file
+type positions map[string][2]int
+
+// find returns the position of path, or of its closest ancestor in the file
+func (p positions) find(path string) (int, int) {
+	for {
+		if pos, ok := p[path]; ok {
+			return pos[0], pos[1]
+		}
+		if path == "" {
+			return 0, 0
+		}
+		path = parentPath(path)
+	}
+}
+
+// parentPath strips the last element from a path
+func parentPath(path string) string {
+	if strings.HasSuffix(path, "]") {
+		if i := strings.LastIndexByte(path, '['); i >= 0 {
+			return path[:i]
+		}
+	}
+	if i := strings.LastIndexByte(path, '.'); i >= 0 {
+		return path[:i]
+	}
+	return ""
+}
+
+func childPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// locate indexes the positions of the values in a file
+func locate(path string, data []byte) positions {
+	found := make(positions)
+	switch FileFormat(path) {
+	case FileFormatYAML:
+		var doc yaml.Node
+		if yaml.Unmarshal(data, &doc) == nil && len(doc.Content) > 0 {
+			locateYAML(doc.Content[0], "", found)
+		}
+	case FileFormatTOML:
+		locateTOML(data, found)
+	default:
+		locateJSON(data, found)
+	}
+	return found
+}
+
+func locateYAML(node *yaml.Node, path string, found positions) {
+	if _, ok := found[path]; !ok {
+		found[path] = [2]int{node.Line, node.Column}
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			child := childPath(path, key.Value)
+			found[child] = [2]int{key.Line, key.Column}
+			locateYAML(node.Content[i+1], child, found)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			locateYAML(item, fmt.Sprintf("%s[%d]", path, i), found)
+		}
+	}
+}
+
+func locateJSON(data []byte, found positions) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	position := func() [2]int {
+		offset := int(decoder.InputOffset())
+		for offset < len(data) && strings.IndexByte(" \t\r\n:,", data[offset]) >= 0 {
+			offset++
+		}
+		line, column := offsetPosition(data, offset)
+		return [2]int{line, column}
+	}
+
+	var walk func(path string) error
+	walk = func(path string) error {
+		if _, ok := found[path]; !ok {
+			found[path] = position()
+		}
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case json.Delim('{'):
+			for decoder.More() {
+				keyPosition := position()
+				key, err := decoder.Token()
+				if err != nil {
+					return err
+				}
+				child := childPath(path, fmt.Sprint(key))
+				found[child] = keyPosition
+				if err := walk(child); err != nil {
+					return err
+				}
+			}
+			_, err = decoder.Token()
+		case json.Delim('['):
+			for i := 0; decoder.More(); i++ {
+				if err := walk(fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+			_, err = decoder.Token()
+		}
+		return err
+	}
+	walk("")
+}
+
+// locateTOML finds tables and keys line by line; values spanning several
+// lines are located at their key
+func locateTOML(data []byte, found positions) {
+	table := ""
+	counts := make(map[string]int)
+	current := make(map[string]string) // array of tables name -> path of its last element
+	resolve := func(name string) string {
+		// A table nested in an array of tables belongs to its last element
+		for prefix, element := range current {
+			if strings.HasPrefix(name, prefix+".") {
+				return element + name[len(prefix):]
+			}
+		}
+		return name
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		trimmed := strings.TrimSpace(text)
+		column := len(text) - len(strings.TrimLeft(text, " \t")) + 1
+		switch {
+		case trimmed == "" || strings.HasPrefix(trimmed, "#"):
+		case strings.HasPrefix(trimmed, "[["):
+			name := tomlKey(strings.TrimSuffix(strings.TrimPrefix(trimmed, "[["), "]]"))
+			element := fmt.Sprintf("%s[%d]", resolve(name), counts[name])
+			counts[name]++
+			current[name] = element
+			table = element
+			found[table] = [2]int{line, column}
+		case strings.HasPrefix(trimmed, "["):
+			table = resolve(tomlKey(strings.TrimSuffix(strings.TrimPrefix(trimmed, "["), "]")))
+			found[table] = [2]int{line, column}
+		default:
+			if i := strings.IndexByte(trimmed, '='); i > 0 {
+				found[childPath(table, tomlKey(trimmed[:i]))] = [2]int{line, column}
+			}
+		}
+	}
+}
+
+// tomlKey normalizes a possibly dotted and quoted TOML key
+func tomlKey(key string) string {
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		parts[i] = strings.Trim(strings.TrimSpace(part), `"'`)
+	}
+	return strings.Join(parts, ".")
+}
@@ -9,6 +9,8 @@ import (
 	"strings"
 	"time"
 
+	"mcop/src/config"
+	"mcop/src/mcp"
 	"mcop/src/types"
 )
 
@@ -44,18 +46,19 @@ func (d *DiscoveryService) DiscoverLocalServers() ([]ServerInfo, error) {
 	
 	for _, port := range ports {
 		url := fmt.Sprintf("http://localhost:%d", port)
-		if d.isMCPServer(url) {
+		server := types.MCPServer{ID: url, URL: url}
+		if d.isMCPServer(server) {
 			serverInfo := ServerInfo{
 				ID:          fmt.Sprintf("local_%d", port),
 				Name:        fmt.Sprintf("Local MCP Server (Port %d)", port),
 				URL:         url,
 				Status:      "running",
 				Description: fmt.Sprintf("MCP server running on localhost:%d", port),
-				ResponseTime: d.getResponseTime(url),
+				ResponseTime: d.getResponseTime(server),
 			}
 			
 			// Try to get tools from the server
-			tools, err := d.getServerTools(url)
+			tools, err := d.getServerTools(server)
 			if err == nil {
 				serverInfo.Tools = tools
 			}
@@ -88,18 +91,19 @@ func (d *DiscoveryService) DiscoverNetworkServers() ([]ServerInfo, error) {
 		
 		for _, port := range ports {
 			url := fmt.Sprintf("http://%s:%d", ip, port)
-			if d.isMCPServer(url) {
+			server := types.MCPServer{ID: url, URL: url}
+			if d.isMCPServer(server) {
 				serverInfo := ServerInfo{
 					ID:          fmt.Sprintf("network_%s_%d", strings.ReplaceAll(ip, ".", "_"), port),
 					Name:        fmt.Sprintf("Network MCP Server (%s:%d)", ip, port),
 					URL:         url,
 					Status:      "running",
 					Description: fmt.Sprintf("MCP server running on %s:%d", ip, port),
-					ResponseTime: d.getResponseTime(url),
+					ResponseTime: d.getResponseTime(server),
 				}
 				
 				// Try to get tools from the server
-				tools, err := d.getServerTools(url)
+				tools, err := d.getServerTools(server)
 				if err == nil {
 					serverInfo.Tools = tools
 				}
@@ -118,32 +122,37 @@ func (d *DiscoveryService) DiscoverFromConfig(configuredServers []types.MCPServe
 
 	for _, configuredServer := range configuredServers {
 		// Check if the server is a stdio-based server
-		if strings.HasPrefix(configuredServer.URL, "stdio://") {
+		if configuredServer.TransportType() == types.TransportStdio {
 			// For stdio servers, we can't really discover them in the network sense
 			// but we can represent them as available
 			serverInfo := ServerInfo{
 				ID:          configuredServer.ID,
 				Name:        configuredServer.Name,
-				URL:         configuredServer.URL,
+				URL:         configuredServer.Endpoint(),
 				Status:      configuredServer.Status,
 				Description: configuredServer.Description,
 			}
 
 			servers = append(servers, serverInfo)
 		} else if strings.HasPrefix(configuredServer.URL, "http://") || strings.HasPrefix(configuredServer.URL, "https://") {
+			// Reach the server with its configured headers and transport
+			server, err := config.ResolveServer(configuredServer)
+			if err != nil {
+				continue
+			}
 			// Check if the HTTP-based server is reachable
-			if d.isMCPServer(configuredServer.URL) {
+			if d.isMCPServer(server) {
 				serverInfo := ServerInfo{
 					ID:          configuredServer.ID,
 					Name:        configuredServer.Name,
 					URL:         configuredServer.URL,
 					Status:      "running",
 					Description: configuredServer.Description,
-					ResponseTime: d.getResponseTime(configuredServer.URL),
+					ResponseTime: d.getResponseTime(server),
 				}
 
 				// Try to get tools from the server
-				tools, err := d.getServerTools(configuredServer.URL)
+				tools, err := d.getServerTools(server)
 				if err == nil {
 					serverInfo.Tools = tools
 				}
@@ -156,8 +165,8 @@ func (d *DiscoveryService) DiscoverFromConfig(configuredServers []types.MCPServe
 	return servers, nil
 }
 
-// isMCPServer checks if the given URL is an MCP server
-func (d *DiscoveryService) isMCPServer(url string) bool {
+// isMCPServer checks if the server's URL is an MCP server
+func (d *DiscoveryService) isMCPServer(server types.MCPServer) bool {
 	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
 	defer cancel()
 	
@@ -166,7 +175,7 @@ func (d *DiscoveryService) isMCPServer(url string) bool {
 		Timeout: d.timeout,
 	}
 	
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
 	if err != nil {
 		return false
 	}
@@ -174,6 +183,10 @@ func (d *DiscoveryService) isMCPServer(url string) bool {
 	// Add common headers that MCP servers might expect
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "MCOP-Discovery/1.0")
+	// and the configured ones, such as credentials
+	for name, value := range server.Headers {
+		req.Header.Set(name, value)
+	}
 	
 	resp, err := client.Do(req)
 	if err != nil {
@@ -188,7 +201,7 @@ func (d *DiscoveryService) isMCPServer(url string) bool {
 }
 
 // getResponseTime measures the response time of a server
-func (d *DiscoveryService) getResponseTime(url string) time.Duration {
+func (d *DiscoveryService) getResponseTime(server types.MCPServer) time.Duration {
 	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
 	defer cancel()
 	
@@ -198,10 +211,13 @@ func (d *DiscoveryService) getResponseTime(url string) time.Duration {
 	
 	start := time.Now()
 	
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
 	if err != nil {
 		return d.timeout // Return timeout duration if request creation fails
 	}
+	for name, value := range server.Headers {
+		req.Header.Set(name, value)
+	}
 	
 	resp, err := client.Do(req)
 	if err != nil {
@@ -212,12 +228,22 @@ func (d *DiscoveryService) getResponseTime(url string) time.Duration {
 	return time.Since(start)
 }
 
-// getServerTools tries to get the tools from an MCP server
-func (d *DiscoveryService) getServerTools(url string) ([]string, error) {
-	// This would typically make an API call to the server to list its tools
-	// For now, return an empty slice
-	// In a real implementation, you would call an endpoint like /tools or make an MCP list_tools call
-	return []string{}, nil
+// getServerTools connects to an MCP server and lists its tools via tools/list
+func (d *DiscoveryService) getServerTools(server types.MCPServer) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	defer cancel()
+
+	client := mcp.NewMCPClient(server)
+	if err := client.ConnectContext(ctx); err != nil {
+		return nil, err
+	}
+	defer client.Disconnect()
+
+	tools, err := client.ListTools(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return types.ToolNames(tools), nil
 }
 
 // getLocalIPs gets all local IP addresses
@@ -0,0 +1,230 @@
+package logs
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level is an MCP log severity, ordered from least to most severe
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelNotice
+	LevelWarning
+	LevelError
+	LevelCritical
+	LevelAlert
+	LevelEmergency
+)
+
+var levelNames = [...]string{"debug", "info", "notice", "warning", "error", "critical", "alert", "emergency"}
+
+// String returns the MCP name of the level
+func (l Level) String() string {
+	if l < LevelDebug || l > LevelEmergency {
+		return "unknown"
+	}
+	return levelNames[l]
+}
+
+// ParseLevel converts an MCP level name into a Level
+func ParseLevel(name string) (Level, bool) {
+	for i, levelName := range levelNames {
+		if strings.EqualFold(name, levelName) {
+			return Level(i), true
+		}
+	}
+	return LevelInfo, false
+}
+
+// Sources of log entries
+const (
+	SourceStderr = "stderr" // a line the server wrote to stderr
+	SourceMCP    = "mcp"    // a notifications/message log notification
+	SourceMCOP   = "mcop"   // an operational message from mcop itself
+)
+
+// Entry is a single log line
+type Entry struct {
+	Seq      uint64
+	Time     time.Time
+	ServerID string
+	Source   string
+	Level    Level
+	Logger   string
+	Text     string
+}
+
+// Ring is a fixed capacity buffer that keeps the most recent entries
+type Ring struct {
+	entries []Entry
+	start   int
+	size    int
+}
+
+// NewRing creates a ring holding at most capacity entries
+func NewRing(capacity int) *Ring {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &Ring{entries: make([]Entry, capacity)}
+}
+
+// Add appends an entry, overwriting the oldest one when the ring is full
+func (r *Ring) Add(entry Entry) {
+	if r.size < len(r.entries) {
+		r.entries[(r.start+r.size)%len(r.entries)] = entry
+		r.size++
+		return
+	}
+	r.entries[r.start] = entry
+	r.start = (r.start + 1) % len(r.entries)
+}
+
+// Len returns the number of entries held
+func (r *Ring) Len() int {
+	return r.size
+}
+
+// Entries returns the held entries, oldest first
+func (r *Ring) Entries() []Entry {
+	entries := make([]Entry, r.size)
+	for i := range entries {
+		entries[i] = r.entries[(r.start+i)%len(r.entries)]
+	}
+	return entries
+}
+
+// Filter selects entries by server and minimum severity
+type Filter struct {
+	// ServerID restricts entries to one server; empty means all servers and mcop's own messages
+	ServerID string
+	MinLevel Level
+}
+
+// Match reports whether entry passes the filter
+func (f Filter) Match(entry Entry) bool {
+	if f.ServerID != "" && entry.ServerID != f.ServerID {
+		return false
+	}
+	return entry.Level >= f.MinLevel
+}
+
+// Store keeps a ring of entries per server, plus one for messages that
+// are not about any server. It is safe for concurrent use.
+type Store struct {
+	mu       sync.Mutex
+	capacity int
+	rings    map[string]*Ring
+	seq      uint64
+	onAppend func()
+}
+
+// NewStore creates a store keeping capacity entries per server; onAppend,
+// if set, is called after every append and must not block
+func NewStore(capacity int, onAppend func()) *Store {
+	return &Store{
+		capacity: capacity,
+		rings:    make(map[string]*Ring),
+		onAppend: onAppend,
+	}
+}
+
+// Append records an entry, stamping its sequence number and, if unset, its time
+func (s *Store) Append(entry Entry) {
+	s.mu.Lock()
+	s.seq++
+	entry.Seq = s.seq
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	ring, ok := s.rings[entry.ServerID]
+	if !ok {
+		ring = NewRing(s.capacity)
+		s.rings[entry.ServerID] = ring
+	}
+	ring.Add(entry)
+	s.mu.Unlock()
+
+	if s.onAppend != nil {
+		s.onAppend()
+	}
+}
+
+// Entries returns the entries matching filter in the order they were appended
+func (s *Store) Entries(filter Filter) []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var entries []Entry
+	for serverID, ring := range s.rings {
+		if filter.ServerID != "" && serverID != filter.ServerID {
+			continue
+		}
+		for _, entry := range ring.Entries() {
+			if filter.Match(entry) {
+				entries = append(entries, entry)
+			}
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Seq < entries[j].Seq })
+	return entries
+}
+
+// StderrWriter returns a writer that records each line written to it as a
+// stderr entry of the server
+func (s *Store) StderrWriter(serverID string) io.Writer {
+	return &lineWriter{store: s, serverID: serverID}
+}
+
+// lineWriter splits a byte stream into stderr entries, buffering partial lines
+type lineWriter struct {
+	store    *Store
+	serverID string
+	buf      []byte
+}
+
+func (w *lineWriter) Write(data []byte) (int, error) {
+	w.buf = append(w.buf, data...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		line := strings.TrimRight(string(w.buf[:i]), "\r")
+		w.buf = w.buf[i+1:]
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		w.store.Append(Entry{
+			ServerID: w.serverID,
+			Source:   SourceStderr,
+			Level:    GuessLevel(line),
+			Text:     line,
+		})
+	}
+	return len(data), nil
+}
+
+// GuessLevel infers the severity of a free-form stderr line from common
+// keywords; lines without one are treated as info
+func GuessLevel(line string) Level {
+	lower := strings.ToLower(line)
+	switch {
+	case strings.Contains(lower, "panic"), strings.Contains(lower, "fatal"), strings.Contains(lower, "critical"):
+		return LevelCritical
+	case strings.Contains(lower, "error"), strings.Contains(lower, "traceback"), strings.Contains(lower, "exception"):
+		return LevelError
+	case strings.Contains(lower, "warn"):
+		return LevelWarning
+	case strings.Contains(lower, "debug"):
+		return LevelDebug
+	}
+	return LevelInfo
+}
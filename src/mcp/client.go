@@ -1,12 +1,13 @@
 package mcp
 
 import (
-	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"mcop/src/types"
@@ -14,148 +15,380 @@ import (
 
 // MCPClient handles communication with MCP servers
 type MCPClient struct {
-	Server   types.MCPServer
-	cmd      *exec.Cmd
-	stdin    io.WriteCloser
-	stdout   io.ReadCloser
-	ctx      context.Context
-	cancel   context.CancelFunc
+	Server    types.MCPServer
+	transport Transport
+	ctx       context.Context
+	cancel    context.CancelFunc
 	connected bool
-}
 
-// MCPRequest represents an MCP request
-type MCPRequest struct {
-	Method  string      `json:"method"`
-	ID      string      `json:"id"`
-	Version string      `json:"version"`
-	Params  interface{} `json:"params,omitempty"`
-}
+	// RequestTimeout bounds calls whose context carries no deadline
+	RequestTimeout time.Duration
 
-// MCPResponse represents an MCP response
-type MCPResponse struct {
-	ID     string      `json:"id"`
-	Result interface{} `json:"result,omitempty"`
-	Error  *MCPError   `json:"error,omitempty"`
-}
+	nextID     int64
+	initResult InitializeResult
+
+	mu        sync.Mutex
+	pending   map[RequestID]chan *MCPResponse
+	handlers  map[string][]NotificationHandler
+	observers []CallObserver
+	closed    bool
+	done      chan struct{}
 
-// MCPError represents an MCP error
-type MCPError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+	// frameObservers are told about every frame; traced holds the sent
+	// requests awaiting a response, to report response latency
+	frameObservers []FrameObserver
+	traced         map[RequestID]tracedRequest
 }
 
+// NotificationHandler is called for each matching notification received from the server
+type NotificationHandler func(notification *MCPNotification)
+
+// CallObserver is told about every completed call: its method, how long it
+// took and the error it failed with, if any
+type CallObserver func(method string, latency time.Duration, err error)
+
+// maxMessageSize bounds the size of a single frame read from a server
+const maxMessageSize = 16 * 1024 * 1024
+
+// DefaultRequestTimeout is applied to calls made without a deadline
+const DefaultRequestTimeout = 60 * time.Second
+
+// ErrNotConnected is returned when calling a client that has no live session
+var ErrNotConnected = errors.New("not connected to server")
+
+// ErrConnectionClosed is returned to pending calls when the server goes away
+var ErrConnectionClosed = errors.New("connection to server closed")
+
 // NewMCPClient creates a new MCP client
 func NewMCPClient(server types.MCPServer) *MCPClient {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &MCPClient{
-		Server: server,
-		ctx:    ctx,
-		cancel: cancel,
+		Server:         server,
+		ctx:            ctx,
+		cancel:         cancel,
+		RequestTimeout: DefaultRequestTimeout,
+		pending:        make(map[RequestID]chan *MCPResponse),
+		handlers:       make(map[string][]NotificationHandler),
+		traced:         make(map[RequestID]tracedRequest),
+		done:           make(chan struct{}),
 	}
 }
 
-// Connect establishes a connection to the MCP server
-func (c *MCPClient) Connect() error {
-	// Parse the server URL to determine connection method
-	if c.Server.URL == "" {
-		return fmt.Errorf("server URL is empty")
-	}
+// NewMCPClientWithTransport creates a client that talks over the given
+// transport instead of one derived from the server URL
+func NewMCPClientWithTransport(server types.MCPServer, transport Transport) *MCPClient {
+	client := NewMCPClient(server)
+	client.transport = transport
+	return client
+}
 
-	if c.Server.URL[:7] == "stdio://" {
-		// Handle stdio-based connection
-		command := c.Server.URL[8:] // Remove "stdio://" prefix
-		parts := parseCommand(command)
-		if len(parts) == 0 {
-			return fmt.Errorf("invalid command: %s", command)
-		}
+// Connect opens the transport to the MCP server and performs the initialize handshake
+func (c *MCPClient) Connect() error {
+	ctx, cancel := context.WithTimeout(context.Background(), initializeTimeout)
+	defer cancel()
+	return c.ConnectContext(ctx)
+}
 
-		c.cmd = exec.CommandContext(c.ctx, parts[0], parts[1:]...)
-		var err error
-		c.stdin, err = c.cmd.StdinPipe()
+// ConnectContext is like Connect but ctx bounds the handshake. The connection
+// itself stays open after ctx ends, until Disconnect is called.
+func (c *MCPClient) ConnectContext(ctx context.Context) error {
+	if c.transport == nil {
+		transport, err := NewTransport(c.Server)
 		if err != nil {
-			return fmt.Errorf("failed to create stdin pipe: %w", err)
+			return err
 		}
+		c.transport = transport
+	}
 
-		c.stdout, err = c.cmd.StdoutPipe()
-		if err != nil {
-			return fmt.Errorf("failed to create stdout pipe: %w", err)
-		}
+	if err := c.transport.Start(c.ctx); err != nil {
+		return err
+	}
 
-		if err := c.cmd.Start(); err != nil {
-			return fmt.Errorf("failed to start command: %w", err)
-		}
+	go c.readLoop()
 
-		c.connected = true
-		go c.readLoop()
-		return nil
+	if err := c.initialize(ctx); err != nil {
+		c.Disconnect()
+		return fmt.Errorf("initialization failed: %w", err)
+	}
+
+	if st, ok := c.transport.(sessionTransport); ok {
+		st.listen()
 	}
 
-	// For now, only stdio is supported
-	return fmt.Errorf("unsupported protocol: %s", c.Server.URL[:7])
+	c.mu.Lock()
+	c.connected = !c.closed
+	c.mu.Unlock()
+	return nil
+}
+
+// initialize negotiates the protocol version and capabilities with the server
+func (c *MCPClient) initialize(ctx context.Context) error {
+	params := InitializeParams{
+		ProtocolVersion: LatestProtocolVersion,
+		Capabilities:    ClientCapabilities{},
+		ClientInfo:      ClientInfo,
+	}
+	resp, err := c.call(ctx, "initialize", params)
+	if err != nil {
+		return err
+	}
+
+	var result InitializeResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return fmt.Errorf("invalid initialize result: %w", err)
+	}
+	if !isSupportedProtocolVersion(result.ProtocolVersion) {
+		return fmt.Errorf("server requested unsupported protocol version %q (supported: %s)",
+			result.ProtocolVersion, strings.Join(SupportedProtocolVersions, ", "))
+	}
+
+	c.initResult = result
+	if st, ok := c.transport.(sessionTransport); ok {
+		st.setProtocolVersion(result.ProtocolVersion)
+	}
+
+	return c.notify("notifications/initialized", nil)
 }
 
 // Disconnect closes the connection to the MCP server
 func (c *MCPClient) Disconnect() error {
+	c.mu.Lock()
 	c.connected = false
+	c.mu.Unlock()
+
 	if c.cancel != nil {
 		c.cancel()
 	}
-	if c.cmd != nil {
-		return c.cmd.Process.Kill()
+	if c.transport != nil {
+		return c.transport.Close()
 	}
-	if c.stdin != nil {
-		c.stdin.Close()
+	return nil
+}
+
+// Shutdown disconnects from the server, giving a stdio server's process up
+// to timeout to exit on its own before it is signalled
+func (c *MCPClient) Shutdown(timeout time.Duration) error {
+	stdio, ok := c.transport.(*StdioTransport)
+	if !ok {
+		return c.Disconnect()
 	}
-	if c.stdout != nil {
-		c.stdout.Close()
+	c.mu.Lock()
+	c.connected = false
+	c.mu.Unlock()
+
+	// Cancelling first would kill the process, which runs under the client's context
+	err := stdio.Shutdown(timeout)
+	if c.cancel != nil {
+		c.cancel()
 	}
-	return nil
+	return err
 }
 
-// readLoop handles reading responses from the MCP server
+// readLoop reads frames from the server and dispatches them until the stream ends
 func (c *MCPClient) readLoop() {
-	scanner := bufio.NewScanner(c.stdout)
-	for scanner.Scan() {
-		if !c.connected {
-			break
+	defer c.shutdown()
+
+	for {
+		data, err := c.transport.Receive()
+		if err != nil {
+			return
+		}
+		messages, err := ParseMessages(data)
+		if err != nil {
+			// Servers may print non-protocol output; skip anything that is not JSON-RPC
+			c.traceFrame(FrameReceived, nil, data)
+			continue
+		}
+		c.traceReceived(messages, data)
+		for _, msg := range messages {
+			c.handleMessage(msg)
 		}
-		line := scanner.Text()
-		// Process the response - for now just log it
-		fmt.Printf("Received: %s\n", line)
 	}
 }
 
-// Call makes an RPC call to the MCP server
-func (c *MCPClient) Call(method string, params interface{}) (*MCPResponse, error) {
-	if !c.connected {
-		return nil, fmt.Errorf("not connected to server")
+// shutdown marks the session closed and fails every call still waiting for a response
+func (c *MCPClient) shutdown() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.connected = false
+	c.closed = true
+	for id, ch := range c.pending {
+		close(ch)
+		delete(c.pending, id)
 	}
+	// Requests in flight will never be answered
+	clear(c.traced)
+	close(c.done)
+}
 
-	request := MCPRequest{
-		Method:  method,
-		ID:      generateID(),
-		Version: "1.0",
-		Params:  params,
+// handleMessage processes a single frame received from the server
+func (c *MCPClient) handleMessage(msg Message) {
+	switch m := msg.(type) {
+	case *MCPResponse:
+		c.mu.Lock()
+		ch, ok := c.pending[m.ID]
+		delete(c.pending, m.ID)
+		c.mu.Unlock()
+		if ok {
+			ch <- m
+		}
+	case *MCPRequest:
+		c.handleRequest(m)
+	case *MCPNotification:
+		c.mu.Lock()
+		handlers := append([]NotificationHandler(nil), c.handlers[m.Method]...)
+		c.mu.Unlock()
+		for _, handler := range handlers {
+			handler(m)
+		}
 	}
+}
+
+// OnNotification registers a handler for notifications with the given method.
+// Handlers run on the client's read goroutine and must not block.
+func (c *MCPClient) OnNotification(method string, handler NotificationHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.handlers[method] = append(c.handlers[method], handler)
+}
+
+// handleRequest answers server-initiated requests
+func (c *MCPClient) handleRequest(req *MCPRequest) {
+	var resp *MCPResponse
+	switch req.Method {
+	case "ping":
+		resp, _ = NewResultResponse(req.ID, nil)
+	default:
+		resp = NewErrorResponse(req.ID, ErrCodeMethodNotFound, fmt.Sprintf("method not found: %s", req.Method))
+	}
+	c.writeMessage(c.ctx, resp)
+}
+
+// Call makes an RPC call to the MCP server and waits for its response.
+// If the server answers with an error object, the response is returned
+// together with the *MCPError as err.
+func (c *MCPClient) Call(ctx context.Context, method string, params interface{}) (*MCPResponse, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+	start := time.Now()
+	resp, err := c.call(ctx, method, params)
+
+	latency := time.Since(start)
+	c.mu.Lock()
+	observers := c.observers
+	c.mu.Unlock()
+	for _, observer := range observers {
+		observer(method, latency, err)
+	}
+	return resp, err
+}
 
-	requestBytes, err := json.Marshal(request)
+// OnCall registers an observer of completed calls. Observers run on the
+// calling goroutine and must not block.
+func (c *MCPClient) OnCall(observer CallObserver) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.observers = append(c.observers, observer)
+}
+
+// CallResult makes an RPC call and decodes a successful result into result
+func (c *MCPClient) CallResult(ctx context.Context, method string, params interface{}, result interface{}) error {
+	resp, err := c.Call(ctx, method, params)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		return err
+	}
+	if result == nil || len(resp.Result) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Result, result); err != nil {
+		return fmt.Errorf("failed to decode %s result: %w", method, err)
+	}
+	return nil
+}
+
+// call sends a request and waits for the matching response, without
+// requiring the handshake to have completed
+func (c *MCPClient) call(ctx context.Context, method string, params interface{}) (*MCPResponse, error) {
+	if _, ok := ctx.Deadline(); !ok && c.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.RequestTimeout)
+		defer cancel()
+	}
+
+	request, err := NewRequest(NewNumberID(atomic.AddInt64(&c.nextID, 1)), method, params)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan *MCPResponse, 1)
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, ErrConnectionClosed
+	}
+	c.pending[request.ID] = ch
+	c.mu.Unlock()
+
+	if err := c.writeMessage(ctx, request); err != nil {
+		c.forget(request.ID)
+		return nil, err
 	}
 
-	// Add newline as MCP typically uses newline-delimited JSON
-	requestBytes = append(requestBytes, '\n')
+	select {
+	case resp, ok := <-ch:
+		if !ok {
+			return nil, ErrConnectionClosed
+		}
+		if resp.Error != nil {
+			return resp, resp.Error
+		}
+		return resp, nil
+	case <-ctx.Done():
+		c.forget(request.ID)
+		c.notify("notifications/cancelled", CancelledParams{RequestID: request.ID, Reason: ctx.Err().Error()})
+		return nil, fmt.Errorf("%s: %w", method, ctx.Err())
+	case <-c.ctx.Done():
+		c.forget(request.ID)
+		return nil, ErrConnectionClosed
+	}
+}
+
+// forget removes a request from the pending table
+func (c *MCPClient) forget(id RequestID) {
+	c.mu.Lock()
+	delete(c.pending, id)
+	delete(c.traced, id)
+	c.mu.Unlock()
+}
+
+// Notify sends a notification to the MCP server; no response is expected
+func (c *MCPClient) Notify(method string, params interface{}) error {
+	if !c.IsConnected() {
+		return ErrNotConnected
+	}
+	return c.notify(method, params)
+}
 
-	_, err = c.stdin.Write(requestBytes)
+// notify sends a notification without requiring the handshake to have completed
+func (c *MCPClient) notify(method string, params interface{}) error {
+	notification, err := NewNotification(method, params)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return err
 	}
+	return c.writeMessage(c.ctx, notification)
+}
 
-	// For now, we're not handling the response properly, just returning a placeholder
-	// In a real implementation, we would need to properly handle async responses
-	return &MCPResponse{
-		ID: request.ID,
-	}, nil
+// writeMessage serializes a frame and hands it to the transport
+func (c *MCPClient) writeMessage(ctx context.Context, msg Message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+	// Traced before sending so that a fast response finds its request
+	c.traceFrame(FrameSent, msg, data)
+	return c.transport.Send(ctx, data)
 }
 
 // parseCommand splits a command string into parts
@@ -191,12 +424,39 @@ func parseCommand(command string) []string {
 	return parts
 }
 
-// generateID creates a unique ID for requests
-func generateID() string {
-	return fmt.Sprintf("req_%d", time.Now().UnixNano())
-}
-
 // IsConnected returns whether the client is connected
 func (c *MCPClient) IsConnected() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	return c.connected
-}
\ No newline at end of file
+}
+
+// Transport returns the transport used by the client
+func (c *MCPClient) Transport() Transport {
+	return c.transport
+}
+
+// Done returns a channel that is closed when the session with the server ends
+func (c *MCPClient) Done() <-chan struct{} {
+	return c.done
+}
+
+// ServerInfo returns the name and version reported by the server during initialization
+func (c *MCPClient) ServerInfo() Implementation {
+	return c.initResult.ServerInfo
+}
+
+// ServerCapabilities returns the capabilities advertised by the server
+func (c *MCPClient) ServerCapabilities() ServerCapabilities {
+	return c.initResult.Capabilities
+}
+
+// ProtocolVersion returns the negotiated MCP protocol revision
+func (c *MCPClient) ProtocolVersion() string {
+	return c.initResult.ProtocolVersion
+}
+
+// Instructions returns the optional usage hints provided by the server
+func (c *MCPClient) Instructions() string {
+	return c.initResult.Instructions
+}
@@ -0,0 +1,30 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// Content is a single content block in prompt messages and tool results.
+// Type is one of "text", "image", "audio", "resource" or "resource_link".
+type Content struct {
+	Type        string            `json:"type"`
+	Text        string            `json:"text,omitempty"`
+	Data        string            `json:"data,omitempty"`
+	MimeType    string            `json:"mimeType,omitempty"`
+	Resource    *ResourceContents `json:"resource,omitempty"`
+	URI         string            `json:"uri,omitempty"`
+	Name        string            `json:"name,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Annotations *Annotations      `json:"annotations,omitempty"`
+	Meta        json.RawMessage   `json:"_meta,omitempty"`
+}
+
+// DataSize returns the decoded size in bytes of image and audio data
+func (c Content) DataSize() int {
+	data, err := base64.StdEncoding.DecodeString(c.Data)
+	if err != nil {
+		return base64.StdEncoding.DecodedLen(len(c.Data))
+	}
+	return len(data)
+}
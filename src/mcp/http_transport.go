@@ -0,0 +1,395 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Header names used by the Streamable HTTP transport
+const (
+	SessionIDHeader       = "Mcp-Session-Id"
+	ProtocolVersionHeader = "MCP-Protocol-Version"
+)
+
+// ErrSessionExpired is returned when a Streamable HTTP server no longer recognizes the session
+var ErrSessionExpired = errors.New("server session expired")
+
+// SSETransport implements the legacy HTTP+SSE transport (protocol 2024-11-05):
+// frames arrive on a long-lived event stream and are sent by POSTing to the
+// endpoint announced in the stream's first "endpoint" event
+type SSETransport struct {
+	URL     string
+	Headers http.Header
+	Client  *http.Client
+
+	ctx      context.Context
+	cancel   context.CancelFunc
+	inbox    *inbox
+	endpoint string
+}
+
+// NewSSETransport creates a legacy HTTP+SSE transport for the given stream URL
+func NewSSETransport(streamURL string) *SSETransport {
+	return &SSETransport{
+		URL:     streamURL,
+		Headers: make(http.Header),
+		Client:  &http.Client{},
+		inbox:   newInbox(),
+	}
+}
+
+// Start opens the event stream and waits for the server to announce its endpoint
+func (t *SSETransport) Start(ctx context.Context) error {
+	t.ctx, t.cancel = context.WithCancel(ctx)
+
+	req, err := http.NewRequestWithContext(t.ctx, http.MethodGet, t.URL, nil)
+	if err != nil {
+		t.cancel()
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	copyHeaders(req.Header, t.Headers)
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := t.Client.Do(req)
+	if err != nil {
+		t.cancel()
+		return fmt.Errorf("failed to open event stream: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		t.cancel()
+		return fmt.Errorf("failed to open event stream: server returned %s", resp.Status)
+	}
+
+	ready := make(chan string, 1)
+	go func() {
+		defer resp.Body.Close()
+		err := readSSE(resp.Body, func(ev sseEvent) bool {
+			switch ev.Event {
+			case "endpoint":
+				select {
+				case ready <- ev.Data:
+				default:
+				}
+			case "", "message":
+				return t.inbox.deliver([]byte(ev.Data))
+			}
+			return true
+		})
+		t.inbox.close(err)
+	}()
+
+	select {
+	case endpoint := <-ready:
+		resolved, err := resolveEndpoint(t.URL, endpoint)
+		if err != nil {
+			t.Close()
+			return err
+		}
+		t.endpoint = resolved
+		return nil
+	case <-t.inbox.done:
+		t.Close()
+		return fmt.Errorf("event stream closed before the server announced its endpoint")
+	case <-time.After(initializeTimeout):
+		t.Close()
+		return fmt.Errorf("timed out waiting for the server to announce its endpoint")
+	}
+}
+
+// Send POSTs a frame to the announced endpoint; replies arrive on the event stream
+func (t *SSETransport) Send(ctx context.Context, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	copyHeaders(req.Header, t.Headers)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return httpStatusError(resp)
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// Receive returns the next frame from the event stream
+func (t *SSETransport) Receive() ([]byte, error) {
+	return t.inbox.receive()
+}
+
+// Close terminates the event stream
+func (t *SSETransport) Close() error {
+	if t.cancel != nil {
+		t.cancel()
+	}
+	t.inbox.close(nil)
+	return nil
+}
+
+// StreamableHTTPTransport implements the Streamable HTTP transport
+// (protocol 2025-03-26 and later): every frame is POSTed to a single endpoint
+// and replies come back either as a JSON body or as an SSE stream
+type StreamableHTTPTransport struct {
+	URL     string
+	Headers http.Header
+	Client  *http.Client
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	inbox  *inbox
+
+	mu              sync.Mutex
+	sessionID       string
+	protocolVersion string
+}
+
+// NewStreamableHTTPTransport creates a Streamable HTTP transport for the given endpoint
+func NewStreamableHTTPTransport(endpoint string) *StreamableHTTPTransport {
+	return &StreamableHTTPTransport{
+		URL:     endpoint,
+		Headers: make(http.Header),
+		Client:  &http.Client{},
+		inbox:   newInbox(),
+	}
+}
+
+// Start prepares the transport; no request is made until the first Send
+func (t *StreamableHTTPTransport) Start(ctx context.Context) error {
+	t.ctx, t.cancel = context.WithCancel(ctx)
+	return nil
+}
+
+// Send POSTs a frame and queues any frames returned in the response
+func (t *StreamableHTTPTransport) Send(ctx context.Context, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.URL, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	t.setSessionHeaders(req)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+
+	resp, err := t.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	if id := resp.Header.Get(SessionIDHeader); id != "" {
+		t.mu.Lock()
+		t.sessionID = id
+		t.mu.Unlock()
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusAccepted:
+		resp.Body.Close()
+		return nil
+	case resp.StatusCode == http.StatusNotFound && t.SessionID() != "":
+		resp.Body.Close()
+		t.inbox.close(ErrSessionExpired)
+		return ErrSessionExpired
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		defer resp.Body.Close()
+		return httpStatusError(resp)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch mediaType {
+	case "text/event-stream":
+		go t.consumeStream(resp.Body)
+	case "application/json":
+		go func() {
+			defer resp.Body.Close()
+			body, err := io.ReadAll(io.LimitReader(resp.Body, maxMessageSize))
+			if err == nil && len(bytes.TrimSpace(body)) > 0 {
+				t.inbox.deliver(body)
+			}
+		}()
+	default:
+		resp.Body.Close()
+	}
+	return nil
+}
+
+// Receive returns the next frame received from the server
+func (t *StreamableHTTPTransport) Receive() ([]byte, error) {
+	return t.inbox.receive()
+}
+
+// Close terminates the session on the server, if one was established
+func (t *StreamableHTTPTransport) Close() error {
+	if sessionID := t.SessionID(); sessionID != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if req, err := http.NewRequestWithContext(ctx, http.MethodDelete, t.URL, nil); err == nil {
+			t.setSessionHeaders(req)
+			if resp, err := t.Client.Do(req); err == nil {
+				resp.Body.Close()
+			}
+		}
+	}
+	if t.cancel != nil {
+		t.cancel()
+	}
+	t.inbox.close(nil)
+	return nil
+}
+
+// SessionID returns the session assigned by the server, if any
+func (t *StreamableHTTPTransport) SessionID() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.sessionID
+}
+
+func (t *StreamableHTTPTransport) setProtocolVersion(version string) {
+	t.mu.Lock()
+	t.protocolVersion = version
+	t.mu.Unlock()
+}
+
+// listen opens the optional GET stream used for server-initiated messages.
+// Servers that do not offer one answer 405, which is not an error.
+func (t *StreamableHTTPTransport) listen() {
+	req, err := http.NewRequestWithContext(t.ctx, http.MethodGet, t.URL, nil)
+	if err != nil {
+		return
+	}
+	t.setSessionHeaders(req)
+	req.Header.Set("Accept", "text/event-stream")
+
+	go func() {
+		resp, err := t.Client.Do(req)
+		if err != nil {
+			return
+		}
+		mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+		if resp.StatusCode != http.StatusOK || mediaType != "text/event-stream" {
+			resp.Body.Close()
+			return
+		}
+		t.consumeStream(resp.Body)
+	}()
+}
+
+// consumeStream queues every message event from an SSE response body
+func (t *StreamableHTTPTransport) consumeStream(body io.ReadCloser) {
+	defer body.Close()
+	readSSE(body, func(ev sseEvent) bool {
+		if ev.Event != "" && ev.Event != "message" {
+			return true
+		}
+		return t.inbox.deliver([]byte(ev.Data))
+	})
+}
+
+// setSessionHeaders adds the configured headers plus session state to a request
+func (t *StreamableHTTPTransport) setSessionHeaders(req *http.Request) {
+	copyHeaders(req.Header, t.Headers)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.sessionID != "" {
+		req.Header.Set(SessionIDHeader, t.sessionID)
+	}
+	if t.protocolVersion != "" {
+		req.Header.Set(ProtocolVersionHeader, t.protocolVersion)
+	}
+}
+
+// sseEvent is a single Server-Sent Events message
+type sseEvent struct {
+	Event string
+	Data  string
+	ID    string
+}
+
+// readSSE parses an event stream, calling handle for each complete event
+// until the stream ends or handle returns false
+func readSSE(r io.Reader, handle func(sseEvent) bool) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+
+	var event sseEvent
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if len(data) > 0 {
+				event.Data = strings.Join(data, "\n")
+				if !handle(event) {
+					return nil
+				}
+			}
+			event = sseEvent{}
+			data = nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue // comment / keep-alive
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event.Event = value
+		case "data":
+			data = append(data, value)
+		case "id":
+			event.ID = value
+		}
+	}
+	return scanner.Err()
+}
+
+// resolveEndpoint resolves the endpoint announced by an SSE server against the stream URL
+func resolveEndpoint(base, endpoint string) (string, error) {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("invalid server URL %s: %w", base, err)
+	}
+	ref, err := url.Parse(strings.TrimSpace(endpoint))
+	if err != nil {
+		return "", fmt.Errorf("server announced an invalid endpoint %q: %w", endpoint, err)
+	}
+	resolved := baseURL.ResolveReference(ref)
+	if resolved.Host != baseURL.Host {
+		return "", fmt.Errorf("server announced an endpoint on a different origin: %s", resolved)
+	}
+	return resolved.String(), nil
+}
+
+// copyHeaders copies every header value from src into dst
+func copyHeaders(dst, src http.Header) {
+	for name, values := range src {
+		for _, value := range values {
+			dst.Add(name, value)
+		}
+	}
+}
+
+// httpStatusError builds an error from an unsuccessful HTTP response
+func httpStatusError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if msg := strings.TrimSpace(string(body)); msg != "" {
+		return fmt.Errorf("server returned %s: %s", resp.Status, msg)
+	}
+	return fmt.Errorf("server returned %s", resp.Status)
+}
@@ -0,0 +1,134 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// LatestProtocolVersion is the MCP revision mcop requests during initialization
+const LatestProtocolVersion = "2025-06-18"
+
+// SupportedProtocolVersions lists every MCP revision mcop can speak, newest first
+var SupportedProtocolVersions = []string{
+	"2025-06-18",
+	"2025-03-26",
+	"2024-11-05",
+}
+
+// ClientInfo identifies mcop to the servers it connects to
+var ClientInfo = Implementation{
+	Name:    "mcop",
+	Title:   "MCOP - MCP Operations Monitor",
+	Version: "1.0.0",
+}
+
+// initializeTimeout bounds how long Connect waits for the initialize response
+const initializeTimeout = 30 * time.Second
+
+// Implementation describes the name and version of an MCP client or server
+type Implementation struct {
+	Name    string `json:"name"`
+	Title   string `json:"title,omitempty"`
+	Version string `json:"version"`
+}
+
+// ClientCapabilities are the optional features mcop offers to servers
+type ClientCapabilities struct {
+	Roots        *RootsCapability           `json:"roots,omitempty"`
+	Sampling     *struct{}                  `json:"sampling,omitempty"`
+	Elicitation  *struct{}                  `json:"elicitation,omitempty"`
+	Experimental map[string]json.RawMessage `json:"experimental,omitempty"`
+}
+
+// RootsCapability describes the client's support for filesystem roots
+type RootsCapability struct {
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+// ServerCapabilities are the optional features advertised by a server
+type ServerCapabilities struct {
+	Tools        *ToolsCapability           `json:"tools,omitempty"`
+	Resources    *ResourcesCapability       `json:"resources,omitempty"`
+	Prompts      *PromptsCapability         `json:"prompts,omitempty"`
+	Logging      *struct{}                  `json:"logging,omitempty"`
+	Completions  *struct{}                  `json:"completions,omitempty"`
+	Experimental map[string]json.RawMessage `json:"experimental,omitempty"`
+}
+
+// ToolsCapability describes the server's tool support
+type ToolsCapability struct {
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+// ResourcesCapability describes the server's resource support
+type ResourcesCapability struct {
+	Subscribe   bool `json:"subscribe,omitempty"`
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+// PromptsCapability describes the server's prompt support
+type PromptsCapability struct {
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+// InitializeParams are sent by the client in the initialize request
+type InitializeParams struct {
+	ProtocolVersion string             `json:"protocolVersion"`
+	Capabilities    ClientCapabilities `json:"capabilities"`
+	ClientInfo      Implementation     `json:"clientInfo"`
+}
+
+// InitializeResult is the server's reply to the initialize request
+type InitializeResult struct {
+	ProtocolVersion string             `json:"protocolVersion"`
+	Capabilities    ServerCapabilities `json:"capabilities"`
+	ServerInfo      Implementation     `json:"serverInfo"`
+	Instructions    string             `json:"instructions,omitempty"`
+}
+
+// Names returns the names of the advertised capabilities in a stable order
+func (c ServerCapabilities) Names() []string {
+	var names []string
+	if c.Tools != nil {
+		names = append(names, "tools")
+	}
+	if c.Resources != nil {
+		names = append(names, "resources")
+	}
+	if c.Prompts != nil {
+		names = append(names, "prompts")
+	}
+	if c.Logging != nil {
+		names = append(names, "logging")
+	}
+	if c.Completions != nil {
+		names = append(names, "completions")
+	}
+	var experimental []string
+	for name := range c.Experimental {
+		experimental = append(experimental, "experimental:"+name)
+	}
+	sort.Strings(experimental)
+	return append(names, experimental...)
+}
+
+// isSupportedProtocolVersion reports whether mcop can speak the given revision
+func isSupportedProtocolVersion(version string) bool {
+	for _, supported := range SupportedProtocolVersions {
+		if version == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// Ping sends a ping request and returns the round-trip time of its response
+func (c *MCPClient) Ping(ctx context.Context) (time.Duration, error) {
+	start := time.Now()
+	if err := c.CallResult(ctx, "ping", nil, nil); err != nil {
+		return 0, err
+	}
+	return time.Since(start), nil
+}
@@ -0,0 +1,32 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// LoggingMessageParams are the parameters of notifications/message
+type LoggingMessageParams struct {
+	Level  string          `json:"level"`
+	Logger string          `json:"logger,omitempty"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// Text returns the log data as a single line: strings as is, anything else as compact JSON
+func (p *LoggingMessageParams) Text() string {
+	var text string
+	if err := json.Unmarshal(p.Data, &text); err == nil {
+		return text
+	}
+	return string(p.Data)
+}
+
+// SetLevelParams are the parameters of logging/setLevel
+type SetLevelParams struct {
+	Level string `json:"level"`
+}
+
+// SetLoggingLevel asks the server to send log notifications at level and above
+func (c *MCPClient) SetLoggingLevel(ctx context.Context, level string) error {
+	return c.CallResult(ctx, "logging/setLevel", SetLevelParams{Level: level}, nil)
+}
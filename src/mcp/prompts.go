@@ -0,0 +1,86 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Prompt describes a prompt template exposed by a server, as returned by prompts/list
+type Prompt struct {
+	Name        string           `json:"name"`
+	Title       string           `json:"title,omitempty"`
+	Description string           `json:"description,omitempty"`
+	Arguments   []PromptArgument `json:"arguments,omitempty"`
+}
+
+// PromptArgument describes an argument accepted by a prompt
+type PromptArgument struct {
+	Name        string `json:"name"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// PromptMessage is a single message of a rendered prompt
+type PromptMessage struct {
+	Role    string  `json:"role"`
+	Content Content `json:"content"`
+}
+
+// ListPromptsResult is the server's reply to prompts/list
+type ListPromptsResult struct {
+	Prompts    []Prompt `json:"prompts"`
+	NextCursor string   `json:"nextCursor,omitempty"`
+}
+
+// GetPromptParams are sent with prompts/get
+type GetPromptParams struct {
+	Name      string            `json:"name"`
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+// GetPromptResult is the server's reply to prompts/get
+type GetPromptResult struct {
+	Description string          `json:"description,omitempty"`
+	Messages    []PromptMessage `json:"messages"`
+}
+
+// DisplayName returns the human readable name of the prompt
+func (p Prompt) DisplayName() string {
+	if p.Title != "" {
+		return p.Title
+	}
+	return p.Name
+}
+
+// DisplayName returns the human readable name of the argument
+func (a PromptArgument) DisplayName() string {
+	if a.Title != "" {
+		return a.Title
+	}
+	return a.Name
+}
+
+// ListPrompts fetches every prompt exposed by the server, following pagination cursors
+func (c *MCPClient) ListPrompts(ctx context.Context) ([]Prompt, error) {
+	var prompts []Prompt
+	err := c.paginate(ctx, "prompts/list", func(raw json.RawMessage) (string, error) {
+		var page ListPromptsResult
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return "", err
+		}
+		prompts = append(prompts, page.Prompts...)
+		return page.NextCursor, nil
+	})
+	return prompts, err
+}
+
+// GetPrompt renders the named prompt with the given arguments
+func (c *MCPClient) GetPrompt(ctx context.Context, name string, arguments map[string]string) (*GetPromptResult, error) {
+	var result GetPromptResult
+	params := GetPromptParams{Name: name, Arguments: arguments}
+	if err := c.CallResult(ctx, "prompts/get", params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,283 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// JSONRPCVersion is the JSON-RPC version spoken by every MCP frame
+const JSONRPCVersion = "2.0"
+
+// Standard JSON-RPC 2.0 error codes
+const (
+	ErrCodeParseError     = -32700
+	ErrCodeInvalidRequest = -32600
+	ErrCodeMethodNotFound = -32601
+	ErrCodeInvalidParams  = -32602
+	ErrCodeInternalError  = -32603
+)
+
+// Message is implemented by every JSON-RPC frame type
+type Message interface {
+	isMessage()
+}
+
+// idKind distinguishes the JSON types a request ID can take
+type idKind uint8
+
+const (
+	idNull idKind = iota
+	idNumber
+	idString
+)
+
+// RequestID is a JSON-RPC request identifier, which may be a number or a string.
+// The zero value is the JSON null ID used in responses to unparseable requests.
+// RequestID is comparable and can be used as a map key.
+type RequestID struct {
+	kind  idKind
+	value string
+}
+
+// NewNumberID creates a numeric request ID
+func NewNumberID(n int64) RequestID {
+	return RequestID{kind: idNumber, value: strconv.FormatInt(n, 10)}
+}
+
+// NewStringID creates a string request ID
+func NewStringID(s string) RequestID {
+	return RequestID{kind: idString, value: s}
+}
+
+// IsNull reports whether the ID is the JSON null ID
+func (id RequestID) IsNull() bool {
+	return id.kind == idNull
+}
+
+// String returns a human readable form of the ID
+func (id RequestID) String() string {
+	switch id.kind {
+	case idNumber:
+		return id.value
+	case idString:
+		return strconv.Quote(id.value)
+	default:
+		return "null"
+	}
+}
+
+// MarshalJSON encodes the ID as a JSON number, string or null
+func (id RequestID) MarshalJSON() ([]byte, error) {
+	switch id.kind {
+	case idNumber:
+		return []byte(id.value), nil
+	case idString:
+		return json.Marshal(id.value)
+	default:
+		return []byte("null"), nil
+	}
+}
+
+// UnmarshalJSON decodes a JSON number, string or null into the ID
+func (id *RequestID) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	switch {
+	case bytes.Equal(data, []byte("null")):
+		*id = RequestID{}
+	case len(data) > 0 && data[0] == '"':
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*id = NewStringID(s)
+	default:
+		var n json.Number
+		if err := json.Unmarshal(data, &n); err != nil {
+			return fmt.Errorf("invalid request id %s", data)
+		}
+		i, err := n.Int64()
+		if err != nil {
+			return fmt.Errorf("request id must be an integer: %s", data)
+		}
+		*id = NewNumberID(i)
+	}
+	return nil
+}
+
+// MCPRequest represents a JSON-RPC request that expects a response
+type MCPRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      RequestID       `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// MCPNotification represents a JSON-RPC notification, which carries no ID
+// and never receives a response
+type MCPNotification struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// MCPResponse represents a JSON-RPC response to a request
+type MCPResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      RequestID       `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *MCPError       `json:"error,omitempty"`
+}
+
+// MCPError represents a JSON-RPC error object
+type MCPError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (*MCPRequest) isMessage()      {}
+func (*MCPNotification) isMessage() {}
+func (*MCPResponse) isMessage()     {}
+
+// Error implements the error interface
+func (e *MCPError) Error() string {
+	if len(e.Data) > 0 {
+		return fmt.Sprintf("mcp error %d: %s (%s)", e.Code, e.Message, e.Data)
+	}
+	return fmt.Sprintf("mcp error %d: %s", e.Code, e.Message)
+}
+
+// CancelledParams are sent with notifications/cancelled when a caller gives up on a request
+type CancelledParams struct {
+	RequestID RequestID `json:"requestId"`
+	Reason    string    `json:"reason,omitempty"`
+}
+
+// NewRequest builds a request frame, encoding params if present
+func NewRequest(id RequestID, method string, params interface{}) (*MCPRequest, error) {
+	raw, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+	return &MCPRequest{JSONRPC: JSONRPCVersion, ID: id, Method: method, Params: raw}, nil
+}
+
+// NewNotification builds a notification frame, encoding params if present
+func NewNotification(method string, params interface{}) (*MCPNotification, error) {
+	raw, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+	return &MCPNotification{JSONRPC: JSONRPCVersion, Method: method, Params: raw}, nil
+}
+
+// NewResultResponse builds a successful response frame
+func NewResultResponse(id RequestID, result interface{}) (*MCPResponse, error) {
+	if result == nil {
+		result = struct{}{}
+	}
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return &MCPResponse{JSONRPC: JSONRPCVersion, ID: id, Result: raw}, nil
+}
+
+// NewErrorResponse builds an error response frame
+func NewErrorResponse(id RequestID, code int, message string) *MCPResponse {
+	return &MCPResponse{
+		JSONRPC: JSONRPCVersion,
+		ID:      id,
+		Error:   &MCPError{Code: code, Message: message},
+	}
+}
+
+// encodeParams marshals params unless they are nil
+func encodeParams(params interface{}) (json.RawMessage, error) {
+	if params == nil {
+		return nil, nil
+	}
+	if raw, ok := params.(json.RawMessage); ok {
+		return raw, nil
+	}
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal params: %w", err)
+	}
+	return raw, nil
+}
+
+// wireMessage is the union of all frame fields used while decoding
+type wireMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+	Result  json.RawMessage `json:"result"`
+	Error   *MCPError       `json:"error"`
+}
+
+// ParseMessages decodes a single frame or a batch of frames into typed messages
+func ParseMessages(data []byte) ([]Message, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty message")
+	}
+
+	if data[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(data, &batch); err != nil {
+			return nil, fmt.Errorf("failed to parse batch: %w", err)
+		}
+		messages := make([]Message, 0, len(batch))
+		for _, item := range batch {
+			msg, err := ParseMessage(item)
+			if err != nil {
+				return nil, err
+			}
+			messages = append(messages, msg)
+		}
+		return messages, nil
+	}
+
+	msg, err := ParseMessage(data)
+	if err != nil {
+		return nil, err
+	}
+	return []Message{msg}, nil
+}
+
+// ParseMessage decodes a single frame into an *MCPRequest, *MCPNotification or *MCPResponse
+func ParseMessage(data []byte) (Message, error) {
+	var wire wireMessage
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return nil, fmt.Errorf("failed to parse message: %w", err)
+	}
+	if wire.JSONRPC != JSONRPCVersion {
+		return nil, fmt.Errorf("unsupported jsonrpc version %q", wire.JSONRPC)
+	}
+
+	hasID := len(wire.ID) > 0
+	var id RequestID
+	if hasID {
+		if err := id.UnmarshalJSON(wire.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case wire.Method != "" && hasID:
+		return &MCPRequest{JSONRPC: wire.JSONRPC, ID: id, Method: wire.Method, Params: wire.Params}, nil
+	case wire.Method != "":
+		return &MCPNotification{JSONRPC: wire.JSONRPC, Method: wire.Method, Params: wire.Params}, nil
+	case wire.Result != nil || wire.Error != nil:
+		if !hasID {
+			return nil, fmt.Errorf("response is missing an id")
+		}
+		return &MCPResponse{JSONRPC: wire.JSONRPC, ID: id, Result: wire.Result, Error: wire.Error}, nil
+	default:
+		return nil, fmt.Errorf("message is neither a request, notification nor response")
+	}
+}
@@ -0,0 +1,243 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// replayEvent is a frame the server sent during an exchange
+type replayEvent struct {
+	delay time.Duration // since the request that started the exchange
+	data  []byte
+	// response is set for the response to the exchange's request, whose ID
+	// is rewritten to the ID of the replayed request
+	response bool
+}
+
+// replayExchange is a request recorded in a session and everything the
+// server sent from that request until the next one
+type replayExchange struct {
+	method string
+	params string // canonical JSON
+	events []replayEvent
+	used   bool
+}
+
+// ReplayTransport plays the server side of a recorded session. Each request
+// is answered with the recorded response to the same method and params;
+// notifications the server sent during that exchange follow it.
+type ReplayTransport struct {
+	// Realtime delays frames by the time they took in the recording
+	Realtime bool
+	// Loose answers requests whose params match no recording with the next
+	// unused response to the same method
+	Loose bool
+
+	mu        sync.Mutex
+	exchanges []*replayExchange
+	preamble  []replayEvent
+	queue     [][]byte
+	ready     chan struct{}
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// NewReplayTransport creates a transport replaying the server side of frames
+func NewReplayTransport(frames []SessionFrame) *ReplayTransport {
+	t := &ReplayTransport{
+		ready:  make(chan struct{}, 1),
+		closed: make(chan struct{}),
+	}
+
+	byID := make(map[RequestID]*replayExchange)
+	var current *replayExchange
+	var currentOffset time.Duration
+	for _, frame := range frames {
+		messages, err := ParseMessages(frame.Message)
+		if err != nil {
+			continue
+		}
+		for _, msg := range messages {
+			if frame.Direction == FrameSent {
+				req, ok := msg.(*MCPRequest)
+				if !ok {
+					continue
+				}
+				current = &replayExchange{method: req.Method, params: canonicalJSON(req.Params)}
+				currentOffset = frame.Offset()
+				t.exchanges = append(t.exchanges, current)
+				byID[req.ID] = current
+				continue
+			}
+
+			data, err := json.Marshal(msg)
+			if err != nil {
+				continue
+			}
+			if resp, ok := msg.(*MCPResponse); ok {
+				if exchange, ok := byID[resp.ID]; ok {
+					delete(byID, resp.ID)
+					exchange.events = append(exchange.events, replayEvent{
+						delay:    frame.Offset() - currentOffset,
+						data:     data,
+						response: true,
+					})
+				}
+				continue
+			}
+			event := replayEvent{delay: frame.Offset() - currentOffset, data: data}
+			if current == nil {
+				event.delay = frame.Offset()
+				t.preamble = append(t.preamble, event)
+			} else {
+				current.events = append(current.events, event)
+			}
+		}
+	}
+	return t
+}
+
+// canonicalJSON re-encodes JSON so that equal values compare equal
+func canonicalJSON(data json.RawMessage) string {
+	var value interface{}
+	if len(data) == 0 || json.Unmarshal(data, &value) != nil {
+		return string(data)
+	}
+	canonical, _ := json.Marshal(value)
+	return string(canonical)
+}
+
+// Start replays the frames the server sent before the first request
+func (t *ReplayTransport) Start(ctx context.Context) error {
+	t.play(t.preamble, RequestID{})
+	return nil
+}
+
+// Send answers the requests in data from the recording
+func (t *ReplayTransport) Send(ctx context.Context, data []byte) error {
+	select {
+	case <-t.closed:
+		return fmt.Errorf("transport closed")
+	default:
+	}
+
+	messages, err := ParseMessages(data)
+	if err != nil {
+		return nil
+	}
+	for _, msg := range messages {
+		req, ok := msg.(*MCPRequest)
+		if !ok {
+			continue
+		}
+		exchange := t.match(req)
+		if exchange == nil {
+			resp := NewErrorResponse(req.ID, ErrCodeMethodNotFound, fmt.Sprintf("no recorded response for %s", req.Method))
+			if encoded, err := json.Marshal(resp); err == nil {
+				t.push(encoded)
+			}
+			continue
+		}
+		t.play(exchange.events, req.ID)
+	}
+	return nil
+}
+
+// match claims the recorded exchange answering req
+func (t *ReplayTransport) match(req *MCPRequest) *replayExchange {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	params := canonicalJSON(req.Params)
+	var fallback *replayExchange
+	for _, exchange := range t.exchanges {
+		if exchange.used || exchange.method != req.Method {
+			continue
+		}
+		if exchange.params == params {
+			exchange.used = true
+			return exchange
+		}
+		if fallback == nil && t.Loose {
+			fallback = exchange
+		}
+	}
+	if fallback != nil {
+		fallback.used = true
+	}
+	return fallback
+}
+
+// play queues the events of an exchange, answering the request with ID id
+func (t *ReplayTransport) play(events []replayEvent, id RequestID) {
+	frames := make([][]byte, len(events))
+	for i, event := range events {
+		frames[i] = event.data
+		if event.response {
+			var resp MCPResponse
+			if err := json.Unmarshal(event.data, &resp); err == nil {
+				resp.ID = id
+				frames[i], _ = json.Marshal(resp)
+			}
+		}
+	}
+
+	if !t.Realtime {
+		for _, frame := range frames {
+			t.push(frame)
+		}
+		return
+	}
+	go func() {
+		start := time.Now()
+		for i, frame := range frames {
+			select {
+			case <-time.After(time.Until(start.Add(events[i].delay))):
+			case <-t.closed:
+				return
+			}
+			t.push(frame)
+		}
+	}()
+}
+
+// push queues a frame for Receive
+func (t *ReplayTransport) push(data []byte) {
+	t.mu.Lock()
+	t.queue = append(t.queue, data)
+	t.mu.Unlock()
+	select {
+	case t.ready <- struct{}{}:
+	default:
+	}
+}
+
+// Receive returns the next replayed frame
+func (t *ReplayTransport) Receive() ([]byte, error) {
+	for {
+		t.mu.Lock()
+		if len(t.queue) > 0 {
+			data := t.queue[0]
+			t.queue = t.queue[1:]
+			t.mu.Unlock()
+			return data, nil
+		}
+		t.mu.Unlock()
+
+		select {
+		case <-t.ready:
+		case <-t.closed:
+			return nil, io.EOF
+		}
+	}
+}
+
+// Close ends the replay
+func (t *ReplayTransport) Close() error {
+	t.closeOnce.Do(func() { close(t.closed) })
+	return nil
+}
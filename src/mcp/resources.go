@@ -0,0 +1,154 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+)
+
+// Resource describes a piece of context exposed by a server, as returned by resources/list
+type Resource struct {
+	URI         string       `json:"uri"`
+	Name        string       `json:"name"`
+	Title       string       `json:"title,omitempty"`
+	Description string       `json:"description,omitempty"`
+	MimeType    string       `json:"mimeType,omitempty"`
+	Size        *int64       `json:"size,omitempty"`
+	Annotations *Annotations `json:"annotations,omitempty"`
+}
+
+// ResourceTemplate describes a parameterized family of resources (RFC 6570 URI template)
+type ResourceTemplate struct {
+	URITemplate string       `json:"uriTemplate"`
+	Name        string       `json:"name"`
+	Title       string       `json:"title,omitempty"`
+	Description string       `json:"description,omitempty"`
+	MimeType    string       `json:"mimeType,omitempty"`
+	Annotations *Annotations `json:"annotations,omitempty"`
+}
+
+// ResourceContents holds the contents of a resource: Text for textual
+// resources, Blob (base64) for binary ones
+type ResourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Blob     string `json:"blob,omitempty"`
+}
+
+// Annotations are optional hints attached to resources and content
+type Annotations struct {
+	Audience     []string `json:"audience,omitempty"`
+	Priority     *float64 `json:"priority,omitempty"`
+	LastModified string   `json:"lastModified,omitempty"`
+}
+
+// ListResourcesResult is the server's reply to resources/list
+type ListResourcesResult struct {
+	Resources  []Resource `json:"resources"`
+	NextCursor string     `json:"nextCursor,omitempty"`
+}
+
+// ListResourceTemplatesResult is the server's reply to resources/templates/list
+type ListResourceTemplatesResult struct {
+	ResourceTemplates []ResourceTemplate `json:"resourceTemplates"`
+	NextCursor        string             `json:"nextCursor,omitempty"`
+}
+
+// ReadResourceResult is the server's reply to resources/read
+type ReadResourceResult struct {
+	Contents []ResourceContents `json:"contents"`
+}
+
+// ResourceParams identify a single resource in read and subscribe requests
+type ResourceParams struct {
+	URI string `json:"uri"`
+}
+
+// ResourceUpdatedParams accompany notifications/resources/updated
+type ResourceUpdatedParams struct {
+	URI string `json:"uri"`
+}
+
+// DisplayName returns the human readable name of the resource
+func (r Resource) DisplayName() string {
+	if r.Title != "" {
+		return r.Title
+	}
+	if r.Name != "" {
+		return r.Name
+	}
+	return r.URI
+}
+
+// DisplayName returns the human readable name of the template
+func (t ResourceTemplate) DisplayName() string {
+	if t.Title != "" {
+		return t.Title
+	}
+	if t.Name != "" {
+		return t.Name
+	}
+	return t.URITemplate
+}
+
+// IsBinary reports whether the contents are a base64 blob rather than text
+func (c ResourceContents) IsBinary() bool {
+	return c.Blob != ""
+}
+
+// BlobSize returns the decoded size of a binary resource in bytes
+func (c ResourceContents) BlobSize() int {
+	data, err := base64.StdEncoding.DecodeString(c.Blob)
+	if err != nil {
+		return base64.StdEncoding.DecodedLen(len(c.Blob))
+	}
+	return len(data)
+}
+
+// ListResources fetches every resource exposed by the server, following pagination cursors
+func (c *MCPClient) ListResources(ctx context.Context) ([]Resource, error) {
+	var resources []Resource
+	err := c.paginate(ctx, "resources/list", func(raw json.RawMessage) (string, error) {
+		var page ListResourcesResult
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return "", err
+		}
+		resources = append(resources, page.Resources...)
+		return page.NextCursor, nil
+	})
+	return resources, err
+}
+
+// ListResourceTemplates fetches every resource template exposed by the server
+func (c *MCPClient) ListResourceTemplates(ctx context.Context) ([]ResourceTemplate, error) {
+	var templates []ResourceTemplate
+	err := c.paginate(ctx, "resources/templates/list", func(raw json.RawMessage) (string, error) {
+		var page ListResourceTemplatesResult
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return "", err
+		}
+		templates = append(templates, page.ResourceTemplates...)
+		return page.NextCursor, nil
+	})
+	return templates, err
+}
+
+// ReadResource fetches the contents of the resource identified by uri
+func (c *MCPClient) ReadResource(ctx context.Context, uri string) ([]ResourceContents, error) {
+	var result ReadResourceResult
+	if err := c.CallResult(ctx, "resources/read", ResourceParams{URI: uri}, &result); err != nil {
+		return nil, err
+	}
+	return result.Contents, nil
+}
+
+// SubscribeResource asks the server to send notifications/resources/updated when uri changes
+func (c *MCPClient) SubscribeResource(ctx context.Context, uri string) error {
+	return c.CallResult(ctx, "resources/subscribe", ResourceParams{URI: uri}, nil)
+}
+
+// UnsubscribeResource cancels a previous SubscribeResource
+func (c *MCPClient) UnsubscribeResource(ctx context.Context, uri string) error {
+	return c.CallResult(ctx, "resources/unsubscribe", ResourceParams{URI: uri}, nil)
+}
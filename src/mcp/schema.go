@@ -0,0 +1,383 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// SchemaType is the "type" keyword of a JSON Schema, which may be a single
+// type name or a list of them
+type SchemaType []string
+
+// UnmarshalJSON accepts both the string and the array form
+func (t *SchemaType) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = SchemaType{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("invalid schema type: %s", data)
+	}
+	*t = list
+	return nil
+}
+
+// MarshalJSON writes a single type as a plain string
+func (t SchemaType) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// Schema is the subset of JSON Schema used by tool input and output schemas
+type Schema struct {
+	Type        SchemaType         `json:"type,omitempty"`
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Enum        []interface{}      `json:"enum,omitempty"`
+	Default     json.RawMessage    `json:"default,omitempty"`
+	Format      string             `json:"format,omitempty"`
+	Pattern     string             `json:"pattern,omitempty"`
+	Minimum     *float64           `json:"minimum,omitempty"`
+	Maximum     *float64           `json:"maximum,omitempty"`
+	MinLength   *int               `json:"minLength,omitempty"`
+	MaxLength   *int               `json:"maxLength,omitempty"`
+	MinItems    *int               `json:"minItems,omitempty"`
+	MaxItems    *int               `json:"maxItems,omitempty"`
+
+	AdditionalProperties *AdditionalProperties `json:"additionalProperties,omitempty"`
+
+	// PropertyOrder lists the property names in the order they were declared
+	PropertyOrder []string `json:"-"`
+}
+
+// AdditionalProperties is the "additionalProperties" keyword: Forbidden
+// rejects properties not listed in Properties, Schema constrains them
+type AdditionalProperties struct {
+	Forbidden bool
+	Schema    *Schema
+}
+
+// UnmarshalJSON accepts both the boolean and the schema form
+func (a *AdditionalProperties) UnmarshalJSON(data []byte) error {
+	var allowed bool
+	if err := json.Unmarshal(data, &allowed); err == nil {
+		*a = AdditionalProperties{Forbidden: !allowed}
+		return nil
+	}
+	var schema Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return fmt.Errorf("invalid additionalProperties: %w", err)
+	}
+	*a = AdditionalProperties{Schema: &schema}
+	return nil
+}
+
+// MarshalJSON writes the schema, or a boolean when there is none
+func (a AdditionalProperties) MarshalJSON() ([]byte, error) {
+	if a.Schema != nil {
+		return json.Marshal(a.Schema)
+	}
+	return json.Marshal(!a.Forbidden)
+}
+
+// ParseSchema decodes a JSON Schema; an empty input yields an empty object schema
+func ParseSchema(raw json.RawMessage) (*Schema, error) {
+	if len(bytes.TrimSpace(raw)) == 0 || string(bytes.TrimSpace(raw)) == "null" {
+		return &Schema{Type: SchemaType{"object"}}, nil
+	}
+	var schema Schema
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	return &schema, nil
+}
+
+// UnmarshalJSON decodes the schema and records the declaration order of its properties
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	type plain Schema
+	if err := json.Unmarshal(data, (*plain)(s)); err != nil {
+		return err
+	}
+
+	var raw struct {
+		Properties json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil || len(raw.Properties) == 0 {
+		return err
+	}
+	order, err := objectKeys(raw.Properties)
+	if err != nil {
+		return err
+	}
+	s.PropertyOrder = order
+	return nil
+}
+
+// objectKeys returns the keys of a JSON object in document order
+func objectKeys(data json.RawMessage) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, nil
+	}
+	var keys []string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, tok.(string))
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// PrimaryType returns the first non-null type, inferring one from the other
+// keywords when "type" is absent
+func (s *Schema) PrimaryType() string {
+	for _, t := range s.Type {
+		if t != "null" {
+			return t
+		}
+	}
+	switch {
+	case len(s.Properties) > 0:
+		return "object"
+	case s.Items != nil:
+		return "array"
+	case len(s.Enum) > 0:
+		switch s.Enum[0].(type) {
+		case string:
+			return "string"
+		case float64:
+			return "number"
+		case bool:
+			return "boolean"
+		}
+	}
+	return ""
+}
+
+// PropertyNames returns the property names in declaration order, falling
+// back to alphabetical order for schemas built in code
+func (s *Schema) PropertyNames() []string {
+	if len(s.PropertyOrder) == len(s.Properties) {
+		return s.PropertyOrder
+	}
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsRequired reports whether the named property is required
+func (s *Schema) IsRequired(name string) bool {
+	for _, required := range s.Required {
+		if required == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SchemaProblem is a value that does not match a schema
+type SchemaProblem struct {
+	// Path locates the value, as in "servers[0].env.TOKEN"; it is empty for the root
+	Path    string
+	Message string
+}
+
+// Validate checks value against the schema and returns one message per
+// problem, each prefixed with the path of the offending value
+func (s *Schema) Validate(value interface{}) []string {
+	var messages []string
+	for _, problem := range s.Problems(value) {
+		name := problem.Path
+		if name == "" {
+			name = "arguments"
+		}
+		messages = append(messages, name+": "+problem.Message)
+	}
+	return messages
+}
+
+// Problems checks value against the schema, in the order the values are visited
+func (s *Schema) Problems(value interface{}) []SchemaProblem {
+	var problems []SchemaProblem
+	s.validate("", value, &problems)
+	return problems
+}
+
+func (s *Schema) validate(path string, value interface{}, problems *[]SchemaProblem) {
+	report := func(format string, args ...interface{}) {
+		*problems = append(*problems, SchemaProblem{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if value == nil {
+		if len(s.Type) > 0 && !s.allows("null") {
+			report("must not be null")
+		}
+		return
+	}
+
+	if len(s.Enum) > 0 && !enumContains(s.Enum, value) {
+		report("must be one of %s", enumList(s.Enum))
+		return
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if !s.allows("object") {
+			report("must be %s", s.typeList())
+			return
+		}
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				report("missing required property %q", name)
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			child := v[name]
+			if prop, ok := s.Properties[name]; ok {
+				prop.validate(joinPath(path, name), child, problems)
+			} else if s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil {
+				s.AdditionalProperties.Schema.validate(joinPath(path, name), child, problems)
+			} else if s.AdditionalProperties != nil && s.AdditionalProperties.Forbidden {
+				*problems = append(*problems, SchemaProblem{Path: joinPath(path, name), Message: "unknown property"})
+			}
+		}
+	case []interface{}:
+		if !s.allows("array") {
+			report("must be %s", s.typeList())
+			return
+		}
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			report("must have at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			report("must have at most %d items", *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, problems)
+			}
+		}
+	case string:
+		if !s.allows("string") {
+			report("must be %s", s.typeList())
+			return
+		}
+		length := utf8.RuneCountInString(v)
+		if s.MinLength != nil && length < *s.MinLength {
+			report("must be at least %d characters", *s.MinLength)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			report("must be at most %d characters", *s.MaxLength)
+		}
+		if s.Pattern != "" {
+			if re, err := regexp.Compile(s.Pattern); err == nil && !re.MatchString(v) {
+				report("must match %s", s.Pattern)
+			}
+		}
+	case bool:
+		if !s.allows("boolean") {
+			report("must be %s", s.typeList())
+		}
+	default:
+		n, ok := toFloat(v)
+		if !ok {
+			report("has unsupported type %T", v)
+			return
+		}
+		if !s.allows("number") && !(s.allows("integer") && n == math.Trunc(n)) {
+			report("must be %s", s.typeList())
+			return
+		}
+		if s.Minimum != nil && n < *s.Minimum {
+			report("must be >= %v", *s.Minimum)
+		}
+		if s.Maximum != nil && n > *s.Maximum {
+			report("must be <= %v", *s.Maximum)
+		}
+	}
+}
+
+// allows reports whether the schema accepts values of type t; an untyped schema accepts anything
+func (s *Schema) allows(t string) bool {
+	if len(s.Type) == 0 {
+		return true
+	}
+	for _, allowed := range s.Type {
+		if allowed == t || (allowed == "number" && t == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Schema) typeList() string {
+	return "of type " + strings.Join(s.Type, " or ")
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int64:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func enumContains(enum []interface{}, value interface{}) bool {
+	encoded, _ := json.Marshal(value)
+	for _, option := range enum {
+		if candidate, _ := json.Marshal(option); bytes.Equal(candidate, encoded) {
+			return true
+		}
+	}
+	return false
+}
+
+func enumList(enum []interface{}) string {
+	options := make([]string, len(enum))
+	for i, option := range enum {
+		encoded, _ := json.Marshal(option)
+		options[i] = string(encoded)
+	}
+	return strings.Join(options, ", ")
+}
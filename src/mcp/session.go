@@ -0,0 +1,246 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// SessionFrame is one line of a recorded session: a frame sent to or
+// received from the server, with its time since the session started
+type SessionFrame struct {
+	OffsetMS  float64         `json:"offset_ms"`
+	Direction string          `json:"direction"` // FrameSent or FrameReceived
+	Message   json.RawMessage `json:"message"`
+}
+
+// Offset returns the time since the start of the session
+func (f SessionFrame) Offset() time.Duration {
+	return time.Duration(f.OffsetMS * float64(time.Millisecond))
+}
+
+// LoadSession reads a recorded session written by RecordingTransport
+func LoadSession(r io.Reader) ([]SessionFrame, error) {
+	var frames []SessionFrame
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var frame SessionFrame
+		if err := json.Unmarshal(scanner.Bytes(), &frame); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if frame.Direction != FrameSent && frame.Direction != FrameReceived {
+			return nil, fmt.Errorf("line %d: invalid direction %q", line, frame.Direction)
+		}
+		frames = append(frames, frame)
+	}
+	return frames, scanner.Err()
+}
+
+// RecordingTransport wraps a transport and writes every JSON-RPC frame that
+// passes through it to w as a SessionFrame line. Output that is not JSON-RPC
+// is passed on but not recorded.
+type RecordingTransport struct {
+	Transport
+
+	mu    sync.Mutex
+	w     io.Writer
+	start time.Time
+	err   error
+}
+
+// NewRecordingTransport creates a transport recording the frames of inner to w
+func NewRecordingTransport(inner Transport, w io.Writer) *RecordingTransport {
+	return &RecordingTransport{Transport: inner, w: w}
+}
+
+// Start starts the inner transport and the session clock
+func (t *RecordingTransport) Start(ctx context.Context) error {
+	t.mu.Lock()
+	t.start = time.Now()
+	t.mu.Unlock()
+	return t.Transport.Start(ctx)
+}
+
+// Send records and forwards a frame to the server
+func (t *RecordingTransport) Send(ctx context.Context, data []byte) error {
+	t.record(FrameSent, data)
+	return t.Transport.Send(ctx, data)
+}
+
+// Receive returns and records the next frame from the server
+func (t *RecordingTransport) Receive() ([]byte, error) {
+	data, err := t.Transport.Receive()
+	if err == nil {
+		t.record(FrameReceived, data)
+	}
+	return data, err
+}
+
+// Err returns the first error writing the recording
+func (t *RecordingTransport) Err() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.err
+}
+
+// record writes a frame to the recording, skipping data that is not JSON
+func (t *RecordingTransport) record(direction string, data []byte) {
+	if !json.Valid(data) {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.err != nil {
+		return
+	}
+	line, err := json.Marshal(SessionFrame{
+		OffsetMS:  float64(time.Since(t.start).Microseconds()) / 1000,
+		Direction: direction,
+		Message:   data,
+	})
+	if err == nil {
+		_, err = t.w.Write(append(line, '\n'))
+	}
+	t.err = err
+}
+
+func (t *RecordingTransport) setProtocolVersion(version string) {
+	if st, ok := t.Transport.(sessionTransport); ok {
+		st.setProtocolVersion(version)
+	}
+}
+
+func (t *RecordingTransport) listen() {
+	if st, ok := t.Transport.(sessionTransport); ok {
+		st.listen()
+	}
+}
+
+// relayDrainTimeout bounds how long Relay waits for outstanding responses
+// once the client has closed its input
+const relayDrainTimeout = 5 * time.Second
+
+// Relay connects a client speaking newline-delimited JSON-RPC on in and out
+// to a server reached through transport, as if the client used the transport
+// itself. It returns when the server goes away, or when the client closes in
+// and the server has answered its outstanding requests.
+func Relay(ctx context.Context, transport Transport, in io.Reader, out io.Writer) error {
+	if err := transport.Start(ctx); err != nil {
+		return err
+	}
+	defer transport.Close()
+
+	// Requests are tracked to wait for their responses at the end, and the
+	// initialize request so that session based transports can be told
+	// about the negotiated session, as MCPClient does
+	var mu sync.Mutex
+	pending := make(map[RequestID]string)
+	answered := make(chan struct{}, 1)
+
+	serverDone := make(chan error, 1)
+	go func() {
+		for {
+			data, err := transport.Receive()
+			if err != nil {
+				if err == io.EOF {
+					err = nil
+				}
+				serverDone <- err
+				return
+			}
+			if _, err := out.Write(append(data, '\n')); err != nil {
+				serverDone <- err
+				return
+			}
+
+			messages, err := ParseMessages(data)
+			if err != nil {
+				continue
+			}
+			for _, msg := range messages {
+				resp, ok := msg.(*MCPResponse)
+				if !ok {
+					continue
+				}
+				mu.Lock()
+				method := pending[resp.ID]
+				delete(pending, resp.ID)
+				mu.Unlock()
+
+				if method == "initialize" && resp.Error == nil {
+					var result InitializeResult
+					st, ok := transport.(sessionTransport)
+					if ok && json.Unmarshal(resp.Result, &result) == nil {
+						st.setProtocolVersion(result.ProtocolVersion)
+						st.listen()
+					}
+				}
+				select {
+				case answered <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+
+	clientDone := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(in)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+		for scanner.Scan() {
+			data := append([]byte(nil), scanner.Bytes()...)
+			if messages, err := ParseMessages(data); err == nil {
+				mu.Lock()
+				for _, msg := range messages {
+					if req, ok := msg.(*MCPRequest); ok {
+						pending[req.ID] = req.Method
+					}
+				}
+				mu.Unlock()
+			}
+			if err := transport.Send(ctx, data); err != nil {
+				clientDone <- err
+				return
+			}
+		}
+		clientDone <- scanner.Err()
+	}()
+
+	select {
+	case err := <-serverDone:
+		return err
+	case <-ctx.Done():
+		return nil
+	case err := <-clientDone:
+		if err != nil {
+			return err
+		}
+	}
+
+	drained := time.After(relayDrainTimeout)
+	for {
+		mu.Lock()
+		outstanding := len(pending)
+		mu.Unlock()
+		if outstanding == 0 {
+			return nil
+		}
+		select {
+		case <-answered:
+		case err := <-serverDone:
+			return err
+		case <-drained:
+			return fmt.Errorf("%d requests unanswered when the client closed its input", outstanding)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
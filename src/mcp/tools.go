@@ -0,0 +1,89 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"mcop/src/types"
+)
+
+// maxListPages guards against servers that never stop returning a cursor
+const maxListPages = 100
+
+// PaginatedParams are the common parameters of the */list requests
+type PaginatedParams struct {
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// ListToolsResult is the server's reply to tools/list
+type ListToolsResult struct {
+	Tools      []types.Tool `json:"tools"`
+	NextCursor string       `json:"nextCursor,omitempty"`
+}
+
+// CallToolParams are the parameters of tools/call
+type CallToolParams struct {
+	Name      string      `json:"name"`
+	Arguments interface{} `json:"arguments,omitempty"`
+}
+
+// CallToolResult is the server's reply to tools/call. IsError reports a
+// failure inside the tool, as opposed to a protocol error.
+type CallToolResult struct {
+	Content           []Content       `json:"content"`
+	StructuredContent json.RawMessage `json:"structuredContent,omitempty"`
+	IsError           bool            `json:"isError,omitempty"`
+	Meta              json.RawMessage `json:"_meta,omitempty"`
+}
+
+// ListTools fetches every tool exposed by the server, following pagination cursors
+func (c *MCPClient) ListTools(ctx context.Context) ([]types.Tool, error) {
+	var tools []types.Tool
+	err := c.paginate(ctx, "tools/list", func(raw json.RawMessage) (string, error) {
+		var page ListToolsResult
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return "", err
+		}
+		tools = append(tools, page.Tools...)
+		return page.NextCursor, nil
+	})
+	return tools, err
+}
+
+// CallTool invokes a tool with the given arguments, which are encoded as a
+// JSON object (a json.RawMessage is passed through unchanged)
+func (c *MCPClient) CallTool(ctx context.Context, name string, arguments interface{}) (*CallToolResult, error) {
+	var result CallToolResult
+	if err := c.CallResult(ctx, "tools/call", CallToolParams{Name: name, Arguments: arguments}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// paginate calls a */list method repeatedly, passing each page's result to
+// handle until it returns an empty cursor
+func (c *MCPClient) paginate(ctx context.Context, method string, handle func(json.RawMessage) (string, error)) error {
+	cursor := ""
+	for page := 0; page < maxListPages; page++ {
+		var params interface{}
+		if cursor != "" {
+			params = PaginatedParams{Cursor: cursor}
+		}
+
+		var raw json.RawMessage
+		if err := c.CallResult(ctx, method, params, &raw); err != nil {
+			return err
+		}
+
+		next, err := handle(raw)
+		if err != nil {
+			return fmt.Errorf("failed to decode %s result: %w", method, err)
+		}
+		if next == "" || next == cursor {
+			return nil
+		}
+		cursor = next
+	}
+	return fmt.Errorf("%s returned more than %d pages", method, maxListPages)
+}
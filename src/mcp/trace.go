@@ -0,0 +1,143 @@
+package mcp
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Frame directions
+const (
+	FrameSent     = "sent"
+	FrameReceived = "received"
+)
+
+// Frame kinds
+const (
+	FrameRequest      = "request"
+	FrameResponse     = "response"
+	FrameNotification = "notification"
+	FrameInvalid      = "invalid" // output that is not JSON-RPC
+)
+
+// maxTracedData bounds the bytes of a frame kept for inspection
+const maxTracedData = 64 * 1024
+
+// Frame is a single JSON-RPC message exchanged with a server
+type Frame struct {
+	Time      time.Time
+	Direction string
+	Kind      string
+	// Method is the request or notification method; for responses to
+	// requests mcop sent, it is the method of that request
+	Method string
+	ID     string // empty for notifications
+	// IsError is set for error responses and invalid frames
+	IsError bool
+	// Latency is set on responses to requests mcop sent
+	Latency time.Duration
+	Size    int
+	// Data holds the frame, cut off after maxTracedData bytes
+	Data      []byte
+	Truncated bool
+}
+
+// FrameObserver is told about every frame sent or received. Observers run on
+// the goroutine moving the frame and must not block.
+type FrameObserver func(frame Frame)
+
+// tracedRequest remembers a request mcop sent until its response arrives
+type tracedRequest struct {
+	method string
+	at     time.Time
+}
+
+// OnFrame registers an observer of the frames exchanged with the server
+func (c *MCPClient) OnFrame(observer FrameObserver) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.frameObservers = append(c.frameObservers, observer)
+}
+
+// traceFrame reports a frame to the frame observers; msg is nil for data
+// that could not be parsed
+func (c *MCPClient) traceFrame(direction string, msg Message, data []byte) {
+	now := time.Now()
+	frame := Frame{Time: now, Direction: direction, Size: len(data)}
+
+	c.mu.Lock()
+	if len(c.frameObservers) == 0 {
+		c.mu.Unlock()
+		return
+	}
+	switch m := msg.(type) {
+	case *MCPRequest:
+		frame.Kind, frame.Method, frame.ID = FrameRequest, m.Method, m.ID.String()
+		if direction == FrameSent {
+			c.traced[m.ID] = tracedRequest{method: m.Method, at: now}
+		}
+	case *MCPResponse:
+		frame.Kind, frame.ID, frame.IsError = FrameResponse, m.ID.String(), m.Error != nil
+		if request, ok := c.traced[m.ID]; ok && direction == FrameReceived {
+			frame.Method, frame.Latency = request.method, now.Sub(request.at)
+			delete(c.traced, m.ID)
+		}
+	case *MCPNotification:
+		frame.Kind, frame.Method = FrameNotification, m.Method
+	default:
+		frame.Kind, frame.IsError = FrameInvalid, true
+	}
+	observers := c.frameObservers
+	c.mu.Unlock()
+
+	if len(data) > maxTracedData {
+		data, frame.Truncated = data[:maxTracedData], true
+	}
+	frame.Data = append([]byte(nil), data...)
+	for _, observer := range observers {
+		observer(frame)
+	}
+}
+
+// traceReceived reports the frames of data read from the server, one per
+// message of a batch
+func (c *MCPClient) traceReceived(messages []Message, data []byte) {
+	if len(messages) == 1 {
+		c.traceFrame(FrameReceived, messages[0], data)
+		return
+	}
+	for _, msg := range messages {
+		part, _ := json.Marshal(msg)
+		c.traceFrame(FrameReceived, msg, part)
+	}
+}
+
+// FrameBuffer keeps the most recent frames of a client. It is safe for concurrent use.
+type FrameBuffer struct {
+	mu       sync.Mutex
+	frames   []Frame
+	capacity int
+}
+
+// NewFrameBuffer creates a buffer holding at most capacity frames
+func NewFrameBuffer(capacity int) *FrameBuffer {
+	return &FrameBuffer{capacity: capacity}
+}
+
+// Add appends a frame, dropping the oldest one when the buffer is full
+func (b *FrameBuffer) Add(frame Frame) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.frames) >= b.capacity {
+		copy(b.frames, b.frames[1:])
+		b.frames = b.frames[:len(b.frames)-1]
+	}
+	b.frames = append(b.frames, frame)
+}
+
+// Frames returns a copy of the buffered frames, oldest first
+func (b *FrameBuffer) Frames() []Frame {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]Frame(nil), b.frames...)
+}
@@ -0,0 +1,351 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"mcop/src/types"
+)
+
+// Transport moves encoded JSON-RPC frames between the client and a server
+type Transport interface {
+	// Start opens the connection; ctx bounds the lifetime of the connection
+	Start(ctx context.Context) error
+	// Send delivers a single encoded frame to the server
+	Send(ctx context.Context, data []byte) error
+	// Receive blocks until the next frame arrives and returns io.EOF once the connection ends
+	Receive() ([]byte, error)
+	// Close terminates the connection
+	Close() error
+}
+
+// sessionTransport is implemented by transports that need to know about the
+// negotiated session once the handshake has completed
+type sessionTransport interface {
+	setProtocolVersion(version string)
+	listen()
+}
+
+// NewTransport selects a transport for the server from its transport type,
+// applying its launch settings or headers
+func NewTransport(server types.MCPServer) (Transport, error) {
+	if server.Command == "" && server.URL == "" {
+		return nil, fmt.Errorf("server has neither a command nor a URL")
+	}
+
+	switch transport := server.TransportType(); transport {
+	case types.TransportStdio:
+		command, args := server.Command, server.Args
+		if command == "" {
+			var err error
+			if command, args, err = ParseStdioURL(server.URL); err != nil {
+				return nil, err
+			}
+		}
+		stdio := NewStdioTransport(command, args)
+		if len(server.Env) > 0 {
+			stdio.Env = server.Environ(os.Environ())
+		}
+		stdio.Dir = server.Cwd
+		return stdio, nil
+	case types.TransportHTTP, types.TransportSSE:
+		u, err := url.Parse(server.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return nil, fmt.Errorf("invalid server URL %q for %s transport", server.URL, transport)
+		}
+		if transport == types.TransportSSE {
+			sse := NewSSETransport(server.URL)
+			setHeaders(sse.Headers, server.Headers)
+			return sse, nil
+		}
+		streamable := NewStreamableHTTPTransport(server.URL)
+		setHeaders(streamable.Headers, server.Headers)
+		return streamable, nil
+	case "":
+		return nil, fmt.Errorf("unsupported protocol: %s", server.URL)
+	default:
+		return nil, fmt.Errorf("unknown transport %q", transport)
+	}
+}
+
+// setHeaders adds configured headers to a transport's headers
+func setHeaders(dst http.Header, headers map[string]string) {
+	for name, value := range headers {
+		dst.Set(name, value)
+	}
+}
+
+// ParseStdioURL splits a stdio:// server URL into the command and its arguments
+func ParseStdioURL(serverURL string) (string, []string, error) {
+	if !strings.HasPrefix(serverURL, "stdio://") {
+		return "", nil, fmt.Errorf("not a stdio URL: %s", serverURL)
+	}
+	command := strings.TrimPrefix(serverURL, "stdio://")
+	parts := parseCommand(command)
+	if len(parts) == 0 {
+		return "", nil, fmt.Errorf("invalid command: %s", command)
+	}
+	return parts[0], parts[1:], nil
+}
+
+// FormatStdioURL builds a stdio:// server URL that ParseStdioURL splits back
+// into command and args
+func FormatStdioURL(command string, args []string) (string, error) {
+	parts := append([]string{command}, args...)
+	quoted := make([]string, len(parts))
+	for i, part := range parts {
+		if part == "" {
+			return "", fmt.Errorf("empty arguments cannot be expressed in a stdio URL")
+		}
+		quoted[i] = quoteCommandPart(part)
+	}
+	return "stdio://" + strings.Join(quoted, " "), nil
+}
+
+// quoteCommandPart quotes a command line word for parseCommand, which has no
+// escapes: single quotes are wrapped in double quotes and runs of other
+// characters in single quotes
+func quoteCommandPart(part string) string {
+	if !strings.ContainsAny(part, ` '"`) {
+		return part
+	}
+	var sb strings.Builder
+	for len(part) > 0 {
+		// Split off the longest prefix made only of single quotes, or of
+		// anything but single quotes
+		single := part[0] == '\''
+		end := strings.IndexFunc(part, func(r rune) bool { return (r == '\'') != single })
+		if end < 0 {
+			end = len(part)
+		}
+		if single {
+			sb.WriteString(`"` + part[:end] + `"`)
+		} else {
+			sb.WriteString("'" + part[:end] + "'")
+		}
+		part = part[end:]
+	}
+	return sb.String()
+}
+
+// StdioTransport runs the server as a child process and exchanges
+// newline-delimited frames over its stdin and stdout
+type StdioTransport struct {
+	Command string
+	Args    []string
+	// Env and Dir configure the process; nil Env inherits mcop's environment
+	Env []string
+	Dir string
+	// Stderr receives the server's stderr; nil discards it
+	Stderr io.Writer
+
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	stdout  *os.File
+	scanner *bufio.Scanner
+	writeMu sync.Mutex
+
+	exited  chan struct{}
+	exitErr error
+}
+
+// NewStdioTransport creates a transport that will launch command with args
+func NewStdioTransport(command string, args []string) *StdioTransport {
+	return &StdioTransport{
+		Command: command,
+		Args:    args,
+		exited:  make(chan struct{}),
+	}
+}
+
+// Start launches the server process and begins waiting for it to exit
+func (t *StdioTransport) Start(ctx context.Context) error {
+	t.cmd = exec.CommandContext(ctx, t.Command, t.Args...)
+	t.cmd.Env = t.Env
+	t.cmd.Dir = t.Dir
+	t.cmd.Stderr = t.Stderr
+
+	// Plain pipes rather than cmd.StdinPipe/StdoutPipe, so that cmd.Wait can
+	// run as soon as the process exits without racing the frame reader
+	stdinReader, stdinWriter, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stdin pipe: %w", err)
+	}
+	stdoutReader, stdoutWriter, err := os.Pipe()
+	if err != nil {
+		stdinReader.Close()
+		stdinWriter.Close()
+		return fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+	t.cmd.Stdin = stdinReader
+	t.cmd.Stdout = stdoutWriter
+
+	err = t.cmd.Start()
+	stdinReader.Close()
+	stdoutWriter.Close()
+	if err != nil {
+		stdinWriter.Close()
+		stdoutReader.Close()
+		return fmt.Errorf("failed to start command: %w", err)
+	}
+
+	t.stdin = stdinWriter
+	t.stdout = stdoutReader
+	t.scanner = bufio.NewScanner(stdoutReader)
+	t.scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+
+	go func() {
+		t.exitErr = t.cmd.Wait()
+		close(t.exited)
+	}()
+	return nil
+}
+
+// Send writes a frame to the server's stdin as a single line
+func (t *StdioTransport) Send(ctx context.Context, data []byte) error {
+	line := make([]byte, 0, len(data)+1)
+	line = append(line, data...)
+	line = append(line, '\n')
+
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	if _, err := t.stdin.Write(line); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	return nil
+}
+
+// Receive reads the next line from the server's stdout
+func (t *StdioTransport) Receive() ([]byte, error) {
+	if t.scanner.Scan() {
+		data := make([]byte, len(t.scanner.Bytes()))
+		copy(data, t.scanner.Bytes())
+		return data, nil
+	}
+	if err := t.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// Close closes the server's stdin and kills the process
+func (t *StdioTransport) Close() error {
+	if t.stdin != nil {
+		t.stdin.Close()
+	}
+	var err error
+	if t.cmd != nil && t.cmd.Process != nil {
+		err = t.cmd.Process.Kill()
+		if errors.Is(err, os.ErrProcessDone) {
+			err = nil
+		}
+	}
+	if t.stdout != nil {
+		t.stdout.Close()
+	}
+	return err
+}
+
+// Shutdown stops the server the way the MCP specification recommends: it
+// closes stdin and waits for the process to exit, then sends SIGTERM and
+// finally SIGKILL, waiting up to timeout before each signal
+func (t *StdioTransport) Shutdown(timeout time.Duration) error {
+	if t.cmd == nil || t.cmd.Process == nil {
+		return t.Close()
+	}
+	if t.stdin != nil {
+		t.stdin.Close()
+	}
+	select {
+	case <-t.exited:
+	case <-time.After(timeout):
+		t.cmd.Process.Signal(syscall.SIGTERM)
+		select {
+		case <-t.exited:
+		case <-time.After(timeout):
+		}
+	}
+	return t.Close()
+}
+
+// Process returns the server process, or nil if it has not been started
+func (t *StdioTransport) Process() *os.Process {
+	if t.cmd == nil {
+		return nil
+	}
+	return t.cmd.Process
+}
+
+// Exited is closed once the server process has exited and been reaped
+func (t *StdioTransport) Exited() <-chan struct{} {
+	return t.exited
+}
+
+// ExitErr returns the result of waiting for the process: nil for a clean
+// exit, an *exec.ExitError for a non-zero status or death by signal. It is
+// only meaningful once Exited is closed.
+func (t *StdioTransport) ExitErr() error {
+	select {
+	case <-t.exited:
+		return t.exitErr
+	default:
+		return nil
+	}
+}
+
+// inbox buffers frames received asynchronously by the HTTP transports
+type inbox struct {
+	messages chan []byte
+	done     chan struct{}
+	once     sync.Once
+	err      error
+}
+
+func newInbox() *inbox {
+	return &inbox{
+		messages: make(chan []byte, 64),
+		done:     make(chan struct{}),
+	}
+}
+
+// deliver queues a frame, returning false if the inbox has been closed
+func (q *inbox) deliver(data []byte) bool {
+	select {
+	case q.messages <- data:
+		return true
+	case <-q.done:
+		return false
+	}
+}
+
+// receive returns the next queued frame, or the close error once closed
+func (q *inbox) receive() ([]byte, error) {
+	select {
+	case data := <-q.messages:
+		return data, nil
+	case <-q.done:
+		if q.err != nil {
+			return nil, q.err
+		}
+		return nil, io.EOF
+	}
+}
+
+// close stops delivery; the first error recorded is returned by receive
+func (q *inbox) close(err error) {
+	q.once.Do(func() {
+		q.err = err
+		close(q.done)
+	})
+}
@@ -1,13 +1,19 @@
 package model
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"mcop/src/mcp"
+	"mcop/src/procstat"
+	"mcop/src/stats"
 	"mcop/src/config"
+	"mcop/src/logs"
+	"mcop/src/supervisor"
+	"mcop/src/types"
 )
 
 // AppState represents the main application state
@@ -16,12 +22,20 @@ type AppState struct {
 	Connections       []Connection
 	MCPConnections    map[string]*mcp.MCPClient // Map of server ID to MCP client
 	SelectedIndex     int
-	View              string // "list", "detail", "config"
+	View              string // "list", "detail", "config", "resources", "prompts", "tools", "logs", "traffic"
 	Error             string
 	IsLoading         bool
 	RefreshRate       int
 	AutoRefresh       bool
 	InitialServerURL  string
+	Resources         ResourceBrowser
+	Prompts           PromptBrowser
+	Tools             ToolRunner
+	Logs              LogViewer
+	Traffic           TrafficInspector
+	// PendingRestart lists running servers whose launch settings changed in
+	// the configuration; they keep their old process until restarted
+	PendingRestart    []string
 }
 
 // AppModel is the main Bubble Tea model
@@ -30,11 +44,52 @@ type AppModel struct {
 	Width int
 	Height int
 	Config *config.AppConfig
+	// configPath is the file the configuration was loaded from, empty for
+	// the merged configuration layers
+	configPath string
+	// profile names the configured profile selecting the listed servers,
+	// empty for all of them
+	profile string
+	// Logs holds captured server output and operational messages
+	Logs *logs.Store
+	// Stats holds the latency and error history of calls to each server
+	Stats *stats.Store
+
+	// events carries messages emitted by background MCP clients into the update loop
+	events chan tea.Msg
+
+	// exits carries the ends of supervised clients. Unlike events it is sent
+	// to without dropping, since a lost exit would leave a crashed server
+	// shown as running and never restarted.
+	exits chan ServerExitedMsg
+
+	// logsChanged signals that entries were appended to Logs
+	logsChanged chan struct{}
+
+	// frames keeps the protocol traffic of each server; trafficChanged
+	// signals that frames were recorded
+	frames         map[string]*mcp.FrameBuffer
+	trafficChanged chan struct{}
+
+	// supervisor decides whether servers are restarted after their process exits
+	supervisor *supervisor.Supervisor
+
+	// sampler computes process metrics of local servers between refreshes
+	sampler *procstat.Sampler
+
+	// pinging holds the servers with a health check ping in flight
+	pinging map[string]bool
+
+	// launches counts the launches of each server, so that the result of a
+	// launch superseded while it was connecting is dropped
+	launches map[string]int
 }
 
-func NewAppModel() *AppModel {
+// NewAppModel creates the model for the configuration at configPath, or the
+// merged configuration layers when configPath is empty
+func NewAppModel(configPath string) *AppModel {
 	// Load configuration
-	cfg, err := config.LoadConfig("")
+	cfg, err := config.LoadConfig(configPath)
 	if err != nil {
 		// If there's an error loading config, use default config
 		fmt.Printf("Warning: failed to load config, using defaults: %v\n", err)
@@ -44,40 +99,12 @@ func NewAppModel() *AppModel {
 	// Convert config.MCPServer to model.MCPServer
 	servers := make([]MCPServer, len(cfg.Servers))
 	for i, cfgServer := range cfg.Servers {
-		startTime := time.Time{}
-		responseTime := time.Duration(0)
-
-		// Convert interface{} fields if possible
-		if cfgServer.StartTime != nil {
-			// Try to convert to time.Time if possible
-			if ts, ok := cfgServer.StartTime.(time.Time); ok {
-				startTime = ts
-			}
-		}
-
-		if cfgServer.ResponseTime != nil {
-			// Try to convert to time.Duration if possible
-			if rt, ok := cfgServer.ResponseTime.(time.Duration); ok {
-				responseTime = rt
-			}
-		}
-
-		servers[i] = MCPServer{
-			ID:                cfgServer.ID,
-			Name:              cfgServer.Name,
-			URL:               cfgServer.URL,
-			Status:            cfgServer.Status,
-			StartTime:         startTime,
-			ResponseTime:      responseTime,
-			ActiveConnections: cfgServer.ActiveConnections,
-			Description:       cfgServer.Description,
-			Tools:             cfgServer.Tools,
-		}
+		servers[i] = newServer(cfgServer)
 	}
 
-	return &AppModel{
+	m := &AppModel{
 		State: AppState{
-			Servers:        servers,
+			Servers:        groupServers(servers),
 			Connections:    []Connection{},
 			MCPConnections: make(map[string]*mcp.MCPClient),
 			SelectedIndex:  0,
@@ -87,8 +114,39 @@ func NewAppModel() *AppModel {
 		},
 		Width:  80,
 		Height: 24,
-		Config: cfg,
+		Config:         cfg,
+		configPath:     configPath,
+		events:         make(chan tea.Msg, eventBufferSize),
+		exits:          make(chan ServerExitedMsg),
+		supervisor:     supervisor.New(),
+		Stats:          stats.NewStore(),
+		sampler:        procstat.NewSampler(),
+		pinging:        make(map[string]bool),
+		launches:       make(map[string]int),
+		logsChanged:    make(chan struct{}, 1),
+		frames:         make(map[string]*mcp.FrameBuffer),
+		trafficChanged: make(chan struct{}, 1),
+	}
+	m.Logs = logs.NewStore(logCapacity, m.notifyLogs)
+	m.configureSupervisor()
+	return m
+}
+
+// newServer converts a configured server to a model server
+func newServer(cfgServer config.MCPServer) MCPServer {
+	server := cfgServer.RuntimeServer()
+	if server.Status == "" {
+		server.Status = "stopped"
+	}
+
+	// Convert interface{} fields if possible
+	if ts, ok := cfgServer.StartTime.(time.Time); ok {
+		server.StartTime = ts
 	}
+	if rt, ok := cfgServer.ResponseTime.(time.Duration); ok {
+		server.ResponseTime = rt
+	}
+	return server
 }
 
 func (m *AppModel) SetInitialServerURL(url string) {
@@ -99,14 +157,45 @@ func (m *AppModel) SetInitialServerURL(url string) {
 func (m *AppModel) Init() tea.Cmd {
 	// The servers are already loaded from config in NewAppModel
 	// Only load mock servers if config has no servers
-	if len(m.State.Servers) == 0 {
+	if len(m.State.Servers) == 0 && m.profile == "" {
 		m.loadMockServers()
 	}
-	return nil
+	if err := m.WatchConfig(); err != nil {
+		m.Log("", logs.LevelWarning, "%v", err)
+	}
+	return tea.Batch(m.listenForEvents(), m.listenForExits(), m.listenForLogs(), m.listenForTraffic(), m.scheduleRefresh())
 }
 
 // Update handles messages and updates the model
 func (m *AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if cmd, ok := m.updateResources(msg); ok {
+		return m, cmd
+	}
+	if cmd, ok := m.updatePrompts(msg); ok {
+		return m, cmd
+	}
+	if cmd, ok := m.updateTools(msg); ok {
+		return m, cmd
+	}
+	if cmd, ok := m.updateSupervisor(msg); ok {
+		return m, cmd
+	}
+	if cmd, ok := m.updateLogs(msg); ok {
+		return m, cmd
+	}
+	if cmd, ok := m.updateMetrics(msg); ok {
+		return m, cmd
+	}
+	if cmd, ok := m.updateRefresh(msg); ok {
+		return m, cmd
+	}
+	if cmd, ok := m.updateTraffic(msg); ok {
+		return m, cmd
+	}
+	if cmd, ok := m.updateReload(msg); ok {
+		return m, cmd
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		return m.handleKeyMsg(msg)
@@ -114,6 +203,17 @@ func (m *AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.Width = msg.Width
 		m.Height = msg.Height
 		return m, nil
+	case ToolsChangedMsg:
+		return m, tea.Batch(m.RefreshTools(msg.ServerID), m.listenForEvents())
+	case ToolsLoadedMsg:
+		if server := m.serverByID(msg.ServerID); server != nil {
+			if msg.Err != nil {
+				m.State.Error = fmt.Sprintf("failed to list tools of %s: %v", server.Name, msg.Err)
+			} else {
+				server.Tools = msg.Tools
+			}
+		}
+		return m, nil
 	}
 	return m, nil
 }
@@ -126,6 +226,25 @@ func (m *AppModel) View() string {
 }
 
 func (m *AppModel) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Let the active browser view handle its own keys first
+	var cmd tea.Cmd
+	handled := false
+	switch m.State.View {
+	case "resources":
+		cmd, handled = m.handleResourcesKey(msg)
+	case "prompts":
+		cmd, handled = m.handlePromptsKey(msg)
+	case "tools":
+		cmd, handled = m.handleToolsKey(msg)
+	case "logs":
+		cmd, handled = m.handleLogsKey(msg)
+	case "traffic":
+		cmd, handled = m.handleTrafficKey(msg)
+	}
+	if handled {
+		return m, cmd
+	}
+
 	switch msg.String() {
 	case "ctrl+c", "q":
 		return m, tea.Quit
@@ -140,14 +259,40 @@ func (m *AppModel) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "enter":
 		m.State.View = "detail"
 	case "r":
-		m.loadMockServers() // Refresh
+		if m.State.View == "detail" {
+			return m, m.OpenResources(m.State.SelectedIndex)
+		}
+		return m, m.Refresh()
+	case "p":
+		if m.State.View == "detail" {
+			return m, m.OpenPrompts(m.State.SelectedIndex)
+		}
+	case "t":
+		if m.State.View == "detail" {
+			return m, m.OpenTools(m.State.SelectedIndex)
+		}
+	case "l":
+		switch m.State.View {
+		case "list":
+			m.OpenLogs("")
+		case "detail":
+			if m.State.SelectedIndex < len(m.State.Servers) {
+				m.OpenLogs(m.State.Servers[m.State.SelectedIndex].ID)
+			}
+		}
+	case "i":
+		if m.State.View == "list" || m.State.View == "detail" {
+			m.OpenTraffic(m.State.SelectedIndex)
+		}
 	case "c":
 		m.State.View = "config"
 	case "s":
 		if m.State.View == "list" && m.State.SelectedIndex < len(m.State.Servers) {
-			m.ToggleServer(m.State.SelectedIndex)
-
-			// The UI layer will handle logging
+			return m, m.ToggleServer(m.State.SelectedIndex)
+		}
+	case "g":
+		if m.State.View == "list" {
+			return m, m.ToggleGroup(m.State.SelectedIndex)
 		}
 	case "d":
 		if m.State.View == "detail" && m.State.SelectedIndex < len(m.State.Servers) {
@@ -203,7 +348,7 @@ func (m *AppModel) renderSimpleListView() string {
 		}
 
 		sb.WriteString(fmt.Sprintf("%s %s %-30s %-12s %3d      %s\n",
-			prefix, statusSymbol, name, server.Status, server.ActiveConnections, server.URL))
+			prefix, statusSymbol, name, server.Status, server.ActiveConnections, server.Endpoint()))
 	}
 
 	// Add key bindings information
@@ -223,7 +368,7 @@ func (m *AppModel) loadMockServers() {
 			ResponseTime:      120 * time.Millisecond,
 			ActiveConnections: 2,
 			Description:       "GitHub integration server",
-			Tools:             []string{"get_repo_info", "create_issue", "search_issues"},
+			Tools:             types.ToolsFromNames([]string{"get_repo_info", "create_issue", "search_issues"}),
 		},
 		{
 			ID:                "2",
@@ -234,7 +379,7 @@ func (m *AppModel) loadMockServers() {
 			ResponseTime:      85 * time.Millisecond,
 			ActiveConnections: 1,
 			Description:       "Personal calendar integration",
-			Tools:             []string{"get_events", "create_event", "update_event"},
+			Tools:             types.ToolsFromNames([]string{"get_events", "create_event", "update_event"}),
 		},
 		{
 			ID:                "3",
@@ -245,7 +390,7 @@ func (m *AppModel) loadMockServers() {
 			ResponseTime:      0,
 			ActiveConnections: 0,
 			Description:       "File system operations",
-			Tools:             []string{"read_file", "write_file", "list_dir"},
+			Tools:             types.ToolsFromNames([]string{"read_file", "write_file", "list_dir"}),
 		},
 	}
 }
@@ -276,7 +421,7 @@ func (m *AppModel) listView() string {
 		}
 
 		s += fmt.Sprintf("%s %s %-30s %-12s %3d      %s\n",
-			prefix, statusSymbol, name, server.Status, server.ActiveConnections, server.URL)
+			prefix, statusSymbol, name, server.Status, server.ActiveConnections, server.Endpoint())
 	}
 
 	// Add key bindings information
@@ -293,7 +438,7 @@ func (m *AppModel) detailView() string {
 
 	s := "MCOP - Server Details\n\n"
 	s += "Name: " + server.Name + "\n"
-	s += "URL: " + server.URL + "\n"
+	s += "URL: " + server.Endpoint() + "\n"
 	s += "Status: " + server.Status + "\n"
 
 	if !server.StartTime.IsZero() {
@@ -307,12 +452,12 @@ func (m *AppModel) detailView() string {
 	s += "Description: " + server.Description + "\n"
 	s += "\nAvailable Tools:\n"
 	for _, tool := range server.Tools {
-		s += "  - " + tool + "\n"
+		s += "  - " + tool.Name + "\n"
 	}
 
 	// Add start/stop button based on current status
 	action := "start"
-	if server.Status == "running" {
+	if server.IsRunning() {
 		action = "stop"
 	}
 
@@ -335,40 +480,32 @@ func boolToString(b bool) string {
 	return "disabled"
 }
 
-func (m *AppModel) ToggleServer(index int) {
+// ToggleServer stops a running or connecting server, or starts a stopped
+// one. Servers connect in the background and show as connecting until the
+// handshake completes, so a slow server does not block the interface.
+func (m *AppModel) ToggleServer(index int) tea.Cmd {
 	if index >= len(m.State.Servers) {
-		return
+		return nil
 	}
 
 	server := &m.State.Servers[index]
 
-	if server.Status == "running" {
-		// Disconnect from the MCP server
-		client, exists := m.State.MCPConnections[server.ID]
-		if exists && client != nil {
-			client.Disconnect()
-			delete(m.State.MCPConnections, server.ID)
-		}
-		server.Status = "stopped"
-	} else if server.Status == "stopped" {
-		// Connect to the MCP server
-		client := mcp.NewMCPClient(*server)  // Pass value, not pointer
-		err := client.Connect()
-		if err != nil {
-			server.Status = "error"
-			return
-		}
-		m.State.MCPConnections[server.ID] = client
-		server.Status = "running"
-		server.StartTime = time.Now()
-	}
+	// A manual start or stop overrides any restart the supervisor scheduled
+	m.supervisor.Cancel(server.ID)
 
-	// Update active connections based on status
-	if server.Status == "running" {
-		server.ActiveConnections = 1 // Simulate one active connection when running
-	} else {
-		server.ActiveConnections = 0
+	switch {
+	case server.IsRunning():
+		m.DisconnectServer(index)
+		m.Log(server.ID, logs.LevelInfo, "Stopped server: %s", server.Name)
+		return nil
+	case server.Status == "connecting":
+		// The pending connection is dropped when it completes
+		server.Status = "stopped"
+		m.Log(server.ID, logs.LevelInfo, "Stopped server: %s", server.Name)
+		return nil
 	}
+	server.Status = "connecting"
+	return m.launchServer(server.ID, false)
 }
 
 func (m *AppModel) DisconnectServer(index int) {
@@ -377,7 +514,8 @@ func (m *AppModel) DisconnectServer(index int) {
 	}
 
 	server := &m.State.Servers[index]
-	if server.Status == "running" {
+	m.supervisor.Cancel(server.ID)
+	if server.IsRunning() {
 		client, exists := m.State.MCPConnections[server.ID]
 		if exists && client != nil {
 			client.Disconnect()
@@ -385,5 +523,78 @@ func (m *AppModel) DisconnectServer(index int) {
 		}
 		server.ActiveConnections = 0
 		server.Status = "stopped"
+		m.clearProcess(server)
+	}
+}
+
+// RefreshTools fetches the live tool list of a connected server in the background
+func (m *AppModel) RefreshTools(serverID string) tea.Cmd {
+	client, exists := m.State.MCPConnections[serverID]
+	if !exists || client == nil || client.ServerCapabilities().Tools == nil {
+		return nil
+	}
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), listTimeout)
+		defer cancel()
+		tools, err := client.ListTools(ctx)
+		return ToolsLoadedMsg{ServerID: serverID, Tools: tools, Err: err}
 	}
-}
\ No newline at end of file
+}
+
+// watchClient routes the client's notifications into the update loop
+func (m *AppModel) watchClient(serverID string, client *mcp.MCPClient) {
+	client.OnNotification("notifications/tools/list_changed", func(*mcp.MCPNotification) {
+		m.emit(ToolsChangedMsg{ServerID: serverID})
+	})
+	m.watchResources(serverID, client)
+	m.watchPrompts(serverID, client)
+	m.watchLogs(serverID, client)
+	m.watchTraffic(serverID, client)
+	client.OnCall(func(method string, latency time.Duration, err error) {
+		// Health check pings would swamp the throughput and latency of real work
+		if method == "ping" {
+			return
+		}
+		m.Stats.Record(serverID, time.Now(), latency, err != nil)
+	})
+}
+
+// CapturesInput reports whether a text form currently has keyboard focus,
+// in which case printable keys must not trigger shortcuts
+func (m *AppModel) CapturesInput() bool {
+	switch m.State.View {
+	case "prompts":
+		return m.State.Prompts.Editing
+	case "tools":
+		return m.State.Tools.Editing
+	case "traffic":
+		return m.State.Traffic.EditingFilter
+	}
+	return false
+}
+
+// emit queues a message for the update loop, dropping it if the queue is full
+func (m *AppModel) emit(msg tea.Msg) {
+	select {
+	case m.events <- msg:
+	default:
+	}
+}
+
+// listenForEvents waits for the next message emitted by a background client
+func (m *AppModel) listenForEvents() tea.Cmd {
+	return func() tea.Msg {
+		return <-m.events
+	}
+}
+
+// serverByID returns the server with the given ID, or nil if there is none
+func (m *AppModel) serverByID(serverID string) *MCPServer {
+	for i := range m.State.Servers {
+		if m.State.Servers[i].ID == serverID {
+			return &m.State.Servers[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package model
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"mcop/src/mcp"
+)
+
+// FieldKind determines how a FormField is edited and how its value is decoded
+type FieldKind int
+
+const (
+	FieldText FieldKind = iota
+	FieldNumber
+	FieldInteger
+	FieldBool
+	FieldEnum
+	FieldList
+	FieldJSON
+)
+
+// FormField is a single input in a Form. Choice fields (FieldBool and
+// FieldEnum) cycle through Options instead of accepting typed text.
+type FormField struct {
+	Name        string
+	Label       string
+	Description string
+	Required    bool
+	Value       string
+	Kind        FieldKind
+	Options     []string
+
+	// Path is the location of the value in nested arguments, and Schema
+	// describes it; both are only set on forms built by NewSchemaForm
+	Path   []string
+	Schema *mcp.Schema
+}
+
+// IsChoice reports whether the field cycles through fixed options
+func (f *FormField) IsChoice() bool {
+	return f.Kind == FieldBool || f.Kind == FieldEnum
+}
+
+// cycle moves a choice field to the next or previous option; optional
+// fields include an empty choice meaning "unset"
+func (f *FormField) cycle(step int) {
+	choices := f.Options
+	if !f.Required {
+		choices = append([]string{""}, f.Options...)
+	}
+	if len(choices) == 0 {
+		return
+	}
+	current := 0
+	for i, choice := range choices {
+		if choice == f.Value {
+			current = i
+			break
+		}
+	}
+	f.Value = choices[(current+step+len(choices))%len(choices)]
+}
+
+// Form is a minimal multi-field form driven by key messages
+type Form struct {
+	Fields []FormField
+	Focus  int
+
+	// Schema is the object schema a form built by NewSchemaForm validates against
+	Schema *mcp.Schema
+}
+
+// HandleKey edits the focused field or moves focus; it reports whether the key was consumed
+func (f *Form) HandleKey(msg tea.KeyMsg) bool {
+	if len(f.Fields) == 0 {
+		return false
+	}
+	field := &f.Fields[f.Focus]
+
+	switch msg.Type {
+	case tea.KeyTab, tea.KeyDown:
+		f.Focus = (f.Focus + 1) % len(f.Fields)
+	case tea.KeyShiftTab, tea.KeyUp:
+		f.Focus = (f.Focus - 1 + len(f.Fields)) % len(f.Fields)
+	case tea.KeySpace:
+		if field.IsChoice() {
+			field.cycle(1)
+		} else {
+			field.Value += " "
+		}
+	case tea.KeyRight, tea.KeyLeft:
+		if !field.IsChoice() {
+			return false
+		}
+		if msg.Type == tea.KeyRight {
+			field.cycle(1)
+		} else {
+			field.cycle(-1)
+		}
+	case tea.KeyBackspace, tea.KeyCtrlU:
+		if field.IsChoice() {
+			if !field.Required {
+				field.Value = ""
+			}
+			return true
+		}
+		if msg.Type == tea.KeyCtrlU {
+			field.Value = ""
+		} else if runes := []rune(field.Value); len(runes) > 0 {
+			field.Value = string(runes[:len(runes)-1])
+		}
+	case tea.KeyRunes:
+		if !field.IsChoice() {
+			field.Value += string(msg.Runes)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// Values returns the non-empty field values keyed by field name
+func (f *Form) Values() map[string]string {
+	values := make(map[string]string)
+	for _, field := range f.Fields {
+		if field.Value != "" {
+			values[field.Name] = field.Value
+		}
+	}
+	return values
+}
+
+// Missing returns the labels of required fields that are still empty
+func (f *Form) Missing() []string {
+	var missing []string
+	for _, field := range f.Fields {
+		if field.Required && strings.TrimSpace(field.Value) == "" {
+			missing = append(missing, field.Label)
+		}
+	}
+	return missing
+}
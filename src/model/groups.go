@@ -0,0 +1,134 @@
+package model
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"mcop/src/logs"
+)
+
+// UngroupedName heads the servers without a group when other servers have one
+const UngroupedName = "Other"
+
+// UseProfile limits the session to the servers a configured profile
+// selects; an empty name selects every server. It is meant to be called
+// before the program starts.
+func (m *AppModel) UseProfile(name string) error {
+	if m.Config == nil {
+		return nil
+	}
+	selected, err := m.Config.ProfileServers(name)
+	if err != nil {
+		return err
+	}
+	servers := make([]MCPServer, len(selected))
+	for i, cfgServer := range selected {
+		servers[i] = newServer(cfgServer)
+	}
+	m.profile = name
+	m.State.Servers = groupServers(servers)
+	m.State.SelectedIndex = 0
+	m.configureSupervisor()
+	return nil
+}
+
+// Profile returns the name of the profile in use, or "" for all servers
+func (m *AppModel) Profile() string {
+	return m.profile
+}
+
+// groupServers orders servers so that each group is listed together, groups
+// in the order their first server appears and servers without a group last
+func groupServers(servers []MCPServer) []MCPServer {
+	var order []string
+	members := make(map[string][]MCPServer)
+	for _, server := range servers {
+		if _, seen := members[server.Group]; !seen && server.Group != "" {
+			order = append(order, server.Group)
+		}
+		members[server.Group] = append(members[server.Group], server)
+	}
+	grouped := make([]MCPServer, 0, len(servers))
+	for _, group := range append(order, "") {
+		grouped = append(grouped, members[group]...)
+	}
+	return grouped
+}
+
+// HasGroups reports whether any listed server belongs to a group
+func (m *AppModel) HasGroups() bool {
+	for _, server := range m.State.Servers {
+		if server.Group != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// GroupName returns the heading of a group
+func GroupName(group string) string {
+	if group == "" {
+		return UngroupedName
+	}
+	return group
+}
+
+// StartGroup starts the servers of a group that are not running. They
+// connect in the background and show as connecting until they are up.
+func (m *AppModel) StartGroup(group string) tea.Cmd {
+	var cmds []tea.Cmd
+	for i := range m.State.Servers {
+		server := &m.State.Servers[i]
+		if server.Group != group || server.IsRunning() || server.Status == "connecting" {
+			continue
+		}
+		// A manual start overrides any restart the supervisor scheduled
+		m.supervisor.Cancel(server.ID)
+		server.Status = "connecting"
+		if cmd := m.launchServer(server.ID, false); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+	}
+	if len(cmds) > 0 {
+		m.Log("", logs.LevelInfo, "Starting %d servers of group '%s'", len(cmds), GroupName(group))
+	}
+	return tea.Batch(cmds...)
+}
+
+// StopGroup stops the running and connecting servers of a group
+func (m *AppModel) StopGroup(group string) {
+	stopped := 0
+	for i := range m.State.Servers {
+		server := &m.State.Servers[i]
+		if server.Group != group {
+			continue
+		}
+		switch {
+		case server.IsRunning():
+			m.DisconnectServer(i)
+		case server.Status == "connecting":
+			// The pending connection is dropped when it completes
+			server.Status = "stopped"
+		default:
+			continue
+		}
+		stopped++
+	}
+	if stopped > 0 {
+		m.Log("", logs.LevelInfo, "Stopped %d servers of group '%s'", stopped, GroupName(group))
+	}
+}
+
+// ToggleGroup starts the group of the server at index if any of its servers
+// is stopped, and stops the whole group otherwise
+func (m *AppModel) ToggleGroup(index int) tea.Cmd {
+	if index >= len(m.State.Servers) {
+		return nil
+	}
+	group := m.State.Servers[index].Group
+	for _, server := range m.State.Servers {
+		if server.Group == group && !server.IsRunning() && server.Status != "connecting" {
+			return m.StartGroup(group)
+		}
+	}
+	m.StopGroup(group)
+	return nil
+}
@@ -0,0 +1,210 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"mcop/src/logs"
+	"mcop/src/mcp"
+)
+
+// logCapacity is the number of log entries kept per server
+const logCapacity = 1000
+
+// LogViewLines is the number of entries shown at once in the logs view
+const LogViewLines = 20
+
+// LogViewer holds the state of the logs view
+type LogViewer struct {
+	// ServerID restricts the view to one server; empty shows every server
+	ServerID string
+	MinLevel logs.Level
+	// Follow keeps the newest entries in view; Offset is the first visible
+	// entry while not following
+	Follow       bool
+	Offset       int
+	PreviousView string
+}
+
+// Filter returns the log filter selected in the view
+func (v *LogViewer) Filter() logs.Filter {
+	return logs.Filter{ServerID: v.ServerID, MinLevel: v.MinLevel}
+}
+
+// LogsUpdatedMsg is sent when new log entries have been recorded
+type LogsUpdatedMsg struct{}
+
+// Log records an operational message; serverID may be empty for messages
+// that are not about a particular server
+func (m *AppModel) Log(serverID string, level logs.Level, format string, args ...interface{}) {
+	m.Logs.Append(logs.Entry{
+		ServerID: serverID,
+		Source:   logs.SourceMCOP,
+		Level:    level,
+		Text:     fmt.Sprintf(format, args...),
+	})
+}
+
+// notifyLogs signals the update loop that entries were appended, coalescing bursts
+func (m *AppModel) notifyLogs() {
+	select {
+	case m.logsChanged <- struct{}{}:
+	default:
+	}
+}
+
+// listenForLogs waits for the next batch of log entries
+func (m *AppModel) listenForLogs() tea.Cmd {
+	return func() tea.Msg {
+		<-m.logsChanged
+		return LogsUpdatedMsg{}
+	}
+}
+
+// VisibleLogs returns the entries that pass the logs view's filter
+func (m *AppModel) VisibleLogs() []logs.Entry {
+	return m.Logs.Entries(m.State.Logs.Filter())
+}
+
+// OpenLogs switches to the logs view, limited to serverID unless it is empty
+func (m *AppModel) OpenLogs(serverID string) {
+	previous := m.State.View
+	if previous == "logs" {
+		previous = m.State.Logs.PreviousView
+	}
+	m.State.Logs = LogViewer{
+		ServerID:     serverID,
+		MinLevel:     m.State.Logs.MinLevel,
+		Follow:       true,
+		PreviousView: previous,
+	}
+	m.State.View = "logs"
+}
+
+// cycleLogServer moves the server filter through all servers and back to none
+func (m *AppModel) cycleLogServer(step int) {
+	ids := []string{""}
+	current := 0
+	for _, server := range m.State.Servers {
+		if server.ID == m.State.Logs.ServerID {
+			current = len(ids)
+		}
+		ids = append(ids, server.ID)
+	}
+	m.State.Logs.ServerID = ids[(current+step+len(ids))%len(ids)]
+	m.State.Logs.Follow = true
+}
+
+// cycleLogLevel changes the minimum severity shown and, when viewing a single
+// server that supports logging, asks it to send logs from that level on
+func (m *AppModel) cycleLogLevel(step int) tea.Cmd {
+	viewer := &m.State.Logs
+	count := int(logs.LevelEmergency) + 1
+	viewer.MinLevel = logs.Level((int(viewer.MinLevel) + step + count) % count)
+	viewer.Follow = true
+
+	client, exists := m.State.MCPConnections[viewer.ServerID]
+	if !exists || !client.IsConnected() || client.ServerCapabilities().Logging == nil {
+		return nil
+	}
+	serverID, level := viewer.ServerID, viewer.MinLevel
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), listTimeout)
+		defer cancel()
+		if err := client.SetLoggingLevel(ctx, level.String()); err != nil {
+			m.Log(serverID, logs.LevelWarning, "failed to set log level to %s: %v", level, err)
+		}
+		return nil
+	}
+}
+
+// scrollLogs moves the view by delta entries, resuming follow at the bottom
+func (m *AppModel) scrollLogs(delta int) {
+	viewer := &m.State.Logs
+	last := len(m.VisibleLogs()) - LogViewLines
+	if last < 0 {
+		last = 0
+	}
+	if viewer.Follow {
+		viewer.Offset = last
+	}
+	viewer.Offset += delta
+	if viewer.Offset < 0 {
+		viewer.Offset = 0
+	}
+	viewer.Follow = viewer.Offset >= last
+	if viewer.Follow {
+		viewer.Offset = last
+	}
+}
+
+// handleLogsKey handles keys while the logs view is active
+func (m *AppModel) handleLogsKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	viewer := &m.State.Logs
+	switch msg.String() {
+	case "up", "k":
+		m.scrollLogs(-1)
+	case "down", "j":
+		m.scrollLogs(1)
+	case "pgup":
+		m.scrollLogs(-LogViewLines)
+	case "pgdown":
+		m.scrollLogs(LogViewLines)
+	case "home", "g":
+		viewer.Follow = false
+		viewer.Offset = 0
+	case "end", "G":
+		viewer.Follow = true
+	case "f":
+		if viewer.Follow {
+			m.scrollLogs(0)
+			viewer.Follow = false
+		} else {
+			viewer.Follow = true
+		}
+	case "tab":
+		m.cycleLogServer(1)
+	case "shift+tab":
+		m.cycleLogServer(-1)
+	case "v":
+		return m.cycleLogLevel(1), true
+	case "V":
+		return m.cycleLogLevel(-1), true
+	case "esc":
+		m.State.View = viewer.PreviousView
+		if m.State.View == "" {
+			m.State.View = "list"
+		}
+	default:
+		return nil, false
+	}
+	return nil, true
+}
+
+// updateLogs re-arms the log listener; ok is false for other messages
+func (m *AppModel) updateLogs(msg tea.Msg) (tea.Cmd, bool) {
+	if _, ok := msg.(LogsUpdatedMsg); ok {
+		return m.listenForLogs(), true
+	}
+	return nil, false
+}
+
+// watchLogs records the server's notifications/message log notifications
+func (m *AppModel) watchLogs(serverID string, client *mcp.MCPClient) {
+	client.OnNotification("notifications/message", func(n *mcp.MCPNotification) {
+		var params mcp.LoggingMessageParams
+		if err := json.Unmarshal(n.Params, &params); err != nil {
+			return
+		}
+		level, _ := logs.ParseLevel(params.Level)
+		m.Logs.Append(logs.Entry{
+			ServerID: serverID,
+			Source:   logs.SourceMCP,
+			Level:    level,
+			Logger:   params.Logger,
+			Text:     params.Text(),
+		})
+	})
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"mcop/src/types"
+)
+
+// eventBufferSize bounds the number of background events waiting for the update loop
+const eventBufferSize = 64
+
+// listTimeout bounds background */list requests
+const listTimeout = 30 * time.Second
+
+// ToolsChangedMsg is sent when a server reports that its tool list changed
+type ToolsChangedMsg struct {
+	ServerID string
+}
+
+// ToolsLoadedMsg carries the result of fetching a server's tool list
+type ToolsLoadedMsg struct {
+	ServerID string
+	Tools    []types.Tool
+	Err      error
+}
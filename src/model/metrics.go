@@ -0,0 +1,78 @@
+package model
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"mcop/src/mcp"
+	"mcop/src/types"
+)
+
+// MetricsSampledMsg carries process metrics read in the background, keyed by server ID
+type MetricsSampledMsg struct {
+	Metrics map[string]types.ProcessMetrics
+}
+
+// serverPID returns the process ID of a server running as a local process, or 0
+func (m *AppModel) serverPID(serverID string) int {
+	client, exists := m.State.MCPConnections[serverID]
+	if !exists {
+		return 0
+	}
+	stdio, ok := client.Transport().(*mcp.StdioTransport)
+	if !ok || stdio.Process() == nil {
+		return 0
+	}
+	return stdio.Process().Pid
+}
+
+// RefreshMetrics samples the process trees of all local servers in the background
+func (m *AppModel) RefreshMetrics() tea.Cmd {
+	pids := make(map[string]int)
+	for _, server := range m.State.Servers {
+		if pid := m.serverPID(server.ID); pid != 0 {
+			pids[server.ID] = pid
+		}
+	}
+	if len(pids) == 0 {
+		return func() tea.Msg {
+			return MetricsSampledMsg{}
+		}
+	}
+
+	sampler := m.sampler
+	return func() tea.Msg {
+		now := time.Now()
+		metrics := make(map[string]types.ProcessMetrics, len(pids))
+		for serverID, pid := range pids {
+			if sample, err := sampler.Sample(serverID, pid, now); err == nil {
+				metrics[serverID] = sample
+			}
+		}
+		return MetricsSampledMsg{Metrics: metrics}
+	}
+}
+
+// updateMetrics applies sampled metrics; ok is false for other messages
+func (m *AppModel) updateMetrics(msg tea.Msg) (tea.Cmd, bool) {
+	if msg, ok := msg.(MetricsSampledMsg); ok {
+		for i := range m.State.Servers {
+			server := &m.State.Servers[i]
+			if metrics, ok := msg.Metrics[server.ID]; ok && m.serverPID(server.ID) == metrics.PID {
+				server.Process = metrics
+			} else if m.serverPID(server.ID) == 0 {
+				// Servers whose read failed keep their last reading until the
+				// next sample, unless their process is gone
+				m.clearProcess(server)
+			}
+		}
+		return nil, true
+	}
+	return nil, false
+}
+
+// clearProcess drops the metrics of a server whose process is gone
+func (m *AppModel) clearProcess(server *MCPServer) {
+	server.Process = types.ProcessMetrics{}
+	m.sampler.Forget(server.ID)
+}
@@ -0,0 +1,242 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"mcop/src/mcp"
+)
+
+// PromptBrowser holds the state of the prompts view for one server
+type PromptBrowser struct {
+	ServerID      string
+	Prompts       []mcp.Prompt
+	SelectedIndex int
+	Editing       bool
+	Form          Form
+	Result        *mcp.GetPromptResult
+	ResultName    string
+	ScrollOffset  int
+	Loading       bool
+	Error         string
+}
+
+// SelectedPrompt returns the selected prompt, or nil if there are none
+func (b *PromptBrowser) SelectedPrompt() *mcp.Prompt {
+	if b.SelectedIndex < len(b.Prompts) {
+		return &b.Prompts[b.SelectedIndex]
+	}
+	return nil
+}
+
+// PromptsLoadedMsg carries the result of listing a server's prompts
+type PromptsLoadedMsg struct {
+	ServerID string
+	Prompts  []mcp.Prompt
+	Err      error
+}
+
+// PromptRenderedMsg carries the result of prompts/get
+type PromptRenderedMsg struct {
+	ServerID string
+	Name     string
+	Result   *mcp.GetPromptResult
+	Err      error
+}
+
+// PromptsChangedMsg is sent when a server reports that its prompt list changed
+type PromptsChangedMsg struct {
+	ServerID string
+}
+
+// OpenPrompts switches to the prompts view for the server at index
+func (m *AppModel) OpenPrompts(index int) tea.Cmd {
+	if index >= len(m.State.Servers) {
+		return nil
+	}
+	server := m.State.Servers[index]
+
+	m.State.View = "prompts"
+	m.State.Prompts = PromptBrowser{ServerID: server.ID}
+
+	client, exists := m.State.MCPConnections[server.ID]
+	if !exists || !client.IsConnected() {
+		m.State.Prompts.Error = "server is not connected; start it with 's' first"
+		return nil
+	}
+	if client.ServerCapabilities().Prompts == nil {
+		m.State.Prompts.Error = "server does not offer prompts"
+		return nil
+	}
+	return m.loadPrompts(server.ID)
+}
+
+// loadPrompts lists a server's prompts in the background
+func (m *AppModel) loadPrompts(serverID string) tea.Cmd {
+	client, exists := m.State.MCPConnections[serverID]
+	if !exists {
+		return nil
+	}
+	m.State.Prompts.Loading = true
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), listTimeout)
+		defer cancel()
+		prompts, err := client.ListPrompts(ctx)
+		return PromptsLoadedMsg{ServerID: serverID, Prompts: prompts, Err: err}
+	}
+}
+
+// renderPrompt calls prompts/get in the background
+func (m *AppModel) renderPrompt(serverID, name string, arguments map[string]string) tea.Cmd {
+	client, exists := m.State.MCPConnections[serverID]
+	if !exists {
+		return nil
+	}
+	m.State.Prompts.Loading = true
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), listTimeout)
+		defer cancel()
+		result, err := client.GetPrompt(ctx, name, arguments)
+		return PromptRenderedMsg{ServerID: serverID, Name: name, Result: result, Err: err}
+	}
+}
+
+// selectPrompt opens the argument form for the selected prompt, or renders it
+// straight away when it takes no arguments
+func (m *AppModel) selectPrompt() tea.Cmd {
+	browser := &m.State.Prompts
+	prompt := browser.SelectedPrompt()
+	if prompt == nil {
+		return nil
+	}
+	if len(prompt.Arguments) == 0 {
+		return m.renderPrompt(browser.ServerID, prompt.Name, nil)
+	}
+
+	fields := make([]FormField, len(prompt.Arguments))
+	for i, arg := range prompt.Arguments {
+		fields[i] = FormField{
+			Name:        arg.Name,
+			Label:       arg.DisplayName(),
+			Description: arg.Description,
+			Required:    arg.Required,
+		}
+	}
+	browser.Form = Form{Fields: fields}
+	browser.Editing = true
+	browser.Error = ""
+	return nil
+}
+
+// submitPromptForm validates the argument form and renders the prompt
+func (m *AppModel) submitPromptForm() tea.Cmd {
+	browser := &m.State.Prompts
+	prompt := browser.SelectedPrompt()
+	if prompt == nil {
+		return nil
+	}
+	if missing := browser.Form.Missing(); len(missing) > 0 {
+		browser.Error = fmt.Sprintf("required: %s", strings.Join(missing, ", "))
+		return nil
+	}
+	browser.Editing = false
+	browser.Error = ""
+	return m.renderPrompt(browser.ServerID, prompt.Name, browser.Form.Values())
+}
+
+// handlePromptsKey handles keys while the prompts view is active
+func (m *AppModel) handlePromptsKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	browser := &m.State.Prompts
+
+	if browser.Editing {
+		switch msg.String() {
+		case "enter":
+			return m.submitPromptForm(), true
+		case "esc":
+			browser.Editing = false
+			browser.Error = ""
+			return nil, true
+		}
+		return nil, browser.Form.HandleKey(msg)
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if browser.SelectedIndex > 0 {
+			browser.SelectedIndex--
+		}
+	case "down", "j":
+		if browser.SelectedIndex < len(browser.Prompts)-1 {
+			browser.SelectedIndex++
+		}
+	case "pgup":
+		browser.ScrollOffset -= 10
+		if browser.ScrollOffset < 0 {
+			browser.ScrollOffset = 0
+		}
+	case "pgdown":
+		browser.ScrollOffset += 10
+	case "enter":
+		return m.selectPrompt(), true
+	case "r":
+		return m.loadPrompts(browser.ServerID), true
+	case "esc":
+		m.State.View = "detail"
+	default:
+		return nil, false
+	}
+	return nil, true
+}
+
+// updatePrompts applies prompt related messages; ok is false for other messages
+func (m *AppModel) updatePrompts(msg tea.Msg) (cmd tea.Cmd, ok bool) {
+	browser := &m.State.Prompts
+	switch msg := msg.(type) {
+	case PromptsLoadedMsg:
+		if msg.ServerID != browser.ServerID {
+			return nil, true
+		}
+		browser.Loading = false
+		if msg.Err != nil {
+			browser.Error = fmt.Sprintf("failed to list prompts: %v", msg.Err)
+			return nil, true
+		}
+		browser.Error = ""
+		browser.Prompts = msg.Prompts
+		if browser.SelectedIndex >= len(browser.Prompts) {
+			browser.SelectedIndex = 0
+		}
+	case PromptRenderedMsg:
+		if msg.ServerID != browser.ServerID {
+			return nil, true
+		}
+		browser.Loading = false
+		if msg.Err != nil {
+			browser.Error = fmt.Sprintf("failed to get prompt %s: %v", msg.Name, msg.Err)
+			return nil, true
+		}
+		browser.Error = ""
+		browser.Result = msg.Result
+		browser.ResultName = msg.Name
+		browser.ScrollOffset = 0
+	case PromptsChangedMsg:
+		if m.State.View == "prompts" && msg.ServerID == browser.ServerID {
+			cmd = m.loadPrompts(msg.ServerID)
+		}
+		return tea.Batch(cmd, m.listenForEvents()), true
+	default:
+		return nil, false
+	}
+	return nil, true
+}
+
+// watchPrompts routes prompt notifications from client into the update loop
+func (m *AppModel) watchPrompts(serverID string, client *mcp.MCPClient) {
+	client.OnNotification("notifications/prompts/list_changed", func(*mcp.MCPNotification) {
+		m.emit(PromptsChangedMsg{ServerID: serverID})
+	})
+}
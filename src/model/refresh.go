@@ -0,0 +1,113 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"mcop/src/logs"
+	"mcop/src/mcp"
+)
+
+// defaultRefreshRate is used when the configuration has no refresh rate
+const defaultRefreshRate = 5 * time.Second
+
+// maxPingTimeout bounds how long a health check waits for a ping response
+const maxPingTimeout = 5 * time.Second
+
+// refreshTickMsg fires when the periodic refresh is due
+type refreshTickMsg struct{}
+
+// PingResultMsg carries the outcome of a health check ping
+type PingResultMsg struct {
+	ServerID string
+	Client   *mcp.MCPClient
+	RTT      time.Duration
+	Err      error
+}
+
+// refreshInterval returns the configured refresh rate
+func (m *AppModel) refreshInterval() time.Duration {
+	if m.State.RefreshRate <= 0 {
+		return defaultRefreshRate
+	}
+	return time.Duration(m.State.RefreshRate) * time.Second
+}
+
+// pingTimeout returns how long a ping may take before the server counts as unresponsive
+func (m *AppModel) pingTimeout() time.Duration {
+	if timeout := m.refreshInterval(); timeout < maxPingTimeout {
+		return timeout
+	}
+	return maxPingTimeout
+}
+
+// scheduleRefresh waits one refresh interval before the next refresh, unless
+// auto-refresh is turned off
+func (m *AppModel) scheduleRefresh() tea.Cmd {
+	if !m.State.AutoRefresh {
+		return nil
+	}
+	return tea.Tick(m.refreshInterval(), func(time.Time) tea.Msg {
+		return refreshTickMsg{}
+	})
+}
+
+// Refresh pings every connected server and samples process metrics, all in
+// the background
+func (m *AppModel) Refresh() tea.Cmd {
+	cmds := []tea.Cmd{m.RefreshMetrics()}
+	for _, server := range m.State.Servers {
+		cmds = append(cmds, m.pingServer(server.ID))
+	}
+	return tea.Batch(cmds...)
+}
+
+// pingServer measures the round-trip time of a connected server. A server
+// whose previous ping is still outstanding is skipped, so slow servers do
+// not accumulate requests.
+func (m *AppModel) pingServer(serverID string) tea.Cmd {
+	client, exists := m.State.MCPConnections[serverID]
+	if !exists || !client.IsConnected() || m.pinging[serverID] {
+		return nil
+	}
+	m.pinging[serverID] = true
+	timeout := m.pingTimeout()
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		rtt, err := client.Ping(ctx)
+		return PingResultMsg{ServerID: serverID, Client: client, RTT: rtt, Err: err}
+	}
+}
+
+// updateRefresh drives the refresh loop and applies health check results;
+// ok is false for other messages
+func (m *AppModel) updateRefresh(msg tea.Msg) (tea.Cmd, bool) {
+	switch msg := msg.(type) {
+	case refreshTickMsg:
+		return tea.Batch(m.Refresh(), m.scheduleRefresh()), true
+	case PingResultMsg:
+		delete(m.pinging, msg.ServerID)
+		server := m.serverByID(msg.ServerID)
+		// Results for clients that were stopped or replaced meanwhile are stale
+		if server == nil || m.State.MCPConnections[msg.ServerID] != msg.Client || !server.IsRunning() {
+			return nil, true
+		}
+		if msg.Err != nil {
+			if server.Status != "degraded" {
+				m.Log(server.ID, logs.LevelWarning, "Server '%s' is not responding: %v", server.Name, msg.Err)
+			}
+			server.Status = "degraded"
+			return nil, true
+		}
+		server.ResponseTime = msg.RTT
+		if server.Status == "degraded" {
+			m.Log(server.ID, logs.LevelInfo, "Server '%s' is responding again", server.Name)
+			server.Status = "running"
+		}
+		return nil, true
+	}
+	return nil, false
+}
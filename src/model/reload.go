@@ -0,0 +1,320 @@
+package model
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fsnotify/fsnotify"
+	"mcop/src/config"
+	"mcop/src/logs"
+)
+
+// reloadDelay lets a burst of writes to a configuration file settle before
+// it is reloaded, since editors often save in several steps
+const reloadDelay = 250 * time.Millisecond
+
+// stopTimeout is how long a removed server's process gets to exit after its
+// stdin is closed, and again after SIGTERM
+const stopTimeout = 2 * time.Second
+
+// ConfigChangedMsg is sent when a configuration file changed on disk
+type ConfigChangedMsg struct{}
+
+// WatchConfig reloads the configuration whenever one of the files it was
+// loaded from changes. Directories rather than files are watched, so that
+// files replaced by a rename and files created later are noticed too.
+func (m *AppModel) WatchConfig() error {
+	if m.Config == nil {
+		return nil
+	}
+	files := make(map[string]bool)
+	for _, layer := range m.Config.Layers() {
+		files[absPath(layer.Path)] = true
+	}
+	if path := m.Config.Path(); path != "" {
+		files[absPath(path)] = true
+	}
+	if len(files) == 0 {
+		return nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to watch configuration: %w", err)
+	}
+	watched := 0
+	for path := range files {
+		if err := watcher.Add(filepath.Dir(path)); err == nil {
+			watched++
+		}
+	}
+	if watched == 0 {
+		watcher.Close()
+		return nil
+	}
+
+	go func() {
+		var timer *time.Timer
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if !files[absPath(event.Name)] || event.Op == fsnotify.Chmod {
+					continue
+				}
+				if timer == nil {
+					timer = time.AfterFunc(reloadDelay, func() { m.emit(ConfigChangedMsg{}) })
+				} else {
+					timer.Reset(reloadDelay)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				m.Log("", logs.LevelWarning, "Watching the configuration failed: %v", err)
+			}
+		}
+	}()
+	return nil
+}
+
+// absPath returns path made absolute, or path itself if that fails
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// updateReload applies configuration changes and restart prompts; ok is
+// false for other messages
+func (m *AppModel) updateReload(msg tea.Msg) (tea.Cmd, bool) {
+	if _, ok := msg.(ConfigChangedMsg); ok {
+		return tea.Batch(m.ReloadConfig(), m.listenForEvents()), true
+	}
+	return nil, false
+}
+
+// ReloadConfig loads the configuration again and applies it to the running
+// session, keeping to the servers of the profile in use. New servers are
+// added stopped and removed servers are shut down. While a file the
+// configuration was loaded from is missing, the configuration is kept.
+// Servers that are not running take changed launch settings immediately;
+// running and connecting ones are listed in State.PendingRestart until
+// RestartPending or DismissPending is called.
+func (m *AppModel) ReloadConfig() tea.Cmd {
+	// A file that is gone, or not back yet after an editor or git replaced
+	// it, would otherwise reload as the built-in defaults
+	if missing := m.missingLayers(); len(missing) > 0 {
+		m.Log("", logs.LevelWarning, "Keeping the current configuration: %s no longer exists", strings.Join(missing, ", "))
+		return nil
+	}
+
+	cfg, err := config.LoadConfig(m.configPath)
+	if err != nil {
+		m.Log("", logs.LevelError, "Failed to reload configuration: %v", err)
+		return nil
+	}
+
+	selected, err := cfg.ProfileServers(m.profile)
+	if err != nil {
+		m.Log("", logs.LevelError, "Failed to reload configuration: %v", err)
+		return nil
+	}
+
+	var added, removed, changed []string
+	configured := make(map[string]bool, len(selected))
+	for _, cfgServer := range selected {
+		configured[cfgServer.ID] = true
+		updated := newServer(cfgServer)
+		server := m.serverByID(cfgServer.ID)
+		if server == nil {
+			m.State.Servers = append(m.State.Servers, updated)
+			added = append(added, updated.Name)
+			continue
+		}
+
+		server.Name = updated.Name
+		server.Description = updated.Description
+		server.Group, server.Tags = updated.Group, updated.Tags
+		if server.SameLaunch(updated) {
+			continue
+		}
+		server.Transport, server.Command, server.Args = updated.Transport, updated.Command, updated.Args
+		server.Env, server.Cwd = updated.Env, updated.Cwd
+		server.URL, server.Headers = updated.URL, updated.Headers
+		changed = append(changed, server.Name)
+		// A connecting server was launched with the old settings too
+		if (server.IsRunning() || server.Status == "connecting") && !m.restartPending(server.ID) {
+			m.State.PendingRestart = append(m.State.PendingRestart, server.ID)
+		}
+	}
+
+	var selectedID string
+	if m.State.SelectedIndex < len(m.State.Servers) {
+		selectedID = m.State.Servers[m.State.SelectedIndex].ID
+	}
+	servers := m.State.Servers[:0]
+	for _, server := range m.State.Servers {
+		if configured[server.ID] {
+			servers = append(servers, server)
+			continue
+		}
+		m.removeServer(server)
+		removed = append(removed, server.Name)
+	}
+	m.State.Servers = groupServers(servers)
+	for i, server := range m.State.Servers {
+		if server.ID == selectedID {
+			m.State.SelectedIndex = i
+		}
+	}
+	if m.State.SelectedIndex >= len(m.State.Servers) && m.State.SelectedIndex > 0 {
+		m.State.SelectedIndex = len(m.State.Servers) - 1
+	}
+
+	m.Config = cfg
+	m.configureSupervisor()
+	var cmd tea.Cmd
+	if cfg.AutoRefresh && !m.State.AutoRefresh {
+		m.State.AutoRefresh = true
+		cmd = m.scheduleRefresh()
+	}
+	m.State.AutoRefresh = cfg.AutoRefresh
+	m.State.RefreshRate = cfg.RefreshRate
+
+	if len(added)+len(removed)+len(changed) == 0 {
+		m.Log("", logs.LevelInfo, "Configuration reloaded")
+	} else {
+		m.Log("", logs.LevelInfo, "Configuration reloaded: %s", describeChanges(added, removed, changed))
+	}
+	return cmd
+}
+
+// missingLayers returns the files the configuration was loaded from that no
+// longer exist
+func (m *AppModel) missingLayers() []string {
+	if m.Config == nil {
+		return nil
+	}
+	var missing []string
+	for _, layer := range m.Config.Layers() {
+		if _, err := os.Stat(layer.Path); os.IsNotExist(err) {
+			missing = append(missing, layer.Path)
+		}
+	}
+	return missing
+}
+
+// describeChanges summarizes the servers a reload added, removed and changed
+func describeChanges(added, removed, changed []string) string {
+	var parts []string
+	if len(added) > 0 {
+		parts = append(parts, "added "+strings.Join(added, ", "))
+	}
+	if len(removed) > 0 {
+		parts = append(parts, "removed "+strings.Join(removed, ", "))
+	}
+	if len(changed) > 0 {
+		parts = append(parts, "changed "+strings.Join(changed, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// removeServer forgets a server that left the configuration, shutting down
+// its process in the background
+func (m *AppModel) removeServer(server MCPServer) {
+	m.supervisor.Cancel(server.ID)
+	m.sampler.Forget(server.ID)
+	m.dropPending(server.ID)
+	client, exists := m.State.MCPConnections[server.ID]
+	if !exists || client == nil {
+		return
+	}
+	delete(m.State.MCPConnections, server.ID)
+	go func() {
+		if err := client.Shutdown(stopTimeout); err != nil {
+			m.Log(server.ID, logs.LevelWarning, "Stopping removed server '%s': %v", server.Name, err)
+		}
+	}()
+	m.Log(server.ID, logs.LevelInfo, "Stopping server '%s', which was removed from the configuration", server.Name)
+}
+
+// restartPending reports whether a server is waiting to be restarted
+func (m *AppModel) restartPending(serverID string) bool {
+	for _, id := range m.State.PendingRestart {
+		if id == serverID {
+			return true
+		}
+	}
+	return false
+}
+
+// dropPending removes a server from the servers waiting to be restarted
+func (m *AppModel) dropPending(serverID string) {
+	pending := m.State.PendingRestart[:0]
+	for _, id := range m.State.PendingRestart {
+		if id != serverID {
+			pending = append(pending, id)
+		}
+	}
+	m.State.PendingRestart = pending
+}
+
+// RestartPending restarts the running servers whose launch settings
+// changed, and launches the connecting ones again
+func (m *AppModel) RestartPending() tea.Cmd {
+	var cmds []tea.Cmd
+	for _, serverID := range m.State.PendingRestart {
+		server := m.serverByID(serverID)
+		if server != nil && server.Status == "connecting" {
+			// The launch in flight is superseded and its result dropped
+			m.Log(serverID, logs.LevelInfo, "Starting server '%s' again with its new settings", server.Name)
+			cmds = append(cmds, m.launchServer(serverID, false))
+			continue
+		}
+		if server == nil || !server.IsRunning() {
+			continue
+		}
+		m.supervisor.Cancel(serverID)
+		if client, exists := m.State.MCPConnections[serverID]; exists && client != nil {
+			client.Disconnect()
+			delete(m.State.MCPConnections, serverID)
+		}
+		server.Status = "stopped"
+		server.ActiveConnections = 0
+		m.clearProcess(server)
+		m.Log(serverID, logs.LevelInfo, "Restarting server '%s' with its new settings", server.Name)
+		cmds = append(cmds, m.startServer(serverID))
+	}
+	m.State.PendingRestart = nil
+	return tea.Batch(cmds...)
+}
+
+// DismissPending keeps the changed servers running with their old settings;
+// they use the new ones the next time they start
+func (m *AppModel) DismissPending() {
+	for _, serverID := range m.State.PendingRestart {
+		if server := m.serverByID(serverID); server != nil {
+			m.Log(serverID, logs.LevelInfo, "Server '%s' keeps its old settings until it is restarted", server.Name)
+		}
+	}
+	m.State.PendingRestart = nil
+}
+
+// PendingRestartNames returns the names of the servers waiting to be restarted
+func (m *AppModel) PendingRestartNames() []string {
+	var names []string
+	for _, serverID := range m.State.PendingRestart {
+		if server := m.serverByID(serverID); server != nil {
+			names = append(names, server.Name)
+		}
+	}
+	return names
+}
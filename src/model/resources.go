@@ -0,0 +1,280 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"mcop/src/mcp"
+)
+
+// ResourceBrowser holds the state of the resources view for one server
+type ResourceBrowser struct {
+	ServerID      string
+	Resources     []mcp.Resource
+	Templates     []mcp.ResourceTemplate
+	SelectedIndex int
+	ContentsURI   string
+	Contents      []mcp.ResourceContents
+	Subscribed    map[string]bool
+	ScrollOffset  int
+	Loading       bool
+	Error         string
+}
+
+// ItemCount returns the number of selectable rows: resources followed by templates
+func (b *ResourceBrowser) ItemCount() int {
+	return len(b.Resources) + len(b.Templates)
+}
+
+// SelectedResource returns the selected resource, or nil if a template is selected
+func (b *ResourceBrowser) SelectedResource() *mcp.Resource {
+	if b.SelectedIndex < len(b.Resources) {
+		return &b.Resources[b.SelectedIndex]
+	}
+	return nil
+}
+
+// SelectedTemplate returns the selected template, or nil if a resource is selected
+func (b *ResourceBrowser) SelectedTemplate() *mcp.ResourceTemplate {
+	i := b.SelectedIndex - len(b.Resources)
+	if i >= 0 && i < len(b.Templates) {
+		return &b.Templates[i]
+	}
+	return nil
+}
+
+// ResourcesLoadedMsg carries the result of listing a server's resources and templates
+type ResourcesLoadedMsg struct {
+	ServerID  string
+	Resources []mcp.Resource
+	Templates []mcp.ResourceTemplate
+	Err       error
+}
+
+// ResourceReadMsg carries the contents of a resource
+type ResourceReadMsg struct {
+	ServerID string
+	URI      string
+	Contents []mcp.ResourceContents
+	Err      error
+}
+
+// ResourceSubscriptionMsg reports the outcome of a subscribe or unsubscribe request
+type ResourceSubscriptionMsg struct {
+	ServerID   string
+	URI        string
+	Subscribed bool
+	Err        error
+}
+
+// ResourcesChangedMsg is sent when a server reports that its resource list changed
+type ResourcesChangedMsg struct {
+	ServerID string
+}
+
+// ResourceUpdatedMsg is sent when a subscribed resource changes on the server
+type ResourceUpdatedMsg struct {
+	ServerID string
+	URI      string
+}
+
+// OpenResources switches to the resources view for the server at index
+func (m *AppModel) OpenResources(index int) tea.Cmd {
+	if index >= len(m.State.Servers) {
+		return nil
+	}
+	server := m.State.Servers[index]
+
+	m.State.View = "resources"
+	m.State.Resources = ResourceBrowser{
+		ServerID:   server.ID,
+		Subscribed: make(map[string]bool),
+	}
+
+	client, exists := m.State.MCPConnections[server.ID]
+	if !exists || !client.IsConnected() {
+		m.State.Resources.Error = "server is not connected; start it with 's' first"
+		return nil
+	}
+	if client.ServerCapabilities().Resources == nil {
+		m.State.Resources.Error = "server does not offer resources"
+		return nil
+	}
+	return m.loadResources(server.ID)
+}
+
+// loadResources lists a server's resources and templates in the background
+func (m *AppModel) loadResources(serverID string) tea.Cmd {
+	client, exists := m.State.MCPConnections[serverID]
+	if !exists {
+		return nil
+	}
+	m.State.Resources.Loading = true
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), listTimeout)
+		defer cancel()
+
+		resources, err := client.ListResources(ctx)
+		if err != nil {
+			return ResourcesLoadedMsg{ServerID: serverID, Err: err}
+		}
+		// Templates are optional; servers without any may not implement the method
+		templates, _ := client.ListResourceTemplates(ctx)
+		return ResourcesLoadedMsg{ServerID: serverID, Resources: resources, Templates: templates}
+	}
+}
+
+// readResource fetches the contents of a resource in the background
+func (m *AppModel) readResource(serverID, uri string) tea.Cmd {
+	client, exists := m.State.MCPConnections[serverID]
+	if !exists {
+		return nil
+	}
+	m.State.Resources.Loading = true
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), listTimeout)
+		defer cancel()
+		contents, err := client.ReadResource(ctx, uri)
+		return ResourceReadMsg{ServerID: serverID, URI: uri, Contents: contents, Err: err}
+	}
+}
+
+// toggleSubscription subscribes to or unsubscribes from the selected resource
+func (m *AppModel) toggleSubscription() tea.Cmd {
+	browser := &m.State.Resources
+	resource := browser.SelectedResource()
+	client, exists := m.State.MCPConnections[browser.ServerID]
+	if resource == nil || !exists {
+		return nil
+	}
+	if caps := client.ServerCapabilities().Resources; caps == nil || !caps.Subscribe {
+		browser.Error = "server does not support resource subscriptions"
+		return nil
+	}
+
+	serverID, uri := browser.ServerID, resource.URI
+	subscribe := !browser.Subscribed[uri]
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), listTimeout)
+		defer cancel()
+
+		var err error
+		if subscribe {
+			err = client.SubscribeResource(ctx, uri)
+		} else {
+			err = client.UnsubscribeResource(ctx, uri)
+		}
+		return ResourceSubscriptionMsg{ServerID: serverID, URI: uri, Subscribed: subscribe, Err: err}
+	}
+}
+
+// handleResourcesKey handles keys while the resources view is active
+func (m *AppModel) handleResourcesKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	browser := &m.State.Resources
+	switch msg.String() {
+	case "up", "k":
+		if browser.SelectedIndex > 0 {
+			browser.SelectedIndex--
+		}
+	case "down", "j":
+		if browser.SelectedIndex < browser.ItemCount()-1 {
+			browser.SelectedIndex++
+		}
+	case "pgup":
+		browser.ScrollOffset -= 10
+		if browser.ScrollOffset < 0 {
+			browser.ScrollOffset = 0
+		}
+	case "pgdown":
+		browser.ScrollOffset += 10
+	case "enter":
+		if resource := browser.SelectedResource(); resource != nil {
+			return m.readResource(browser.ServerID, resource.URI), true
+		}
+	case "u":
+		return m.toggleSubscription(), true
+	case "r":
+		return m.loadResources(browser.ServerID), true
+	case "esc":
+		m.State.View = "detail"
+	default:
+		return nil, false
+	}
+	return nil, true
+}
+
+// updateResources applies resource related messages; ok is false for other messages
+func (m *AppModel) updateResources(msg tea.Msg) (cmd tea.Cmd, ok bool) {
+	browser := &m.State.Resources
+	switch msg := msg.(type) {
+	case ResourcesLoadedMsg:
+		if msg.ServerID != browser.ServerID {
+			return nil, true
+		}
+		browser.Loading = false
+		if msg.Err != nil {
+			browser.Error = fmt.Sprintf("failed to list resources: %v", msg.Err)
+			return nil, true
+		}
+		browser.Error = ""
+		browser.Resources = msg.Resources
+		browser.Templates = msg.Templates
+		if browser.SelectedIndex >= browser.ItemCount() {
+			browser.SelectedIndex = 0
+		}
+	case ResourceReadMsg:
+		if msg.ServerID != browser.ServerID {
+			return nil, true
+		}
+		browser.Loading = false
+		if msg.Err != nil {
+			browser.Error = fmt.Sprintf("failed to read %s: %v", msg.URI, msg.Err)
+			return nil, true
+		}
+		browser.Error = ""
+		if browser.ContentsURI != msg.URI {
+			browser.ScrollOffset = 0
+		}
+		browser.ContentsURI = msg.URI
+		browser.Contents = msg.Contents
+	case ResourceSubscriptionMsg:
+		if msg.ServerID != browser.ServerID {
+			return nil, true
+		}
+		if msg.Err != nil {
+			browser.Error = fmt.Sprintf("failed to update subscription for %s: %v", msg.URI, msg.Err)
+			return nil, true
+		}
+		browser.Subscribed[msg.URI] = msg.Subscribed
+	case ResourcesChangedMsg:
+		if m.State.View == "resources" && msg.ServerID == browser.ServerID {
+			cmd = m.loadResources(msg.ServerID)
+		}
+		return tea.Batch(cmd, m.listenForEvents()), true
+	case ResourceUpdatedMsg:
+		if m.State.View == "resources" && msg.ServerID == browser.ServerID && msg.URI == browser.ContentsURI {
+			cmd = m.readResource(msg.ServerID, msg.URI)
+		}
+		return tea.Batch(cmd, m.listenForEvents()), true
+	default:
+		return nil, false
+	}
+	return nil, true
+}
+
+// watchResources routes resource notifications from client into the update loop
+func (m *AppModel) watchResources(serverID string, client *mcp.MCPClient) {
+	client.OnNotification("notifications/resources/list_changed", func(*mcp.MCPNotification) {
+		m.emit(ResourcesChangedMsg{ServerID: serverID})
+	})
+	client.OnNotification("notifications/resources/updated", func(n *mcp.MCPNotification) {
+		var params mcp.ResourceUpdatedParams
+		if err := json.Unmarshal(n.Params, &params); err == nil {
+			m.emit(ResourceUpdatedMsg{ServerID: serverID, URI: params.URI})
+		}
+	})
+}
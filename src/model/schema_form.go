@@ -0,0 +1,238 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"mcop/src/mcp"
+)
+
+// NewSchemaForm builds a form for the properties of an object schema. Nested
+// objects with declared properties are flattened into dotted field labels;
+// everything else without a dedicated editor is entered as raw JSON.
+func NewSchemaForm(schema *mcp.Schema) Form {
+	form := Form{Schema: schema}
+	form.addSchemaFields(schema, nil, true)
+	return form
+}
+
+// addSchemaFields appends one field per leaf property of schema; required is
+// false once any enclosing object is optional
+func (f *Form) addSchemaFields(schema *mcp.Schema, path []string, required bool) {
+	for _, name := range schema.PropertyNames() {
+		prop := schema.Properties[name]
+		if prop == nil {
+			continue
+		}
+		fieldPath := append(append([]string(nil), path...), name)
+		fieldRequired := required && schema.IsRequired(name)
+
+		if prop.PrimaryType() == "object" && len(prop.Properties) > 0 {
+			f.addSchemaFields(prop, fieldPath, fieldRequired)
+			continue
+		}
+
+		description := prop.Description
+		if description == "" {
+			description = prop.Title
+		}
+		field := FormField{
+			Name:        name,
+			Label:       strings.Join(fieldPath, "."),
+			Description: description,
+			Required:    fieldRequired,
+			Kind:        schemaFieldKind(prop),
+			Path:        fieldPath,
+			Schema:      prop,
+		}
+		switch field.Kind {
+		case FieldEnum:
+			for _, option := range prop.Enum {
+				field.Options = append(field.Options, formatEnumOption(option))
+			}
+		case FieldBool:
+			field.Options = []string{"true", "false"}
+		}
+		field.Value = formatDefault(field.Kind, prop.Default)
+		f.Fields = append(f.Fields, field)
+	}
+}
+
+// schemaFieldKind picks the editor used for a property
+func schemaFieldKind(prop *mcp.Schema) FieldKind {
+	if len(prop.Enum) > 0 {
+		return FieldEnum
+	}
+	switch prop.PrimaryType() {
+	case "string":
+		return FieldText
+	case "number":
+		return FieldNumber
+	case "integer":
+		return FieldInteger
+	case "boolean":
+		return FieldBool
+	case "array":
+		if prop.Items != nil && len(prop.Items.Enum) == 0 && isScalarType(prop.Items.PrimaryType()) {
+			return FieldList
+		}
+	}
+	return FieldJSON
+}
+
+func isScalarType(t string) bool {
+	return t == "string" || t == "number" || t == "integer" || t == "boolean"
+}
+
+func formatEnumOption(option interface{}) string {
+	if s, ok := option.(string); ok {
+		return s
+	}
+	encoded, _ := json.Marshal(option)
+	return string(encoded)
+}
+
+// formatDefault renders a schema default the way the user would type it
+func formatDefault(kind FieldKind, raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil || value == nil {
+		return ""
+	}
+	switch v := value.(type) {
+	case string:
+		return v
+	case []interface{}:
+		if kind == FieldList {
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = formatEnumOption(item)
+			}
+			return strings.Join(items, ", ")
+		}
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, raw); err != nil {
+		return string(raw)
+	}
+	return compact.String()
+}
+
+// Arguments decodes the fields of a schema form into a nested arguments
+// object and validates it against the schema. Empty fields are omitted.
+func (f *Form) Arguments() (map[string]interface{}, error) {
+	args := make(map[string]interface{})
+	var problems []string
+
+	for i := range f.Fields {
+		field := &f.Fields[i]
+		if field.Path == nil || strings.TrimSpace(field.Value) == "" {
+			continue
+		}
+		value, err := field.decode()
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", field.Label, err))
+			continue
+		}
+		setPath(args, field.Path, value)
+	}
+
+	if len(problems) == 0 && f.Schema != nil {
+		problems = f.Schema.Validate(args)
+	}
+	if len(problems) > 0 {
+		return nil, errors.New(strings.Join(problems, "; "))
+	}
+	return args, nil
+}
+
+// decode converts the typed text of a field into a JSON value
+func (f *FormField) decode() (interface{}, error) {
+	switch f.Kind {
+	case FieldEnum:
+		for i, option := range f.Options {
+			if option == f.Value && f.Schema != nil && i < len(f.Schema.Enum) {
+				return f.Schema.Enum[i], nil
+			}
+		}
+		return nil, fmt.Errorf("must be one of %s", strings.Join(f.Options, ", "))
+	case FieldList:
+		text := strings.TrimSpace(f.Value)
+		if strings.HasPrefix(text, "[") {
+			return decodeJSON(text)
+		}
+		items := []interface{}{}
+		for _, part := range strings.Split(text, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			item, err := decodeScalar(f.Schema.Items.PrimaryType(), part)
+			if err != nil {
+				return nil, fmt.Errorf("item %q %v", part, err)
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case FieldJSON:
+		return decodeJSON(f.Value)
+	case FieldNumber:
+		return decodeScalar("number", strings.TrimSpace(f.Value))
+	case FieldInteger:
+		return decodeScalar("integer", strings.TrimSpace(f.Value))
+	case FieldBool:
+		return decodeScalar("boolean", f.Value)
+	}
+	return f.Value, nil
+}
+
+func decodeScalar(kind, text string) (interface{}, error) {
+	switch kind {
+	case "number":
+		n, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, errors.New("must be a number")
+		}
+		return n, nil
+	case "integer":
+		n, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return nil, errors.New("must be an integer")
+		}
+		return n, nil
+	case "boolean":
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return nil, errors.New("must be true or false")
+		}
+		return b, nil
+	}
+	return text, nil
+}
+
+func decodeJSON(text string) (interface{}, error) {
+	var value interface{}
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+	return value, nil
+}
+
+// setPath stores value in args at the nested location given by path
+func setPath(args map[string]interface{}, path []string, value interface{}) {
+	for _, name := range path[:len(path)-1] {
+		child, ok := args[name].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			args[name] = child
+		}
+		args = child
+	}
+	args[path[len(path)-1]] = value
+}
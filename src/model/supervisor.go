@@ -0,0 +1,227 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"mcop/src/config"
+	"mcop/src/logs"
+	"mcop/src/mcp"
+	"mcop/src/supervisor"
+)
+
+// ServerExitedMsg is sent when a supervised server's process exits, or when
+// the connection to a remote server ends
+type ServerExitedMsg struct {
+	ServerID string
+	Client   *mcp.MCPClient
+	Err      error
+}
+
+// RestartServerMsg fires when a scheduled restart is due
+type RestartServerMsg struct {
+	ServerID string
+}
+
+// ServerStartedMsg carries the result of starting a server in the background
+type ServerStartedMsg struct {
+	ServerID string
+	Client   *mcp.MCPClient
+	Err      error
+	// Restart is set when the supervisor or a configuration change
+	// restarted the server, rather than the user starting it
+	Restart bool
+	// Launch numbers the launch within the server's launches
+	Launch int
+}
+
+// newClient creates a client for server, resolving the references in its
+// launch settings and capturing the stderr of stdio processes
+func (m *AppModel) newClient(server MCPServer) (*mcp.MCPClient, error) {
+	resolved, err := config.ResolveServer(server)
+	if err != nil {
+		return nil, err
+	}
+	transport, err := mcp.NewTransport(resolved)
+	if err != nil {
+		return nil, err
+	}
+	if stdio, ok := transport.(*mcp.StdioTransport); ok {
+		stdio.Stderr = m.Logs.StderrWriter(server.ID)
+	}
+	// The client keeps the unresolved server, so secrets stay out of the UI
+	return mcp.NewMCPClientWithTransport(server, transport), nil
+}
+
+// superviseClient reports the end of a connected client's process, or of its
+// session for transports without a local process
+func (m *AppModel) superviseClient(serverID string, client *mcp.MCPClient) {
+	go func() {
+		var err error
+		if stdio, ok := client.Transport().(*mcp.StdioTransport); ok {
+			<-stdio.Exited()
+			err = stdio.ExitErr()
+		} else {
+			<-client.Done()
+			err = mcp.ErrConnectionClosed
+		}
+		m.exits <- ServerExitedMsg{ServerID: serverID, Client: client, Err: err}
+	}()
+}
+
+// listenForExits waits for the next supervised client to end
+func (m *AppModel) listenForExits() tea.Cmd {
+	return func() tea.Msg {
+		return <-m.exits
+	}
+}
+
+// startServer restarts a server, connecting in the background
+func (m *AppModel) startServer(serverID string) tea.Cmd {
+	return m.launchServer(serverID, true)
+}
+
+// launchServer connects to a server in the background. Failed restarts are
+// handed to the supervisor, while failed starts only mark the server failed.
+func (m *AppModel) launchServer(serverID string, restart bool) tea.Cmd {
+	server := m.serverByID(serverID)
+	if server == nil {
+		return nil
+	}
+	client, err := m.newClient(*server)
+	if err != nil {
+		if !restart {
+			m.startFailed(server, err)
+			return nil
+		}
+		return m.serverStopped(server, err, "failed to restart")
+	}
+	m.watchClient(serverID, client)
+	m.launches[serverID]++
+	launch := m.launches[serverID]
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), listTimeout)
+		defer cancel()
+		err := client.ConnectContext(ctx)
+		return ServerStartedMsg{ServerID: serverID, Client: client, Err: err, Restart: restart, Launch: launch}
+	}
+}
+
+// startFailed records that the user's attempt to start a server failed
+func (m *AppModel) startFailed(server *MCPServer, err error) {
+	server.Status = "error"
+	m.State.Error = fmt.Sprintf("failed to connect to %s: %v", server.Name, err)
+	m.Log(server.ID, logs.LevelError, "Server '%s' failed to start: %v", server.Name, err)
+}
+
+// serverStopped records that a server is no longer running and asks the
+// supervisor whether to restart it
+func (m *AppModel) serverStopped(server *MCPServer, cause error, what string) tea.Cmd {
+	server.ActiveConnections = 0
+	m.clearProcess(server)
+	failed := cause != nil
+	if failed {
+		server.Status = "error"
+	} else {
+		server.Status = "stopped"
+	}
+
+	text := fmt.Sprintf("Server '%s' %s", server.Name, what)
+	level := logs.LevelInfo
+	if failed {
+		text += ": " + describeExit(cause)
+		level = logs.LevelError
+	}
+
+	var cmd tea.Cmd
+	decision := m.supervisor.Exited(server.ID, failed, time.Now())
+	switch {
+	case decision.Restart:
+		text += fmt.Sprintf("; restarting in %s (attempt %d)", decision.Delay, decision.Attempt)
+		serverID := server.ID
+		cmd = tea.Tick(decision.Delay, func(time.Time) tea.Msg {
+			return RestartServerMsg{ServerID: serverID}
+		})
+	case decision.GaveUp:
+		text += fmt.Sprintf("; giving up after %d restarts", decision.Attempt)
+		level = logs.LevelCritical
+	}
+	m.Log(server.ID, level, "%s", text)
+	return cmd
+}
+
+// describeExit turns a wait error into a short description of how a process ended
+func describeExit(err error) string {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ProcessState.String()
+	}
+	return err.Error()
+}
+
+// updateSupervisor applies process lifecycle messages; ok is false for other messages
+func (m *AppModel) updateSupervisor(msg tea.Msg) (tea.Cmd, bool) {
+	switch msg := msg.(type) {
+	case ServerExitedMsg:
+		server := m.serverByID(msg.ServerID)
+		// Exits of clients the user already stopped or replaced are expected
+		if server == nil || m.State.MCPConnections[msg.ServerID] != msg.Client {
+			return m.listenForExits(), true
+		}
+		delete(m.State.MCPConnections, msg.ServerID)
+		msg.Client.Disconnect()
+		return tea.Batch(m.serverStopped(server, msg.Err, "exited"), m.listenForExits()), true
+	case RestartServerMsg:
+		server := m.serverByID(msg.ServerID)
+		if server == nil || !m.supervisor.TakeRestart(msg.ServerID) || server.IsRunning() {
+			return nil, true
+		}
+		return m.startServer(msg.ServerID), true
+	case ServerStartedMsg:
+		server := m.serverByID(msg.ServerID)
+		if server == nil || msg.Launch != m.launches[msg.ServerID] || server.IsRunning() ||
+			(!msg.Restart && server.Status != "connecting") {
+			// The user started or stopped the server, or it was launched
+			// again, while it was connecting
+			msg.Client.Disconnect()
+			return nil, true
+		}
+		if msg.Err != nil {
+			msg.Client.Disconnect()
+			if !msg.Restart {
+				m.startFailed(server, msg.Err)
+				return nil, true
+			}
+			return m.serverStopped(server, msg.Err, "failed to restart"), true
+		}
+		m.State.MCPConnections[msg.ServerID] = msg.Client
+		server.Status = "running"
+		server.StartTime = time.Now()
+		server.ActiveConnections = 1
+		m.superviseClient(msg.ServerID, msg.Client)
+		if msg.Restart {
+			m.Log(msg.ServerID, logs.LevelInfo, "Server '%s' restarted", server.Name)
+		} else {
+			m.State.Error = ""
+			m.supervisor.Reset(msg.ServerID)
+			m.Log(msg.ServerID, logs.LevelInfo, "Started server: %s", server.Name)
+		}
+		return tea.Batch(m.RefreshTools(msg.ServerID), m.RefreshMetrics()), true
+	}
+	return nil, false
+}
+
+// configureSupervisor applies each server's restart settings from the configuration
+func (m *AppModel) configureSupervisor() {
+	if m.Config == nil {
+		return
+	}
+	for _, server := range m.State.Servers {
+		m.supervisor.Configure(server.ID, supervisor.ConfigFor(m.Config.GetServerConfig(server.ID)))
+	}
+}
@@ -0,0 +1,209 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"mcop/src/mcp"
+	"mcop/src/types"
+)
+
+// toolCallTimeout bounds tools/call requests started from the TUI
+const toolCallTimeout = 5 * time.Minute
+
+// ToolRunner holds the state of the tools view for one server
+type ToolRunner struct {
+	ServerID      string
+	SelectedIndex int
+	Editing       bool
+	Form          Form
+	Result        *mcp.CallToolResult
+	ResultTool    string
+	Elapsed       time.Duration
+	ScrollOffset  int
+	Loading       bool
+	Error         string
+}
+
+// ToolCalledMsg carries the result of tools/call
+type ToolCalledMsg struct {
+	ServerID string
+	Name     string
+	Result   *mcp.CallToolResult
+	Elapsed  time.Duration
+	Err      error
+}
+
+// RunnerTools returns the tools of the server shown in the tools view
+func (m *AppModel) RunnerTools() []types.Tool {
+	if server := m.serverByID(m.State.Tools.ServerID); server != nil {
+		return server.Tools
+	}
+	return nil
+}
+
+// SelectedTool returns the tool selected in the tools view, or nil if there are none
+func (m *AppModel) SelectedTool() *types.Tool {
+	tools := m.RunnerTools()
+	if m.State.Tools.SelectedIndex < len(tools) {
+		return &tools[m.State.Tools.SelectedIndex]
+	}
+	return nil
+}
+
+// OpenTools switches to the tools view for the server at index
+func (m *AppModel) OpenTools(index int) tea.Cmd {
+	if index >= len(m.State.Servers) {
+		return nil
+	}
+	server := m.State.Servers[index]
+
+	m.State.View = "tools"
+	m.State.Tools = ToolRunner{ServerID: server.ID}
+
+	client, exists := m.State.MCPConnections[server.ID]
+	if !exists || !client.IsConnected() {
+		m.State.Tools.Error = "server is not connected; start it with 's' first"
+		return nil
+	}
+	if client.ServerCapabilities().Tools == nil {
+		m.State.Tools.Error = "server does not offer tools"
+	}
+	return nil
+}
+
+// selectTool opens the input form for the selected tool, or calls it straight
+// away when its schema declares no properties
+func (m *AppModel) selectTool() tea.Cmd {
+	runner := &m.State.Tools
+	tool := m.SelectedTool()
+	if tool == nil {
+		return nil
+	}
+
+	schema, err := mcp.ParseSchema(tool.InputSchema)
+	if err != nil {
+		runner.Error = fmt.Sprintf("tool %s has an invalid input schema: %v", tool.Name, err)
+		return nil
+	}
+	form := NewSchemaForm(schema)
+	if len(form.Fields) == 0 {
+		return m.callTool(runner.ServerID, tool.Name, map[string]interface{}{})
+	}
+
+	runner.Form = form
+	runner.Editing = true
+	runner.Error = ""
+	return nil
+}
+
+// submitToolForm validates the input form and calls the selected tool
+func (m *AppModel) submitToolForm() tea.Cmd {
+	runner := &m.State.Tools
+	tool := m.SelectedTool()
+	if tool == nil {
+		return nil
+	}
+	args, err := runner.Form.Arguments()
+	if err != nil {
+		runner.Error = err.Error()
+		return nil
+	}
+	runner.Editing = false
+	runner.Error = ""
+	return m.callTool(runner.ServerID, tool.Name, args)
+}
+
+// callTool calls tools/call in the background
+func (m *AppModel) callTool(serverID, name string, args map[string]interface{}) tea.Cmd {
+	client, exists := m.State.MCPConnections[serverID]
+	if !exists {
+		return nil
+	}
+	m.State.Tools.Loading = true
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), toolCallTimeout)
+		defer cancel()
+		start := time.Now()
+		result, err := client.CallTool(ctx, name, args)
+		return ToolCalledMsg{ServerID: serverID, Name: name, Result: result, Elapsed: time.Since(start), Err: err}
+	}
+}
+
+// handleToolsKey handles keys while the tools view is active
+func (m *AppModel) handleToolsKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	runner := &m.State.Tools
+
+	if runner.Editing {
+		switch msg.String() {
+		case "enter":
+			return m.submitToolForm(), true
+		case "esc":
+			runner.Editing = false
+			runner.Error = ""
+			return nil, true
+		}
+		return nil, runner.Form.HandleKey(msg)
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if runner.SelectedIndex > 0 {
+			runner.SelectedIndex--
+		}
+	case "down", "j":
+		if runner.SelectedIndex < len(m.RunnerTools())-1 {
+			runner.SelectedIndex++
+		}
+	case "pgup":
+		runner.ScrollOffset -= 10
+		if runner.ScrollOffset < 0 {
+			runner.ScrollOffset = 0
+		}
+	case "pgdown":
+		runner.ScrollOffset += 10
+	case "enter":
+		return m.selectTool(), true
+	case "r":
+		return m.RefreshTools(runner.ServerID), true
+	case "esc":
+		m.State.View = "detail"
+	default:
+		return nil, false
+	}
+	return nil, true
+}
+
+// updateTools applies tool call results; ok is false for other messages
+func (m *AppModel) updateTools(msg tea.Msg) (tea.Cmd, bool) {
+	runner := &m.State.Tools
+	switch msg := msg.(type) {
+	case ToolCalledMsg:
+		if msg.ServerID != runner.ServerID {
+			return nil, true
+		}
+		runner.Loading = false
+		if msg.Err != nil {
+			runner.Error = fmt.Sprintf("failed to call %s: %v", msg.Name, msg.Err)
+			return nil, true
+		}
+		runner.Error = ""
+		runner.Result = msg.Result
+		runner.ResultTool = msg.Name
+		runner.Elapsed = msg.Elapsed
+		runner.ScrollOffset = 0
+	case ToolsLoadedMsg:
+		// Keep the selection in range when the tool list shrinks; the list
+		// itself is stored by AppModel.Update
+		if msg.ServerID == runner.ServerID && runner.SelectedIndex >= len(msg.Tools) {
+			runner.SelectedIndex = 0
+		}
+		return nil, false
+	default:
+		return nil, false
+	}
+	return nil, true
+}
@@ -0,0 +1,183 @@
+package model
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"mcop/src/mcp"
+)
+
+// frameCapacity is the number of frames kept per server for the inspector
+const frameCapacity = 2000
+
+// InspectorRows is the number of frames listed at once in the traffic view
+const InspectorRows = 12
+
+// TrafficInspector holds the state of the protocol traffic view
+type TrafficInspector struct {
+	ServerID string
+	// SelectedIndex indexes the filtered frames; Follow keeps the newest selected
+	SelectedIndex int
+	Follow        bool
+	// MethodFilter keeps frames whose method contains it, ignoring case
+	MethodFilter  string
+	EditingFilter bool
+	ErrorsOnly    bool
+	ScrollOffset  int
+	PreviousView  string
+}
+
+// Match reports whether a frame passes the inspector's filters
+func (i *TrafficInspector) Match(frame mcp.Frame) bool {
+	if i.ErrorsOnly && !frame.IsError {
+		return false
+	}
+	return i.MethodFilter == "" || strings.Contains(strings.ToLower(frame.Method), strings.ToLower(i.MethodFilter))
+}
+
+// TrafficUpdatedMsg is sent when frames have been recorded
+type TrafficUpdatedMsg struct{}
+
+// watchTraffic records the frames exchanged with a server
+func (m *AppModel) watchTraffic(serverID string, client *mcp.MCPClient) {
+	buffer, exists := m.frames[serverID]
+	if !exists {
+		buffer = mcp.NewFrameBuffer(frameCapacity)
+		m.frames[serverID] = buffer
+	}
+	client.OnFrame(func(frame mcp.Frame) {
+		buffer.Add(frame)
+		select {
+		case m.trafficChanged <- struct{}{}:
+		default:
+		}
+	})
+}
+
+// listenForTraffic waits for the next batch of frames
+func (m *AppModel) listenForTraffic() tea.Cmd {
+	return func() tea.Msg {
+		<-m.trafficChanged
+		return TrafficUpdatedMsg{}
+	}
+}
+
+// InspectedFrames returns the frames of the inspected server that pass the filters
+func (m *AppModel) InspectedFrames() []mcp.Frame {
+	inspector := &m.State.Traffic
+	buffer, exists := m.frames[inspector.ServerID]
+	if !exists {
+		return nil
+	}
+	var frames []mcp.Frame
+	for _, frame := range buffer.Frames() {
+		if inspector.Match(frame) {
+			frames = append(frames, frame)
+		}
+	}
+	return frames
+}
+
+// SelectedFrame returns the frame selected in the traffic view
+func (m *AppModel) SelectedFrame() (mcp.Frame, bool) {
+	frames := m.InspectedFrames()
+	if len(frames) == 0 {
+		return mcp.Frame{}, false
+	}
+	index := m.State.Traffic.SelectedIndex
+	if m.State.Traffic.Follow || index >= len(frames) {
+		index = len(frames) - 1
+	}
+	return frames[index], true
+}
+
+// OpenTraffic switches to the traffic view of the server at index
+func (m *AppModel) OpenTraffic(index int) {
+	if index >= len(m.State.Servers) {
+		return
+	}
+	m.State.Traffic = TrafficInspector{
+		ServerID:     m.State.Servers[index].ID,
+		Follow:       true,
+		PreviousView: m.State.View,
+	}
+	m.State.View = "traffic"
+}
+
+// moveFrameSelection moves the selection by delta frames, following the
+// newest frame again once the end is reached
+func (m *AppModel) moveFrameSelection(delta int) {
+	inspector := &m.State.Traffic
+	count := len(m.InspectedFrames())
+	if inspector.Follow {
+		inspector.SelectedIndex = count - 1
+	}
+	inspector.SelectedIndex += delta
+	if inspector.SelectedIndex < 0 {
+		inspector.SelectedIndex = 0
+	}
+	inspector.Follow = inspector.SelectedIndex >= count-1
+	inspector.ScrollOffset = 0
+}
+
+// handleTrafficKey handles keys while the traffic view is active
+func (m *AppModel) handleTrafficKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	inspector := &m.State.Traffic
+	if inspector.EditingFilter {
+		switch msg.Type {
+		case tea.KeyEnter, tea.KeyEsc:
+			inspector.EditingFilter = false
+		case tea.KeyBackspace:
+			if runes := []rune(inspector.MethodFilter); len(runes) > 0 {
+				inspector.MethodFilter = string(runes[:len(runes)-1])
+			}
+		case tea.KeyCtrlU:
+			inspector.MethodFilter = ""
+		case tea.KeyRunes, tea.KeySpace:
+			inspector.MethodFilter += string(msg.Runes)
+		}
+		inspector.Follow = true
+		return nil, true
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		m.moveFrameSelection(-1)
+	case "down", "j":
+		m.moveFrameSelection(1)
+	case "home", "g":
+		inspector.Follow = false
+		inspector.SelectedIndex = 0
+		inspector.ScrollOffset = 0
+	case "end", "G":
+		inspector.Follow = true
+		inspector.ScrollOffset = 0
+	case "pgup":
+		if inspector.ScrollOffset > 0 {
+			inspector.ScrollOffset--
+		}
+	case "pgdown":
+		inspector.ScrollOffset++
+	case "/":
+		inspector.EditingFilter = true
+	case "e":
+		inspector.ErrorsOnly = !inspector.ErrorsOnly
+		inspector.Follow = true
+	case "esc":
+		m.State.View = inspector.PreviousView
+		if m.State.View == "" {
+			m.State.View = "list"
+		}
+	default:
+		return nil, false
+	}
+	return nil, true
+}
+
+// updateTraffic re-arms the traffic listener; ok is false for other messages
+func (m *AppModel) updateTraffic(msg tea.Msg) (tea.Cmd, bool) {
+	if _, ok := msg.(TrafficUpdatedMsg); ok {
+		return m.listenForTraffic(), true
+	}
+	return nil, false
+}
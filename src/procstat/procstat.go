@@ -0,0 +1,227 @@
+package procstat
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"mcop/src/types"
+)
+
+// procRoot is where the proc filesystem is mounted
+const procRoot = "/proc"
+
+// clockTicks is the kernel's USER_HZ, the unit of CPU times in /proc/<pid>/stat.
+// It is 100 on every mainstream Linux architecture.
+const clockTicks = 100
+
+// Usage is the resource usage of a process tree at one point in time
+type Usage struct {
+	PID       int
+	CPUTicks  uint64 // user plus system time of every process in the tree
+	RSS       int64  // resident memory in bytes
+	Threads   int
+	FDs       int
+	Processes int
+}
+
+// procInfo holds the fields read from a single process
+type procInfo struct {
+	ppid     int
+	cpuTicks uint64
+	rss      int64
+	threads  int
+	fds      int
+}
+
+// Tree returns the combined usage of pid and all of its descendants, since
+// launchers like npx or go run keep the real server in a grandchild. It
+// reads the proc filesystem and so only works on Linux.
+func Tree(pid int) (Usage, error) {
+	root, err := readProc(pid)
+	if err != nil {
+		return Usage{}, err
+	}
+	usage := Usage{PID: pid}
+	usage.add(root)
+
+	children := childrenByParent()
+	queue := children[pid]
+	for len(queue) > 0 {
+		child := queue[0]
+		queue = append(queue[1:], children[child]...)
+		// Processes may exit between listing and reading them
+		if info, err := readProc(child); err == nil {
+			usage.add(info)
+		}
+	}
+	return usage, nil
+}
+
+func (u *Usage) add(info procInfo) {
+	u.CPUTicks += info.cpuTicks
+	u.RSS += info.rss
+	u.Threads += info.threads
+	u.FDs += info.fds
+	u.Processes++
+}
+
+// childrenByParent maps each process ID to the IDs of its children
+func childrenByParent() map[int][]int {
+	children := make(map[int][]int)
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return children
+	}
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		ppid, _, err := readStat(pid)
+		if err != nil {
+			continue
+		}
+		children[ppid] = append(children[ppid], pid)
+	}
+	return children
+}
+
+// readProc reads the usage of a single process
+func readProc(pid int) (procInfo, error) {
+	ppid, ticks, err := readStat(pid)
+	if err != nil {
+		return procInfo{}, err
+	}
+	info := procInfo{ppid: ppid, cpuTicks: ticks}
+	if err := readStatus(pid, &info); err != nil {
+		return procInfo{}, err
+	}
+	// Descriptors of processes owned by other users cannot be listed
+	if fds, err := os.ReadDir(filepath.Join(procRoot, strconv.Itoa(pid), "fd")); err == nil {
+		info.fds = len(fds)
+	}
+	return info, nil
+}
+
+// readStat returns the parent ID and the user plus system CPU ticks from /proc/<pid>/stat
+func readStat(pid int) (int, uint64, error) {
+	data, err := os.ReadFile(filepath.Join(procRoot, strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return 0, 0, err
+	}
+	// The command name is parenthesized and may itself contain spaces or
+	// parentheses, so fields are counted from the last closing parenthesis
+	end := strings.LastIndexByte(string(data), ')')
+	if end < 0 {
+		return 0, 0, fmt.Errorf("malformed stat for pid %d", pid)
+	}
+	// fields[0] is the state, field 3 of stat(5)
+	fields := strings.Fields(string(data[end+1:]))
+	if len(fields) < 13 {
+		return 0, 0, fmt.Errorf("malformed stat for pid %d", pid)
+	}
+	ppid, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("malformed stat for pid %d: %w", pid, err)
+	}
+	utime, err := strconv.ParseUint(fields[11], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("malformed stat for pid %d: %w", pid, err)
+	}
+	stime, err := strconv.ParseUint(fields[12], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("malformed stat for pid %d: %w", pid, err)
+	}
+	return ppid, utime + stime, nil
+}
+
+// readStatus fills the resident memory and thread count from /proc/<pid>/status
+func readStatus(pid int, info *procInfo) error {
+	f, err := os.Open(filepath.Join(procRoot, strconv.Itoa(pid), "status"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+		switch key {
+		case "VmRSS":
+			// Reported in kB; kernel threads and zombies have no VmRSS line
+			kb, _ := strconv.ParseInt(fields[0], 10, 64)
+			info.rss = kb * 1024
+		case "Threads":
+			info.threads, _ = strconv.Atoi(fields[0])
+		}
+	}
+	return scanner.Err()
+}
+
+// Sampler turns successive usage readings into metrics, computing CPU
+// percentages from the CPU time used between two samples of a server.
+// It is safe for concurrent use.
+type Sampler struct {
+	mu       sync.Mutex
+	previous map[string]sample
+}
+
+// sample is a reading remembered for the next CPU percentage
+type sample struct {
+	pid   int
+	ticks uint64
+	at    time.Time
+}
+
+// NewSampler creates a sampler with no previous readings
+func NewSampler() *Sampler {
+	return &Sampler{previous: make(map[string]sample)}
+}
+
+// Sample reads the process tree rooted at pid for serverID. CPUPercent is
+// relative to one core and is zero on the first sample of a process.
+func (s *Sampler) Sample(serverID string, pid int, now time.Time) (types.ProcessMetrics, error) {
+	usage, err := Tree(pid)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		delete(s.previous, serverID)
+		return types.ProcessMetrics{}, err
+	}
+	metrics := types.ProcessMetrics{
+		PID:       usage.PID,
+		RSS:       usage.RSS,
+		Threads:   usage.Threads,
+		FDs:       usage.FDs,
+		Processes: usage.Processes,
+	}
+	if prev, ok := s.previous[serverID]; ok && prev.pid == pid && usage.CPUTicks >= prev.ticks {
+		if elapsed := now.Sub(prev.at).Seconds(); elapsed > 0 {
+			cpuSeconds := float64(usage.CPUTicks-prev.ticks) / clockTicks
+			metrics.CPUPercent = cpuSeconds / elapsed * 100
+		}
+	}
+	s.previous[serverID] = sample{pid: pid, ticks: usage.CPUTicks, at: now}
+	return metrics, nil
+}
+
+// Forget drops the previous reading of a server whose process has stopped
+func (s *Sampler) Forget(serverID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.previous, serverID)
+}
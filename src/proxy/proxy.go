@@ -0,0 +1,285 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+
+	"mcop/src/mcp"
+)
+
+// maxFrameSize bounds a single newline-delimited frame read from the server or a TCP client
+const maxFrameSize = 16 << 20
+
+// subscriberBuffer is the number of frames queued for a slow subscriber before frames are dropped
+const subscriberBuffer = 256
+
+// Bridge shares one stdio MCP server with network clients. Frames written by
+// clients go to the server's stdin; frames read from its stdout are routed to
+// the HTTP request waiting for them, or broadcast to the connected streams.
+type Bridge struct {
+	in      io.Writer
+	writeMu sync.Mutex
+
+	mu          sync.Mutex
+	pending     map[mcp.RequestID]chan []byte
+	subscribers map[chan []byte]struct{}
+}
+
+// NewBridge creates a bridge that writes client frames to in
+func NewBridge(in io.Writer) *Bridge {
+	return &Bridge{
+		in:          in,
+		pending:     make(map[mcp.RequestID]chan []byte),
+		subscribers: make(map[chan []byte]struct{}),
+	}
+}
+
+// Run routes frames read from the server's stdout until it is closed
+func (b *Bridge) Run(out io.Reader) error {
+	scanner := bufio.NewScanner(out)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxFrameSize)
+	for scanner.Scan() {
+		frame := append([]byte(nil), scanner.Bytes()...)
+		if len(bytes.TrimSpace(frame)) > 0 {
+			b.route(frame)
+		}
+	}
+	return scanner.Err()
+}
+
+// route hands a response to the HTTP request that sent it, and everything else to all subscribers
+func (b *Bridge) route(frame []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if msg, err := mcp.ParseMessage(frame); err == nil {
+		if resp, ok := msg.(*mcp.MCPResponse); ok {
+			if ch, ok := b.pending[resp.ID]; ok {
+				delete(b.pending, resp.ID)
+				ch <- frame
+				return
+			}
+		}
+	}
+	for ch := range b.subscribers {
+		select {
+		case ch <- frame:
+		default:
+		}
+	}
+}
+
+// send writes a frame to the server as a single line
+func (b *Bridge) send(frame []byte) error {
+	var line bytes.Buffer
+	if err := json.Compact(&line, frame); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	line.WriteByte('\n')
+
+	b.writeMu.Lock()
+	defer b.writeMu.Unlock()
+	_, err := b.in.Write(line.Bytes())
+	return err
+}
+
+func (b *Bridge) subscribe() chan []byte {
+	ch := make(chan []byte, subscriberBuffer)
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+	return ch
+}
+
+func (b *Bridge) unsubscribe(ch chan []byte) {
+	b.mu.Lock()
+	delete(b.subscribers, ch)
+	b.mu.Unlock()
+}
+
+// expect registers interest in the response to id
+func (b *Bridge) expect(id mcp.RequestID) chan []byte {
+	ch := make(chan []byte, 1)
+	b.mu.Lock()
+	b.pending[id] = ch
+	b.mu.Unlock()
+	return ch
+}
+
+func (b *Bridge) forget(id mcp.RequestID) {
+	b.mu.Lock()
+	delete(b.pending, id)
+	b.mu.Unlock()
+}
+
+// ServeTCP accepts connections on ln and speaks newline-delimited JSON-RPC
+// with each of them until ln is closed
+func (b *Bridge) ServeTCP(ln net.Listener) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+		go b.serveConn(conn)
+	}
+}
+
+// serveConn forwards frames between one TCP client and the server
+func (b *Bridge) serveConn(conn net.Conn) {
+	defer conn.Close()
+	frames := b.subscribe()
+	defer b.unsubscribe(frames)
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case frame := <-frames:
+				if _, err := conn.Write(append(frame, '\n')); err != nil {
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxFrameSize)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		if err := b.send(scanner.Bytes()); err != nil {
+			return
+		}
+	}
+}
+
+// ServeHTTP implements the Streamable HTTP transport: POST delivers frames
+// and answers with the matching responses, GET opens an SSE stream carrying
+// server notifications and requests
+func (b *Bridge) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		b.handlePost(w, r)
+	case http.MethodGet:
+		b.handleStream(w, r)
+	case http.MethodDelete:
+		w.WriteHeader(http.StatusOK)
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (b *Bridge) handlePost(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxFrameSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	messages, err := mcp.ParseMessages(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Register before sending so a fast reply cannot be broadcast instead
+	var ids []mcp.RequestID
+	var replies []chan []byte
+	for _, msg := range messages {
+		if req, ok := msg.(*mcp.MCPRequest); ok {
+			ids = append(ids, req.ID)
+			replies = append(replies, b.expect(req.ID))
+		}
+	}
+	defer func() {
+		for _, id := range ids {
+			b.forget(id)
+		}
+	}()
+
+	if err := b.send(body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	if len(replies) == 0 {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	responses := make([]json.RawMessage, len(replies))
+	for i, ch := range replies {
+		select {
+		case frame := <-ch:
+			responses[i] = frame
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if bytes.HasPrefix(bytes.TrimSpace(body), []byte("[")) {
+		json.NewEncoder(w).Encode(responses)
+		return
+	}
+	w.Write(responses[0])
+}
+
+func (b *Bridge) handleStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	frames := b.subscribe()
+	defer b.unsubscribe(frames)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case frame := <-frames:
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", frame)
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// Serve exposes the bridge on ln until ctx is cancelled. The scheme selects
+// the protocol: "tcp" for raw newline-delimited JSON-RPC, "http" for
+// Streamable HTTP at any path.
+func (b *Bridge) Serve(ctx context.Context, scheme string, ln net.Listener) error {
+	var serve func() error
+	switch scheme {
+	case "tcp":
+		serve = func() error { return b.ServeTCP(ln) }
+	case "http":
+		serve = func() error { return http.Serve(ln, b) }
+	default:
+		return fmt.Errorf("unsupported listen scheme %q (expected tcp or http)", scheme)
+	}
+
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+	if err := serve(); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package stats
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// BucketWidth is the time span aggregated into one point of the history
+const BucketWidth = 10 * time.Second
+
+// HistoryBuckets is the number of buckets kept per server, ten minutes in total
+const HistoryBuckets = 60
+
+// maxBucketSamples bounds the latencies kept per bucket for percentiles;
+// beyond it, new samples overwrite old ones round-robin
+const maxBucketSamples = 1000
+
+// Summary aggregates the calls made during a time span
+type Summary struct {
+	Requests          int
+	Errors            int
+	RequestsPerMinute float64
+	ErrorRate         float64 // fraction of requests that failed, 0 to 1
+	P50, P95, P99     time.Duration
+}
+
+// bucket holds the calls that completed within one BucketWidth
+type bucket struct {
+	start     time.Time
+	requests  int
+	errors    int
+	latencies []time.Duration
+}
+
+// Series is a rolling history of calls to one server
+type Series struct {
+	buckets [HistoryBuckets]bucket
+}
+
+// bucketAt returns the bucket covering t, recycling it if it holds an older span
+func (s *Series) bucketAt(t time.Time) *bucket {
+	start := t.Truncate(BucketWidth)
+	b := &s.buckets[(start.UnixNano()/int64(BucketWidth))%HistoryBuckets]
+	if !b.start.Equal(start) {
+		*b = bucket{start: start, latencies: b.latencies[:0]}
+	}
+	return b
+}
+
+// Record adds a call that completed at the given time
+func (s *Series) Record(at time.Time, latency time.Duration, failed bool) {
+	b := s.bucketAt(at)
+	if len(b.latencies) < maxBucketSamples {
+		b.latencies = append(b.latencies, latency)
+	} else {
+		b.latencies[b.requests%maxBucketSamples] = latency
+	}
+	b.requests++
+	if failed {
+		b.errors++
+	}
+}
+
+// Summary aggregates the calls of the window ending at now
+func (s *Series) Summary(now time.Time, window time.Duration) Summary {
+	first := now.Truncate(BucketWidth).Add(-window + BucketWidth)
+	var selected []*bucket
+	for i := range s.buckets {
+		b := &s.buckets[i]
+		if !b.start.IsZero() && !b.start.Before(first) && !b.start.After(now) {
+			selected = append(selected, b)
+		}
+	}
+	return summarize(selected, window)
+}
+
+// History returns one summary per bucket for the n buckets ending at now,
+// oldest first; buckets without calls are zero
+func (s *Series) History(now time.Time, n int) []Summary {
+	if n > HistoryBuckets {
+		n = HistoryBuckets
+	}
+	history := make([]Summary, n)
+	current := now.Truncate(BucketWidth)
+	for i := range history {
+		start := current.Add(-time.Duration(n-1-i) * BucketWidth)
+		b := &s.buckets[(start.UnixNano()/int64(BucketWidth))%HistoryBuckets]
+		if b.start.Equal(start) {
+			history[i] = summarize([]*bucket{b}, BucketWidth)
+		}
+	}
+	return history
+}
+
+// summarize combines buckets spanning window into a summary
+func summarize(buckets []*bucket, window time.Duration) Summary {
+	var summary Summary
+	var latencies []time.Duration
+	for _, b := range buckets {
+		summary.Requests += b.requests
+		summary.Errors += b.errors
+		latencies = append(latencies, b.latencies...)
+	}
+	if summary.Requests == 0 {
+		return summary
+	}
+	summary.RequestsPerMinute = float64(summary.Requests) / window.Minutes()
+	summary.ErrorRate = float64(summary.Errors) / float64(summary.Requests)
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	summary.P50 = percentile(latencies, 50)
+	summary.P95 = percentile(latencies, 95)
+	summary.P99 = percentile(latencies, 99)
+	return summary
+}
+
+// percentile returns the nearest-rank percentile p of sorted latencies
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// Store keeps a series per server. It is safe for concurrent use.
+type Store struct {
+	mu     sync.Mutex
+	series map[string]*Series
+}
+
+// NewStore creates an empty store
+func NewStore() *Store {
+	return &Store{series: make(map[string]*Series)}
+}
+
+// Record adds a completed call of a server
+func (s *Store) Record(serverID string, at time.Time, latency time.Duration, failed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	series, ok := s.series[serverID]
+	if !ok {
+		series = &Series{}
+		s.series[serverID] = series
+	}
+	series.Record(at, latency, failed)
+}
+
+// Summary aggregates a server's calls of the window ending at now
+func (s *Store) Summary(serverID string, now time.Time, window time.Duration) Summary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	series, ok := s.series[serverID]
+	if !ok {
+		return Summary{}
+	}
+	return series.Summary(now, window)
+}
+
+// History returns a server's per-bucket summaries for the n buckets ending at now
+func (s *Store) History(serverID string, now time.Time, n int) []Summary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	series, ok := s.series[serverID]
+	if !ok {
+		series = &Series{}
+	}
+	return series.History(now, n)
+}
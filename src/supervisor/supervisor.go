@@ -0,0 +1,194 @@
+package supervisor
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"mcop/src/config"
+)
+
+// Policy decides whether a server is restarted after its process exits
+type Policy string
+
+const (
+	PolicyNever     Policy = "never"
+	PolicyOnFailure Policy = "on-failure"
+	PolicyAlways    Policy = "always"
+)
+
+// Defaults applied when a server's configuration leaves a setting unset
+const (
+	DefaultMaxRestarts    = 5
+	DefaultRestartWindow  = time.Minute
+	DefaultInitialBackoff = time.Second
+	DefaultMaxBackoff     = 30 * time.Second
+)
+
+// ParsePolicy validates a restart policy name; the empty string means never
+func ParsePolicy(name string) (Policy, error) {
+	switch Policy(name) {
+	case "", PolicyNever:
+		return PolicyNever, nil
+	case PolicyOnFailure, PolicyAlways:
+		return Policy(name), nil
+	}
+	return "", fmt.Errorf("unknown restart policy %q (expected never, on-failure or always)", name)
+}
+
+// Config holds the restart settings of one server
+type Config struct {
+	Policy Policy
+	// MaxRestarts is the number of restarts allowed within Window before giving up
+	MaxRestarts    int
+	Window         time.Duration
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// ConfigFor builds the restart settings of a server, applying defaults;
+// an unknown policy is treated as never
+func ConfigFor(serverConfig config.ServerConfig) Config {
+	policy, err := ParsePolicy(serverConfig.RestartPolicy)
+	if err != nil {
+		policy = PolicyNever
+	}
+	cfg := Config{
+		Policy:         policy,
+		MaxRestarts:    serverConfig.MaxRestarts,
+		Window:         time.Duration(serverConfig.RestartWindow) * time.Second,
+		InitialBackoff: time.Duration(serverConfig.RestartBackoff) * time.Millisecond,
+		MaxBackoff:     time.Duration(serverConfig.RestartMaxBackoff) * time.Millisecond,
+	}
+	if cfg.MaxRestarts <= 0 {
+		cfg.MaxRestarts = DefaultMaxRestarts
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = DefaultRestartWindow
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = DefaultInitialBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = DefaultMaxBackoff
+	}
+	if cfg.MaxBackoff < cfg.InitialBackoff {
+		cfg.MaxBackoff = cfg.InitialBackoff
+	}
+	return cfg
+}
+
+// Decision is the supervisor's verdict on an exited server
+type Decision struct {
+	// Restart is set when the server should be started again after Delay
+	Restart bool
+	Delay   time.Duration
+	// Attempt counts the restarts within the current window, including this one
+	Attempt int
+	// GaveUp is set when the policy wanted a restart but the limit was reached
+	GaveUp bool
+}
+
+// Supervisor tracks restart history for a set of servers. It only makes
+// decisions; starting and stopping processes is left to the caller.
+type Supervisor struct {
+	mu      sync.Mutex
+	servers map[string]*serverState
+}
+
+type serverState struct {
+	config   Config
+	restarts []time.Time
+	pending  bool
+}
+
+// New creates an empty supervisor
+func New() *Supervisor {
+	return &Supervisor{servers: make(map[string]*serverState)}
+}
+
+func (s *Supervisor) state(serverID string) *serverState {
+	state, ok := s.servers[serverID]
+	if !ok {
+		state = &serverState{config: ConfigFor(config.ServerConfig{})}
+		s.servers[serverID] = state
+	}
+	return state
+}
+
+// Configure sets the restart settings of a server
+func (s *Supervisor) Configure(serverID string, cfg Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state(serverID).config = cfg
+}
+
+// Exited records that a server's process ended, failed reporting a non-zero
+// exit, and decides whether to restart it. A positive decision stays pending
+// until TakeRestart or Cancel is called.
+func (s *Supervisor) Exited(serverID string, failed bool, now time.Time) Decision {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := s.state(serverID)
+
+	switch state.config.Policy {
+	case PolicyAlways:
+	case PolicyOnFailure:
+		if !failed {
+			return Decision{}
+		}
+	default:
+		return Decision{}
+	}
+
+	// Forget restarts that fell out of the window
+	recent := state.restarts[:0]
+	for _, t := range state.restarts {
+		if now.Sub(t) < state.config.Window {
+			recent = append(recent, t)
+		}
+	}
+	state.restarts = recent
+
+	if len(state.restarts) >= state.config.MaxRestarts {
+		return Decision{GaveUp: true, Attempt: len(state.restarts)}
+	}
+
+	delay := state.config.InitialBackoff
+	for i := 0; i < len(state.restarts) && delay < state.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > state.config.MaxBackoff {
+		delay = state.config.MaxBackoff
+	}
+
+	state.restarts = append(state.restarts, now)
+	state.pending = true
+	return Decision{Restart: true, Delay: delay, Attempt: len(state.restarts)}
+}
+
+// TakeRestart reports whether a restart is still pending for the server and clears it
+func (s *Supervisor) TakeRestart(serverID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := s.state(serverID)
+	pending := state.pending
+	state.pending = false
+	return pending
+}
+
+// Cancel drops a pending restart, e.g. because the user started or stopped the server
+func (s *Supervisor) Cancel(serverID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state(serverID).pending = false
+}
+
+// Reset forgets the restart history of a server
+func (s *Supervisor) Reset(serverID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := s.state(serverID)
+	state.restarts = nil
+	state.pending = false
+}
@@ -1,20 +1,193 @@
 package types
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
 	"time"
 )
 
+// Transports a server can be reached over
+const (
+	TransportStdio = "stdio"
+	TransportHTTP  = "http" // Streamable HTTP
+	TransportSSE   = "sse"  // legacy HTTP+SSE
+)
+
 // MCPServer represents an MCP server instance
 type MCPServer struct {
 	ID                string
 	Name              string
 	URL               string
-	Status            string // "running", "stopped", "error", "connecting"
+	Status            string // "running", "degraded", "stopped", "error", "connecting"
 	StartTime         time.Time
 	ResponseTime      time.Duration
 	ActiveConnections int
 	Description       string
-	Tools             []string
+	Tools             []Tool
+	Process           ProcessMetrics // zero unless the server runs as a local process
+
+	// Transport is TransportStdio, TransportHTTP or TransportSSE; when empty
+	// it is derived from Command or URL. Stdio servers run Command with Args
+	// in Cwd, with Env added to mcop's environment; remote servers are
+	// reached at URL with Headers added to every request. A stdio:// URL is
+	// shorthand for Command and Args.
+	Transport string
+	Command   string
+	Args      []string
+	Env       map[string]string
+	Cwd       string
+	Headers   map[string]string
+
+	// Group heads the server in the list; Tags are free-form labels
+	Group string
+	Tags  []string
+}
+
+// TransportType returns the transport used to reach the server, or "" when
+// it cannot be determined
+func (s MCPServer) TransportType() string {
+	switch {
+	case s.Transport != "":
+		return s.Transport
+	case s.Command != "", strings.HasPrefix(s.URL, "stdio://"):
+		return TransportStdio
+	case strings.HasPrefix(s.URL, "http://"), strings.HasPrefix(s.URL, "https://"):
+		// Legacy HTTP+SSE servers conventionally expose their event stream at /sse
+		if u, err := url.Parse(s.URL); err == nil && strings.HasSuffix(u.Path, "/sse") {
+			return TransportSSE
+		}
+		return TransportHTTP
+	}
+	return ""
+}
+
+// SameLaunch reports whether two servers are started and reached the same way
+func (s MCPServer) SameLaunch(other MCPServer) bool {
+	return s.TransportType() == other.TransportType() && s.Command == other.Command &&
+		s.URL == other.URL && s.Cwd == other.Cwd && sameStrings(s.Args, other.Args) &&
+		sameMap(s.Env, other.Env) && sameMap(s.Headers, other.Headers)
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func sameMap(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, value := range a {
+		if other, ok := b[key]; !ok || other != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Endpoint describes where the server is reached, for display
+func (s MCPServer) Endpoint() string {
+	if s.Command == "" {
+		return s.URL
+	}
+	parts := []string{s.Command}
+	for _, arg := range s.Args {
+		if arg == "" || strings.ContainsAny(arg, " \t\"'") {
+			arg = fmt.Sprintf("%q", arg)
+		}
+		parts = append(parts, arg)
+	}
+	return "stdio://" + strings.Join(parts, " ")
+}
+
+// Environ returns base with the server's Env applied on top, in a stable order
+func (s MCPServer) Environ(base []string) []string {
+	keys := make([]string, 0, len(s.Env))
+	for key := range s.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	env := append([]string(nil), base...)
+	for _, key := range keys {
+		env = append(env, key+"="+s.Env[key])
+	}
+	return env
+}
+
+// IsRunning reports whether the server is connected, including while it
+// is degraded by failing health checks
+func (s *MCPServer) IsRunning() bool {
+	return s.Status == "running" || s.Status == "degraded"
+}
+
+// ProcessMetrics describes the resource usage of a server process and its descendants
+type ProcessMetrics struct {
+	PID        int
+	CPUPercent float64 // relative to one core
+	RSS        int64   // resident memory in bytes
+	Threads    int
+	FDs        int
+	Processes  int
+}
+
+// Tool describes a tool exposed by an MCP server, as returned by tools/list
+type Tool struct {
+	Name         string           `json:"name"`
+	Title        string           `json:"title,omitempty"`
+	Description  string           `json:"description,omitempty"`
+	InputSchema  json.RawMessage  `json:"inputSchema,omitempty"`
+	OutputSchema json.RawMessage  `json:"outputSchema,omitempty"`
+	Annotations  *ToolAnnotations `json:"annotations,omitempty"`
+}
+
+// ToolAnnotations are optional hints describing a tool's behavior
+type ToolAnnotations struct {
+	Title           string `json:"title,omitempty"`
+	ReadOnlyHint    *bool  `json:"readOnlyHint,omitempty"`
+	DestructiveHint *bool  `json:"destructiveHint,omitempty"`
+	IdempotentHint  *bool  `json:"idempotentHint,omitempty"`
+	OpenWorldHint   *bool  `json:"openWorldHint,omitempty"`
+}
+
+// DisplayName returns the human readable name of the tool
+func (t Tool) DisplayName() string {
+	if t.Title != "" {
+		return t.Title
+	}
+	if t.Annotations != nil && t.Annotations.Title != "" {
+		return t.Annotations.Title
+	}
+	return t.Name
+}
+
+// ToolsFromNames builds placeholder tool definitions from a list of names,
+// used until the live list is fetched from the server
+func ToolsFromNames(names []string) []Tool {
+	tools := make([]Tool, len(names))
+	for i, name := range names {
+		tools[i] = Tool{Name: name}
+	}
+	return tools
+}
+
+// ToolNames returns the names of the given tools
+func ToolNames(tools []Tool) []string {
+	names := make([]string, len(tools))
+	for i, tool := range tools {
+		names[i] = tool.Name
+	}
+	return names
 }
 
 // Connection represents an active connection to an MCP server
@@ -3,11 +3,12 @@ package ui
 import (
 	"fmt"
 	"strings"
-	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	tea "github.com/charmbracelet/bubbletea"
+	"mcop/src/logs"
 	"mcop/src/model"
+	"mcop/src/types"
 )
 
 // AppInterface combines model and styling functionality
@@ -19,8 +20,6 @@ type AppInterface struct {
 	ShowDialog    bool
 	DialogType    string
 	DialogMessage string
-	// Log state
-	LogMessages   []string
 }
 
 // Styled components - using lipgloss for theming
@@ -58,6 +57,12 @@ var (
 	ItemStyle = lipgloss.NewStyle().
 		PaddingLeft(2)
 
+	// Group heading style in the server list
+	GroupHeaderStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("99")).
+		Bold(true).
+		MarginTop(1)
+
 	// Status running style
 	StatusRunningStyle = lipgloss.NewStyle().
 		Foreground(lipgloss.Color("46")). // Green
@@ -68,6 +73,11 @@ var (
 		Foreground(lipgloss.Color("203")). // Red
 		Padding(0, 1)
 
+	// Status degraded style, for running servers failing health checks
+	StatusDegradedStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("220")). // Yellow
+		Padding(0, 1)
+
 	// Status error style
 	StatusErrorStyle = lipgloss.NewStyle().
 		Foreground(lipgloss.Color("196")). // Bright red
@@ -97,14 +107,6 @@ var (
 		Padding(2).
 		Background(lipgloss.Color("235"))
 
-	// Log styles
-	LogStyle = lipgloss.NewStyle().
-		Border(lipgloss.NormalBorder()).
-		BorderForeground(lipgloss.Color("240")).
-		Height(8).
-		Padding(1).
-		MarginTop(1)
-
 	// Status bar style
 	StatusBarStyle = lipgloss.NewStyle().
 		Background(lipgloss.Color("240")).
@@ -114,12 +116,11 @@ var (
 )
 
 // NewAppModel creates a new instance of the styled application model
-func NewAppModel() *AppInterface {
+func NewAppModel(configPath string) *AppInterface {
 	return &AppInterface{
-		AppModel:    model.NewAppModel(),
-		Width:       80,
-		Height:      24,
-		LogMessages: []string{},
+		AppModel: model.NewAppModel(configPath),
+		Width:    80,
+		Height:   24,
 	}
 }
 
@@ -128,6 +129,11 @@ func (a *AppInterface) SetInitialServerURL(url string) {
 	a.AppModel.SetInitialServerURL(url)
 }
 
+// UseProfile limits the session to the servers of a configured profile
+func (a *AppInterface) UseProfile(name string) error {
+	return a.AppModel.UseProfile(name)
+}
+
 // View returns the styled view of the application with full layout
 func (a *AppInterface) View() string {
 	var content string
@@ -138,14 +144,20 @@ func (a *AppInterface) View() string {
 		content = a.renderServerDetail()
 	case "config":
 		content = a.renderConfigView()
+	case "resources":
+		content = a.renderResourcesView()
+	case "prompts":
+		content = a.renderPromptsView()
+	case "tools":
+		content = a.renderToolsView()
+	case "logs":
+		content = a.renderLogsView()
+	case "traffic":
+		content = a.renderTrafficView()
 	default:
 		content = a.renderServerList()
 	}
 
-	// Add log console to content
-	logContent := a.renderLogConsole()
-	content += "\n" + logContent
-
 	// Add status bar
 	statusBar := a.renderStatusBar()
 	content += "\n" + statusBar
@@ -169,28 +181,6 @@ func (a *AppInterface) renderStatusBar() string {
 	return StatusBarStyle.Render(statusText)
 }
 
-// renderLogConsole renders the log console
-func (a *AppInterface) renderLogConsole() string {
-	logContent := "Operation Logs:\n"
-
-	// Show last 5 log messages
-	startIdx := 0
-	if len(a.LogMessages) > 5 {
-		startIdx = len(a.LogMessages) - 5
-	}
-
-	for i := startIdx; i < len(a.LogMessages); i++ {
-		logContent += a.LogMessages[i] + "\n"
-	}
-
-	// If no logs, show a placeholder
-	if len(a.LogMessages) == 0 {
-		logContent += "[No recent logs]"
-	}
-
-	return LogStyle.Render(logContent)
-}
-
 // renderDialog renders the dialog box
 func (a *AppInterface) renderDialog() string {
 	dialog := a.DialogMessage
@@ -238,6 +228,14 @@ func (a *AppInterface) overlayDialog(content, dialog string) string {
 	return strings.Join(result, "\n") + "\n" + dialog
 }
 
+// firstLine returns the first line of s, used to keep long descriptions on one row
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
 // stripAnsi removes ANSI color codes from a string (simplified version)
 func stripAnsi(s string) string {
 	return s
@@ -248,7 +246,11 @@ func (a *AppInterface) renderServerList() string {
 	var sb strings.Builder
 
 	// Render title
-	title := TitleStyle.Render("MCOP - Model Context Protocol Operations Monitor")
+	titleText := "MCOP - Model Context Protocol Operations Monitor"
+	if profile := a.AppModel.Profile(); profile != "" {
+		titleText += " [profile: " + profile + "]"
+	}
+	title := TitleStyle.Render(titleText)
 	sb.WriteString(title)
 	sb.WriteString("\n\n")
 
@@ -259,13 +261,26 @@ func (a *AppInterface) renderServerList() string {
 		lipgloss.NewStyle().Width(30).Padding(0).Render("NAME"),
 		lipgloss.NewStyle().Width(12).Padding(0).Render("STATUS"),
 		lipgloss.NewStyle().Width(8).Padding(0).Render("CONNS"),
+		lipgloss.NewStyle().Width(8).Padding(0).Render("PID"),
+		lipgloss.NewStyle().Width(7).Padding(0).Render("CPU%"),
+		lipgloss.NewStyle().Width(11).Padding(0).Render("RSS"),
+		lipgloss.NewStyle().Width(5).Padding(0).Render("THR"),
+		lipgloss.NewStyle().Width(5).Padding(0).Render("FDS"),
+		lipgloss.NewStyle().Width(6).Padding(0).Render("REQ/M"),
+		lipgloss.NewStyle().Width(14).Padding(0).Render("LATENCY"),
 		"URL",
 	)
 	sb.WriteString(HeaderStyle.Render(header))
 	sb.WriteString("\n")
 
-	// Render server list
+	// Render server list, with a heading above each group
+	grouped := a.AppModel.HasGroups()
 	for i, server := range a.AppModel.State.Servers {
+		if grouped && (i == 0 || server.Group != a.AppModel.State.Servers[i-1].Group) {
+			sb.WriteString(a.renderGroupHeader(server.Group))
+			sb.WriteString("\n")
+		}
+
 		var rowStyle lipgloss.Style
 		if i == a.AppModel.State.SelectedIndex {
 			rowStyle = SelectedItemStyle
@@ -277,6 +292,8 @@ func (a *AppInterface) renderServerList() string {
 		switch server.Status {
 		case "running":
 			statusStyle = StatusRunningStyle
+		case "degraded":
+			statusStyle = StatusDegradedStyle
 		case "stopped":
 			statusStyle = StatusStoppedStyle
 		case "error":
@@ -286,7 +303,7 @@ func (a *AppInterface) renderServerList() string {
 		}
 
 		var indicator string
-		if server.Status == "running" {
+		if server.IsRunning() {
 			indicator = "●"
 		} else {
 			indicator = "○"
@@ -297,13 +314,17 @@ func (a *AppInterface) renderServerList() string {
 			name = name[:25] + "..."
 		}
 
+		requests, latency := a.trafficColumns(server.ID)
 		row := lipgloss.JoinHorizontal(
 			lipgloss.Left,
 			lipgloss.NewStyle().Width(4).Padding(0).Render(fmt.Sprintf("%d", i+1)),
 			lipgloss.NewStyle().Width(30).Padding(0).Render(name),
 			lipgloss.NewStyle().Width(12).Padding(0).Render(statusStyle.Render(server.Status)),
 			lipgloss.NewStyle().Width(8).Padding(0).Render(fmt.Sprintf("%d", server.ActiveConnections)),
-			fmt.Sprintf("%s %s", indicator, server.URL),
+			processColumns(server.Process),
+			lipgloss.NewStyle().Width(6).Padding(0).Render(requests),
+			lipgloss.NewStyle().Width(14).Padding(0).Render(latency),
+			fmt.Sprintf("%s %s", indicator, server.Endpoint()),
 		)
 
 		sb.WriteString(rowStyle.Render(row))
@@ -311,13 +332,50 @@ func (a *AppInterface) renderServerList() string {
 	}
 
 	// Add controls help
-	help := HelpStyle.Render("↑↓=Navigate | Enter=Details | S=Start/Stop | R=Refresh | C=Config | Q=Quit")
+	help := HelpStyle.Render("↑↓=Navigate | Enter=Details | S=Start/Stop | G=Start/Stop group | R=Refresh | C=Config | Q=Quit")
 	sb.WriteString("\n")
 	sb.WriteString(help)
 
 	return sb.String()
 }
 
+// renderGroupHeader renders the heading of a group with how many of its
+// servers are running
+func (a *AppInterface) renderGroupHeader(group string) string {
+	running, total := 0, 0
+	for _, server := range a.AppModel.State.Servers {
+		if server.Group != group {
+			continue
+		}
+		total++
+		if server.IsRunning() {
+			running++
+		}
+	}
+	return GroupHeaderStyle.Render(fmt.Sprintf("▾ %s (%d/%d running)", model.GroupName(group), running, total))
+}
+
+// processColumns renders the PID, CPU%, RSS, thread and FD columns of a
+// server, left blank for servers without a local process
+func processColumns(process types.ProcessMetrics) string {
+	pid, cpu, rss, threads, fds := "-", "-", "-", "-", "-"
+	if process.PID != 0 {
+		pid = fmt.Sprintf("%d", process.PID)
+		cpu = fmt.Sprintf("%.1f", process.CPUPercent)
+		rss = formatBytes(process.RSS)
+		threads = fmt.Sprintf("%d", process.Threads)
+		fds = fmt.Sprintf("%d", process.FDs)
+	}
+	return lipgloss.JoinHorizontal(
+		lipgloss.Left,
+		lipgloss.NewStyle().Width(8).Padding(0).Render(pid),
+		lipgloss.NewStyle().Width(7).Padding(0).Render(cpu),
+		lipgloss.NewStyle().Width(11).Padding(0).Render(rss),
+		lipgloss.NewStyle().Width(5).Padding(0).Render(threads),
+		lipgloss.NewStyle().Width(5).Padding(0).Render(fds),
+	)
+}
+
 // renderServerDetail renders the server detail view with styling
 func (a *AppInterface) renderServerDetail() string {
 	if a.AppModel.State.SelectedIndex >= len(a.AppModel.State.Servers) {
@@ -340,7 +398,7 @@ func (a *AppInterface) renderServerDetail() string {
 
 	sb.WriteString(DetailTitleStyle.Render("URL:"))
 	sb.WriteString("\n")
-	sb.WriteString(DetailValueStyle.Render(server.URL))
+	sb.WriteString(DetailValueStyle.Render(server.Endpoint()))
 	sb.WriteString("\n\n")
 
 	sb.WriteString(DetailTitleStyle.Render("Status:"))
@@ -349,6 +407,8 @@ func (a *AppInterface) renderServerDetail() string {
 	switch server.Status {
 	case "running":
 		statusStyle = StatusRunningStyle
+	case "degraded":
+		statusStyle = StatusDegradedStyle
 	case "stopped":
 		statusStyle = StatusStoppedStyle
 	default:
@@ -357,6 +417,31 @@ func (a *AppInterface) renderServerDetail() string {
 	sb.WriteString(DetailValueStyle.Render(statusStyle.Render(server.Status)))
 	sb.WriteString("\n\n")
 
+	if client, ok := a.AppModel.State.MCPConnections[server.ID]; ok && client.IsConnected() {
+		info := client.ServerInfo()
+		sb.WriteString(DetailTitleStyle.Render("Server Info:"))
+		sb.WriteString("\n")
+		sb.WriteString(DetailValueStyle.Render(fmt.Sprintf("%s %s (protocol %s)", info.Name, info.Version, client.ProtocolVersion())))
+		sb.WriteString("\n\n")
+
+		sb.WriteString(DetailTitleStyle.Render("Capabilities:"))
+		sb.WriteString("\n")
+		capabilities := client.ServerCapabilities().Names()
+		if len(capabilities) == 0 {
+			capabilities = []string{"none"}
+		}
+		sb.WriteString(DetailValueStyle.Render(strings.Join(capabilities, ", ")))
+		sb.WriteString("\n\n")
+	}
+
+	if process := server.Process; process.PID != 0 {
+		sb.WriteString(DetailTitleStyle.Render("Process:"))
+		sb.WriteString("\n")
+		sb.WriteString(DetailValueStyle.Render(fmt.Sprintf("PID %d (%d processes) | CPU %.1f%% | RSS %s | %d threads | %d open files",
+			process.PID, process.Processes, process.CPUPercent, formatBytes(process.RSS), process.Threads, process.FDs)))
+		sb.WriteString("\n\n")
+	}
+
 	sb.WriteString(DetailTitleStyle.Render("Start Time:"))
 	sb.WriteString("\n")
 	if !server.StartTime.IsZero() {
@@ -371,6 +456,8 @@ func (a *AppInterface) renderServerDetail() string {
 	sb.WriteString(DetailValueStyle.Render(server.ResponseTime.String()))
 	sb.WriteString("\n\n")
 
+	sb.WriteString(a.renderTraffic(server.ID))
+
 	sb.WriteString(DetailTitleStyle.Render("Active Connections:"))
 	sb.WriteString("\n")
 	sb.WriteString(DetailValueStyle.Render(fmt.Sprintf("%d", server.ActiveConnections)))
@@ -385,17 +472,21 @@ func (a *AppInterface) renderServerDetail() string {
 		sb.WriteString(DetailTitleStyle.Render("Available Tools:"))
 		sb.WriteString("\n")
 		for _, tool := range server.Tools {
-			sb.WriteString(DetailValueStyle.Render(fmt.Sprintf("  - %s", tool)))
+			line := fmt.Sprintf("  - %s", tool.DisplayName())
+			if tool.Description != "" {
+				line += ": " + firstLine(tool.Description)
+			}
+			sb.WriteString(DetailValueStyle.Render(line))
 			sb.WriteString("\n")
 		}
 	}
 
 	// Add action instructions
 	action := "start"
-	if server.Status == "running" {
+	if server.IsRunning() {
 		action = "stop"
 	}
-	help := HelpStyle.Render(fmt.Sprintf("Press 'Esc' to return, 'S' to %s, 'D' to disconnect", action))
+	help := HelpStyle.Render(fmt.Sprintf("Press 'Esc' to return, 'S' to %s, 'D' to disconnect, 'T' for tools, 'R' for resources, 'P' for prompts", action))
 	sb.WriteString("\n")
 	sb.WriteString(help)
 
@@ -450,11 +541,19 @@ func (a *AppInterface) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if newModel, ok := updatedModel.(*model.AppModel); ok {
 			a.AppModel = newModel
 		}
+		a.promptRestart()
 		return a, cmd
 	}
 
 	// Handle key messages for dialog interaction and other actions
+	var cmd tea.Cmd
 	if msg, ok := msg.(tea.KeyMsg); ok {
+		if !a.ShowDialog && a.AppModel.CapturesInput() {
+			// Forms receive every key, so shortcuts must not fire while typing
+			_, cmd = a.AppModel.Update(msg)
+			return a, cmd
+		}
+
 		if a.ShowDialog {
 			// Handle dialog keys based on dialog type
 			if a.DialogType == "download" {
@@ -470,6 +569,14 @@ func (a *AppInterface) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			} else if a.DialogType == "help" {
 				// Any key closes help dialog
 				a.ShowDialog = false
+			} else if a.DialogType == "restart" {
+				if msg.String() == "y" || msg.String() == "Y" {
+					cmd = a.AppModel.RestartPending()
+					a.ShowDialog = false
+				} else if msg.String() == "n" || msg.String() == "N" || msg.String() == "esc" {
+					a.AppModel.DismissPending()
+					a.ShowDialog = false
+				}
 			} else {
 				// Handle generic dialog keys
 				if msg.String() == "y" || msg.String() == "Y" {
@@ -497,9 +604,14 @@ func (a *AppInterface) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					"  Esc   - Return to list view\n\n" +
 					"Server Management:\n" +
 					"  S     - Start/Stop selected server\n" +
+					"  G     - Start/Stop the group of selected server\n" +
 					"  D     - Disconnect selected server\n" +
 					"  C     - Configuration view\n" +
-					"  R     - Refresh server list\n\n" +
+					"  R     - Refresh servers, or browse resources (detail view)\n" +
+					"  P     - Browse prompts (detail view)\n" +
+					"  T     - Call tools (detail view)\n" +
+					"  L     - Logs (all servers, or selected in detail view)\n" +
+					"  I     - Inspect protocol traffic of selected server\n\n" +
 					"Tools:\n" +
 					"  X     - Download/Configure MCP Servers\n" +
 					"  H     - Show this help\n" +
@@ -507,47 +619,51 @@ func (a *AppInterface) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					"Press any key to close..."
 			case "s":
 				// Handle start/stop for servers
-				if a.AppModel.State.View == "list" && a.AppModel.State.SelectedIndex < len(a.AppModel.State.Servers) {
-					server := a.AppModel.State.Servers[a.AppModel.State.SelectedIndex]
-					originalStatus := server.Status
-					a.AppModel.ToggleServer(a.AppModel.State.SelectedIndex)
-					// Add log message about the action
-					if originalStatus == "running" {
-						a.addLogMessage(fmt.Sprintf("Stopped server: %s", server.Name))
-					} else {
-						a.addLogMessage(fmt.Sprintf("Started server: %s", server.Name))
-					}
+				if a.AppModel.State.View == "list" {
+					// The server connects in the background; the model logs the outcome
+					cmd = a.AppModel.ToggleServer(a.AppModel.State.SelectedIndex)
 				}
 			case "d":
 				// Handle disconnect
 				if a.AppModel.State.View == "detail" && a.AppModel.State.SelectedIndex < len(a.AppModel.State.Servers) {
 					server := a.AppModel.State.Servers[a.AppModel.State.SelectedIndex]
 					a.AppModel.DisconnectServer(a.AppModel.State.SelectedIndex)
-					a.addLogMessage(fmt.Sprintf("Disconnected from server: %s", server.Name))
+					a.AppModel.Log(server.ID, logs.LevelInfo, "Disconnected from server: %s", server.Name)
 				}
 			case "x":
 				// Show download/configure dialog
 				a.ShowDialog = true
 				a.DialogType = "download"
 				a.DialogMessage = "MCP Server Manager:\n\n- Download new server\n- Configure existing servers\n\n[y/N] to download example server?"
+			default:
+				// Navigation and view switching are handled by the model
+				_, cmd = a.AppModel.Update(msg)
 			}
 		}
 	}
 
 	// Return the UI wrapper with the updated model
-	return a, nil
+	a.promptRestart()
+	return a, cmd
 }
 
-// addLogMessage adds a message to the log console
-func (a *AppInterface) addLogMessage(message string) {
-	timestamp := time.Now().Format("15:04:05")
-	logEntry := fmt.Sprintf("[%s] %s", timestamp, message)
-	a.LogMessages = append(a.LogMessages, logEntry)
-
-	// Limit log size to prevent memory issues
-	if len(a.LogMessages) > 50 {
-		a.LogMessages = a.LogMessages[len(a.LogMessages)-50:]
+// promptRestart asks whether to restart servers whose settings changed in
+// the configuration, once no other dialog is open
+func (a *AppInterface) promptRestart() {
+	names := a.AppModel.PendingRestartNames()
+	if a.ShowDialog || len(names) == 0 {
+		return
 	}
+	a.ShowDialog = true
+	a.DialogType = "restart"
+	a.DialogMessage = "The configuration of these running servers changed:\n\n  " +
+		strings.Join(names, "\n  ") +
+		"\n\nRestart them now to apply the changes? [y/n]"
+}
+
+// addLogMessage records an operational message in the log
+func (a *AppInterface) addLogMessage(message string) {
+	a.AppModel.Log("", logs.LevelInfo, "%s", message)
 }
 
 // addServerLog adds server operation logs
@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"mcop/src/model"
+)
+
+// Form styles
+var (
+	FormLabelStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("99")).
+			Bold(true)
+
+	FormInputStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("252")).
+			Background(lipgloss.Color("236")).
+			Padding(0, 1)
+
+	FormFocusedInputStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("255")).
+				Background(lipgloss.Color("62")).
+				Padding(0, 1)
+)
+
+// renderForm renders the fields of a form, highlighting the focused one
+func renderForm(form *model.Form) string {
+	var sb strings.Builder
+	for i, field := range form.Fields {
+		label := field.Label
+		if field.Required {
+			label += " *"
+		}
+
+		inputStyle := FormInputStyle
+		value := field.Value
+		if field.IsChoice() {
+			if value == "" {
+				value = "unset"
+			}
+			value = "‹ " + value + " ›"
+		} else if i == form.Focus {
+			value += "▏"
+		}
+		if i == form.Focus {
+			inputStyle = FormFocusedInputStyle
+		}
+
+		sb.WriteString(lipgloss.JoinHorizontal(
+			lipgloss.Left,
+			FormLabelStyle.Width(24).Render(truncate(label, 22)),
+			inputStyle.Width(40).Render(value),
+			HelpStyle.UnsetMarginTop().MarginLeft(1).Render(fieldKindHint(field.Kind)),
+		))
+		sb.WriteString("\n")
+		if field.Description != "" {
+			sb.WriteString(HelpStyle.UnsetMarginTop().MarginLeft(24).Render(truncate(firstLine(field.Description), 60)))
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// fieldKindHint describes the expected input of a form field
+func fieldKindHint(kind model.FieldKind) string {
+	switch kind {
+	case model.FieldNumber:
+		return "number"
+	case model.FieldInteger:
+		return "integer"
+	case model.FieldBool, model.FieldEnum:
+		return "←/→ to choose"
+	case model.FieldList:
+		return "comma-separated"
+	case model.FieldJSON:
+		return "JSON"
+	}
+	return ""
+}
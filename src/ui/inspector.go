@@ -0,0 +1,146 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"mcop/src/mcp"
+	"mcop/src/model"
+)
+
+// frameDetailLines is the number of JSON lines shown for the selected frame
+const frameDetailLines = 15
+
+// renderTrafficView renders the protocol traffic inspector of a server
+func (a *AppInterface) renderTrafficView() string {
+	inspector := &a.AppModel.State.Traffic
+	frames := a.AppModel.InspectedFrames()
+	var sb strings.Builder
+
+	// Title
+	title := TitleStyle.Render(fmt.Sprintf("MCOP - Traffic: %s", a.serverName(inspector.ServerID)))
+	sb.WriteString(title)
+	sb.WriteString("\n\n")
+
+	filter := inspector.MethodFilter
+	if inspector.EditingFilter {
+		filter += "█"
+	} else if filter == "" {
+		filter = "(none)"
+	}
+	errors := "off"
+	if inspector.ErrorsOnly {
+		errors = "on"
+	}
+	sb.WriteString(DetailValueStyle.Render(fmt.Sprintf("Method filter: %s | Errors only: %s", filter, errors)))
+	sb.WriteString("\n\n")
+
+	header := lipgloss.JoinHorizontal(
+		lipgloss.Left,
+		lipgloss.NewStyle().Width(4).Render("DIR"),
+		lipgloss.NewStyle().Width(14).Render("TIME"),
+		lipgloss.NewStyle().Width(36).Render("METHOD"),
+		lipgloss.NewStyle().Width(8).Render("ID"),
+		lipgloss.NewStyle().Width(11).Render("LATENCY"),
+		"SIZE",
+	)
+	sb.WriteString(HeaderStyle.Render(header))
+	sb.WriteString("\n")
+
+	if len(frames) == 0 {
+		sb.WriteString(ItemStyle.Render("[No frames]"))
+		sb.WriteString("\n")
+	}
+
+	selected := inspector.SelectedIndex
+	if inspector.Follow || selected >= len(frames) {
+		selected = len(frames) - 1
+	}
+	// Keep the selection in view, preferring to show the newest frames
+	start := selected - model.InspectorRows + 1
+	if start < 0 {
+		start = 0
+	}
+	end := start + model.InspectorRows
+	if end > len(frames) {
+		end = len(frames)
+	}
+	for i := start; i < end; i++ {
+		sb.WriteString(a.listRowStyle(i == selected).Render(frameRow(frames[i])))
+		sb.WriteString("\n")
+	}
+	if len(frames) > model.InspectorRows {
+		sb.WriteString(HelpStyle.Render(fmt.Sprintf("frame %d of %d", selected+1, len(frames))))
+		sb.WriteString("\n")
+	}
+
+	if selected >= 0 {
+		frame := frames[selected]
+		sb.WriteString("\n")
+		sb.WriteString(DetailTitleStyle.Render(fmt.Sprintf("%s %s:", frame.Direction, frame.Kind)))
+		sb.WriteString("\n")
+		sb.WriteString(renderScrolled(frameLines(frame), inspector.ScrollOffset, frameDetailLines))
+	}
+
+	help := "↑↓=Select | End=Follow | /=Filter method | E=Errors only | PgUp/PgDn=Scroll | Esc=Back"
+	if inspector.EditingFilter {
+		help = "Type to filter by method | Ctrl+U=Clear | Enter/Esc=Done"
+	}
+	sb.WriteString("\n")
+	sb.WriteString(HelpStyle.Render(help))
+
+	return sb.String()
+}
+
+// frameRow renders the summary columns of a frame
+func frameRow(frame mcp.Frame) string {
+	direction := "→"
+	if frame.Direction == mcp.FrameReceived {
+		direction = "←"
+	}
+	method := frame.Method
+	switch {
+	case frame.Kind == mcp.FrameInvalid:
+		method = "(not JSON-RPC)"
+	case method == "":
+		method = "(" + frame.Kind + ")"
+	}
+	if frame.IsError && frame.Kind == mcp.FrameResponse {
+		method += " [error]"
+	}
+	latency := ""
+	if frame.Latency > 0 {
+		latency = formatLatency(frame.Latency)
+	}
+	row := lipgloss.JoinHorizontal(
+		lipgloss.Left,
+		lipgloss.NewStyle().Width(4).Render(direction),
+		lipgloss.NewStyle().Width(14).Render(frame.Time.Format("15:04:05.000")),
+		lipgloss.NewStyle().Width(36).Render(truncate(method, 34)),
+		lipgloss.NewStyle().Width(8).Render(truncate(frame.ID, 7)),
+		lipgloss.NewStyle().Width(11).Render(latency),
+		formatBytes(int64(frame.Size)),
+	)
+	if frame.IsError {
+		return StatusErrorStyle.Padding(0).Render(row)
+	}
+	return row
+}
+
+// frameLines pretty-prints a frame's JSON, falling back to the raw data
+func frameLines(frame mcp.Frame) []string {
+	var lines []string
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, frame.Data, "", "  "); err != nil {
+		lines = strings.Split(string(frame.Data), "\n")
+	} else {
+		lines = strings.Split(pretty.String(), "\n")
+	}
+	if frame.Truncated {
+		lines = append(lines, fmt.Sprintf("[truncated, %s in total]", formatBytes(int64(frame.Size))))
+	}
+	return lines
+}
@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"mcop/src/logs"
+	"mcop/src/model"
+)
+
+// levelColors maps log levels to their display colors
+var levelColors = map[logs.Level]lipgloss.Color{
+	logs.LevelDebug:     lipgloss.Color("241"),
+	logs.LevelInfo:      lipgloss.Color("39"),
+	logs.LevelNotice:    lipgloss.Color("51"),
+	logs.LevelWarning:   lipgloss.Color("220"),
+	logs.LevelError:     lipgloss.Color("203"),
+	logs.LevelCritical:  lipgloss.Color("196"),
+	logs.LevelAlert:     lipgloss.Color("196"),
+	logs.LevelEmergency: lipgloss.Color("196"),
+}
+
+// renderLevel renders a level name padded to a fixed width in its color
+func renderLevel(level logs.Level) string {
+	return lipgloss.NewStyle().
+		Foreground(levelColors[level]).
+		Width(9).
+		Render(level.String())
+}
+
+// renderLogEntry renders one log entry as a single line
+func renderLogEntry(entry logs.Entry) string {
+	origin := entry.ServerID
+	if origin == "" {
+		origin = "mcop"
+	}
+	if entry.Logger != "" {
+		origin += "/" + entry.Logger
+	}
+	return fmt.Sprintf("%s %s %-16s %s",
+		entry.Time.Format("15:04:05"),
+		renderLevel(entry.Level),
+		truncate(origin, 16),
+		entry.Text)
+}
+
+// renderLogsView renders the log pane with its server and severity filters
+func (a *AppInterface) renderLogsView() string {
+	viewer := &a.AppModel.State.Logs
+	entries := a.AppModel.VisibleLogs()
+	var sb strings.Builder
+
+	// Title
+	scope := "all servers"
+	if viewer.ServerID != "" {
+		scope = a.serverName(viewer.ServerID)
+	}
+	title := TitleStyle.Render(fmt.Sprintf("MCOP - Logs: %s", scope))
+	sb.WriteString(title)
+	sb.WriteString("\n\n")
+
+	follow := "off"
+	if viewer.Follow {
+		follow = "on"
+	}
+	sb.WriteString(DetailValueStyle.Render(fmt.Sprintf("Level: %s and above | Follow: %s", viewer.MinLevel, follow)))
+	sb.WriteString("\n\n")
+
+	if len(entries) == 0 {
+		sb.WriteString(ItemStyle.Render("[No log entries]"))
+		sb.WriteString("\n")
+	}
+
+	offset := viewer.Offset
+	if viewer.Follow || offset > len(entries)-model.LogViewLines {
+		offset = len(entries) - model.LogViewLines
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	end := offset + model.LogViewLines
+	if end > len(entries) {
+		end = len(entries)
+	}
+	for _, entry := range entries[offset:end] {
+		sb.WriteString(renderLogEntry(entry))
+		sb.WriteString("\n")
+	}
+	if len(entries) > model.LogViewLines {
+		sb.WriteString(HelpStyle.Render(fmt.Sprintf("entries %d-%d of %d", offset+1, end, len(entries))))
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString(HelpStyle.Render("↑↓/PgUp/PgDn=Scroll | F=Follow | Tab=Server | V=Level | Esc=Back"))
+
+	return sb.String()
+}
@@ -0,0 +1,123 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"mcop/src/mcp"
+)
+
+// promptPreviewLines is the number of rendered prompt lines shown at once
+const promptPreviewLines = 15
+
+// RoleStyle renders the role of each message in a rendered prompt
+var RoleStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("212")).
+	Bold(true)
+
+// renderPromptsView renders the prompts browser for the selected server
+func (a *AppInterface) renderPromptsView() string {
+	browser := &a.AppModel.State.Prompts
+	var sb strings.Builder
+
+	// Title
+	title := TitleStyle.Render(fmt.Sprintf("MCOP - Prompts: %s", a.serverName(browser.ServerID)))
+	sb.WriteString(title)
+	sb.WriteString("\n\n")
+
+	if browser.Error != "" {
+		sb.WriteString(StatusErrorStyle.Render(browser.Error))
+		sb.WriteString("\n\n")
+	}
+	if browser.Loading {
+		sb.WriteString(HelpStyle.Render("Loading..."))
+		sb.WriteString("\n\n")
+	}
+
+	// Prompt list
+	header := lipgloss.JoinHorizontal(
+		lipgloss.Left,
+		lipgloss.NewStyle().Width(30).Render("NAME"),
+		lipgloss.NewStyle().Width(8).Render("ARGS"),
+		"DESCRIPTION",
+	)
+	sb.WriteString(HeaderStyle.Render(header))
+	sb.WriteString("\n")
+
+	if len(browser.Prompts) == 0 && !browser.Loading {
+		sb.WriteString(ItemStyle.Render("[No prompts]"))
+		sb.WriteString("\n")
+	}
+
+	for i, prompt := range browser.Prompts {
+		row := lipgloss.JoinHorizontal(
+			lipgloss.Left,
+			lipgloss.NewStyle().Width(30).Render(truncate(prompt.DisplayName(), 28)),
+			lipgloss.NewStyle().Width(8).Render(fmt.Sprintf("%d", len(prompt.Arguments))),
+			truncate(firstLine(prompt.Description), 60),
+		)
+		sb.WriteString(a.listRowStyle(i == browser.SelectedIndex).Render(row))
+		sb.WriteString("\n")
+	}
+
+	var help string
+	switch {
+	case browser.Editing:
+		sb.WriteString("\n")
+		sb.WriteString(DetailTitleStyle.Render("Arguments:"))
+		sb.WriteString("\n")
+		sb.WriteString(renderForm(&browser.Form))
+		help = "Tab/↑↓=Next field | Ctrl+U=Clear | Enter=Render | Esc=Cancel"
+	case browser.Result != nil:
+		sb.WriteString("\n")
+		sb.WriteString(DetailTitleStyle.Render(fmt.Sprintf("Rendered %s:", browser.ResultName)))
+		sb.WriteString("\n")
+		sb.WriteString(renderScrolled(promptResultLines(browser.Result), browser.ScrollOffset, promptPreviewLines))
+		help = "↑↓=Navigate | Enter=Render | R=Reload | PgUp/PgDn=Scroll | Esc=Back"
+	default:
+		help = "↑↓=Navigate | Enter=Render | R=Reload | Esc=Back"
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString(HelpStyle.Render(help))
+
+	return sb.String()
+}
+
+// promptResultLines flattens a rendered prompt into display lines
+func promptResultLines(result *mcp.GetPromptResult) []string {
+	var lines []string
+	if result.Description != "" {
+		lines = append(lines, result.Description, "")
+	}
+	for _, message := range result.Messages {
+		lines = append(lines, RoleStyle.Render("["+message.Role+"]"))
+		lines = append(lines, contentLines(message.Content)...)
+		lines = append(lines, "")
+	}
+	return lines
+}
+
+// contentLines renders a content block as display lines, summarizing binary data
+func contentLines(content mcp.Content) []string {
+	switch content.Type {
+	case "text":
+		return strings.Split(content.Text, "\n")
+	case "image", "audio":
+		return []string{fmt.Sprintf("[%s %s, %s]", content.Type, content.MimeType, formatBytes(int64(content.DataSize())))}
+	case "resource":
+		if content.Resource == nil {
+			return []string{"[empty resource]"}
+		}
+		if content.Resource.IsBinary() {
+			return []string{fmt.Sprintf("[resource %s %s, %s]", content.Resource.URI, content.Resource.MimeType,
+				formatBytes(int64(content.Resource.BlobSize())))}
+		}
+		return append([]string{"[resource " + content.Resource.URI + "]"}, strings.Split(content.Resource.Text, "\n")...)
+	case "resource_link":
+		return []string{fmt.Sprintf("[link %s] %s", content.URI, content.Name)}
+	default:
+		return []string{fmt.Sprintf("[%s content]", content.Type)}
+	}
+}
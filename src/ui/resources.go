@@ -0,0 +1,192 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// resourceContentLines is the number of content lines shown below the resource list
+const resourceContentLines = 15
+
+// renderResourcesView renders the resources browser for the selected server
+func (a *AppInterface) renderResourcesView() string {
+	browser := &a.AppModel.State.Resources
+	var sb strings.Builder
+
+	// Title
+	title := TitleStyle.Render(fmt.Sprintf("MCOP - Resources: %s", a.serverName(browser.ServerID)))
+	sb.WriteString(title)
+	sb.WriteString("\n\n")
+
+	if browser.Error != "" {
+		sb.WriteString(StatusErrorStyle.Render(browser.Error))
+		sb.WriteString("\n\n")
+	}
+	if browser.Loading {
+		sb.WriteString(HelpStyle.Render("Loading..."))
+		sb.WriteString("\n\n")
+	}
+
+	// Resource and template list
+	header := lipgloss.JoinHorizontal(
+		lipgloss.Left,
+		lipgloss.NewStyle().Width(30).Render("NAME"),
+		lipgloss.NewStyle().Width(20).Render("MIME"),
+		"URI",
+	)
+	sb.WriteString(HeaderStyle.Render(header))
+	sb.WriteString("\n")
+
+	if browser.ItemCount() == 0 && !browser.Loading {
+		sb.WriteString(ItemStyle.Render("[No resources]"))
+		sb.WriteString("\n")
+	}
+
+	for i, resource := range browser.Resources {
+		marker := " "
+		if browser.Subscribed[resource.URI] {
+			marker = "*"
+		}
+		row := lipgloss.JoinHorizontal(
+			lipgloss.Left,
+			lipgloss.NewStyle().Width(30).Render(truncate(marker+" "+resource.DisplayName(), 28)),
+			lipgloss.NewStyle().Width(20).Render(truncate(resource.MimeType, 18)),
+			resource.URI,
+		)
+		sb.WriteString(a.listRowStyle(i == browser.SelectedIndex).Render(row))
+		sb.WriteString("\n")
+	}
+
+	for i, template := range browser.Templates {
+		row := lipgloss.JoinHorizontal(
+			lipgloss.Left,
+			lipgloss.NewStyle().Width(30).Render(truncate("T "+template.DisplayName(), 28)),
+			lipgloss.NewStyle().Width(20).Render(truncate(template.MimeType, 18)),
+			template.URITemplate,
+		)
+		sb.WriteString(a.listRowStyle(len(browser.Resources)+i == browser.SelectedIndex).Render(row))
+		sb.WriteString("\n")
+	}
+
+	// Selected item details
+	if template := browser.SelectedTemplate(); template != nil {
+		sb.WriteString("\n")
+		sb.WriteString(DetailTitleStyle.Render("Template:"))
+		sb.WriteString("\n")
+		sb.WriteString(DetailValueStyle.Render(template.URITemplate))
+		sb.WriteString("\n")
+		if template.Description != "" {
+			sb.WriteString(DetailValueStyle.Render(template.Description))
+			sb.WriteString("\n")
+		}
+	} else if len(browser.Contents) > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(DetailTitleStyle.Render(fmt.Sprintf("Contents of %s:", browser.ContentsURI)))
+		sb.WriteString("\n")
+		sb.WriteString(a.renderResourceContents())
+	}
+
+	help := HelpStyle.Render("↑↓=Navigate | Enter=Read | U=Subscribe | R=Reload | PgUp/PgDn=Scroll | Esc=Back")
+	sb.WriteString("\n")
+	sb.WriteString(help)
+
+	return sb.String()
+}
+
+// renderResourceContents renders text contents with scrolling and summarizes binary blobs
+func (a *AppInterface) renderResourceContents() string {
+	browser := &a.AppModel.State.Resources
+
+	var lines []string
+	for _, contents := range browser.Contents {
+		if contents.IsBinary() {
+			mimeType := contents.MimeType
+			if mimeType == "" {
+				mimeType = "application/octet-stream"
+			}
+			lines = append(lines, fmt.Sprintf("[binary %s, %s] %s", mimeType, formatBytes(int64(contents.BlobSize())), contents.URI))
+			continue
+		}
+		if len(browser.Contents) > 1 {
+			header := "--- " + contents.URI
+			if contents.MimeType != "" {
+				header += " (" + contents.MimeType + ")"
+			}
+			lines = append(lines, header)
+		}
+		lines = append(lines, strings.Split(contents.Text, "\n")...)
+	}
+
+	return renderScrolled(lines, browser.ScrollOffset, resourceContentLines)
+}
+
+// renderScrolled renders a window of lines starting at offset, with a position indicator
+func renderScrolled(lines []string, offset, height int) string {
+	if offset > len(lines)-height {
+		offset = len(lines) - height
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	end := offset + height
+	if end > len(lines) {
+		end = len(lines)
+	}
+
+	var sb strings.Builder
+	for _, line := range lines[offset:end] {
+		sb.WriteString(DetailValueStyle.Render(line))
+		sb.WriteString("\n")
+	}
+	if len(lines) > height {
+		sb.WriteString(HelpStyle.Render(fmt.Sprintf("lines %d-%d of %d", offset+1, end, len(lines))))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// listRowStyle returns the style for a row in one of the browser lists
+func (a *AppInterface) listRowStyle(selected bool) lipgloss.Style {
+	if selected {
+		return SelectedItemStyle
+	}
+	return ItemStyle
+}
+
+// serverName returns the display name of the server with the given ID
+func (a *AppInterface) serverName(serverID string) string {
+	for _, server := range a.AppModel.State.Servers {
+		if server.ID == serverID {
+			return server.Name
+		}
+	}
+	return serverID
+}
+
+// truncate shortens s to at most max characters, marking the cut with "..."
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	if max <= 3 {
+		return string(runes[:max])
+	}
+	return string(runes[:max-3]) + "..."
+}
+
+// formatBytes renders a byte count using binary units
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,116 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"mcop/src/stats"
+)
+
+// sparkBlocks are the glyphs of a sparkline, from lowest to highest
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// listHistoryBuckets is the number of history buckets in the server list sparkline
+const listHistoryBuckets = 12
+
+// detailHistoryBuckets is the number of history buckets in the detail view graphs
+const detailHistoryBuckets = 30
+
+// sparkline renders values scaled to their maximum, one glyph per value;
+// values of zero or less are drawn as blanks
+func sparkline(values []float64) string {
+	max := 0.0
+	for _, v := range values {
+		max = math.Max(max, v)
+	}
+	var sb strings.Builder
+	for _, v := range values {
+		if v <= 0 {
+			sb.WriteRune(' ')
+			continue
+		}
+		level := int(math.Ceil(v/max*float64(len(sparkBlocks)))) - 1
+		sb.WriteRune(sparkBlocks[level])
+	}
+	return sb.String()
+}
+
+// historyValues extracts one value per bucket of a server's history
+func historyValues(history []stats.Summary, value func(stats.Summary) float64) []float64 {
+	values := make([]float64, len(history))
+	for i, summary := range history {
+		values[i] = value(summary)
+	}
+	return values
+}
+
+// p95Millis returns the p95 latency of a bucket in milliseconds
+func p95Millis(s stats.Summary) float64 {
+	return float64(s.P95) / float64(time.Millisecond)
+}
+
+// formatLatency renders a latency with a precision that suits its magnitude
+func formatLatency(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(10 * time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(100 * time.Microsecond).String()
+	default:
+		return d.Round(time.Microsecond).String()
+	}
+}
+
+// trafficColumns renders the requests/min and latency sparkline columns of the server list
+func (a *AppInterface) trafficColumns(serverID string) (string, string) {
+	now := time.Now()
+	summary := a.AppModel.Stats.Summary(serverID, now, time.Minute)
+	if summary.Requests == 0 {
+		return "-", ""
+	}
+	history := a.AppModel.Stats.History(serverID, now, listHistoryBuckets)
+	return fmt.Sprintf("%.0f", summary.RequestsPerMinute), sparkline(historyValues(history, p95Millis))
+}
+
+// renderTraffic renders the latency, throughput and error history of a server
+func (a *AppInterface) renderTraffic(serverID string) string {
+	now := time.Now()
+	summary := a.AppModel.Stats.Summary(serverID, now, time.Minute)
+	history := a.AppModel.Stats.History(serverID, now, detailHistoryBuckets)
+	var sb strings.Builder
+
+	sb.WriteString(DetailTitleStyle.Render("Traffic (last minute):"))
+	sb.WriteString("\n")
+	if summary.Requests == 0 {
+		sb.WriteString(DetailValueStyle.Render("No requests"))
+		sb.WriteString("\n\n")
+		return sb.String()
+	}
+	sb.WriteString(DetailValueStyle.Render(fmt.Sprintf("Latency: p50 %s | p95 %s | p99 %s",
+		formatLatency(summary.P50), formatLatency(summary.P95), formatLatency(summary.P99))))
+	sb.WriteString("\n")
+	sb.WriteString(DetailValueStyle.Render(fmt.Sprintf("Requests/min: %.0f | Error rate: %.1f%%",
+		summary.RequestsPerMinute, summary.ErrorRate*100)))
+	sb.WriteString("\n\n")
+
+	window := time.Duration(detailHistoryBuckets) * stats.BucketWidth
+	sb.WriteString(DetailTitleStyle.Render(fmt.Sprintf("History (last %s):", window)))
+	sb.WriteString("\n")
+	graphs := []struct {
+		label string
+		value func(stats.Summary) float64
+	}{
+		{"p95 latency ", p95Millis},
+		{"requests    ", func(s stats.Summary) float64 { return float64(s.Requests) }},
+		{"errors      ", func(s stats.Summary) float64 { return float64(s.Errors) }},
+	}
+	for _, graph := range graphs {
+		line := fmt.Sprintf("%s│%s│", graph.label, sparkline(historyValues(history, graph.value)))
+		sb.WriteString(DetailValueStyle.Render(line))
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
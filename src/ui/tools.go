@@ -0,0 +1,116 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"mcop/src/mcp"
+)
+
+// toolResultLines is the number of result lines shown below the tool list
+const toolResultLines = 15
+
+// renderToolsView renders the tool invocation view for the selected server
+func (a *AppInterface) renderToolsView() string {
+	runner := &a.AppModel.State.Tools
+	tools := a.AppModel.RunnerTools()
+	var sb strings.Builder
+
+	// Title
+	title := TitleStyle.Render(fmt.Sprintf("MCOP - Tools: %s", a.serverName(runner.ServerID)))
+	sb.WriteString(title)
+	sb.WriteString("\n\n")
+
+	if runner.Error != "" {
+		sb.WriteString(StatusErrorStyle.Render(runner.Error))
+		sb.WriteString("\n\n")
+	}
+	if runner.Loading {
+		sb.WriteString(HelpStyle.Render("Calling..."))
+		sb.WriteString("\n\n")
+	}
+
+	// Tool list
+	header := lipgloss.JoinHorizontal(
+		lipgloss.Left,
+		lipgloss.NewStyle().Width(30).Render("NAME"),
+		"DESCRIPTION",
+	)
+	sb.WriteString(HeaderStyle.Render(header))
+	sb.WriteString("\n")
+
+	if len(tools) == 0 {
+		sb.WriteString(ItemStyle.Render("[No tools]"))
+		sb.WriteString("\n")
+	}
+
+	for i, tool := range tools {
+		row := lipgloss.JoinHorizontal(
+			lipgloss.Left,
+			lipgloss.NewStyle().Width(30).Render(truncate(tool.DisplayName(), 28)),
+			truncate(firstLine(tool.Description), 60),
+		)
+		sb.WriteString(a.listRowStyle(i == runner.SelectedIndex).Render(row))
+		sb.WriteString("\n")
+	}
+
+	var help string
+	switch {
+	case runner.Editing:
+		sb.WriteString("\n")
+		sb.WriteString(DetailTitleStyle.Render("Input:"))
+		sb.WriteString("\n")
+		sb.WriteString(renderForm(&runner.Form))
+		help = "Tab/↑↓=Next field | ←→/Space=Choose | Ctrl+U=Clear | Enter=Call | Esc=Cancel"
+	case runner.Result != nil:
+		status := StatusRunningStyle.Render("ok")
+		if runner.Result.IsError {
+			status = StatusErrorStyle.Render("tool error")
+		}
+		sb.WriteString("\n")
+		sb.WriteString(DetailTitleStyle.Render(fmt.Sprintf("Result of %s (%s, %s):",
+			runner.ResultTool, runner.Elapsed.Round(time.Millisecond), status)))
+		sb.WriteString("\n")
+		sb.WriteString(renderScrolled(toolResultContentLines(runner.Result), runner.ScrollOffset, toolResultLines))
+		help = "↑↓=Navigate | Enter=Call | R=Reload | PgUp/PgDn=Scroll | Esc=Back"
+	default:
+		help = "↑↓=Navigate | Enter=Call | R=Reload | Esc=Back"
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString(HelpStyle.Render(help))
+
+	return sb.String()
+}
+
+// toolResultContentLines flattens a tool result into display lines, with
+// structured content pretty-printed after the content blocks
+func toolResultContentLines(result *mcp.CallToolResult) []string {
+	var lines []string
+	for i, content := range result.Content {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, contentLines(content)...)
+	}
+	if len(result.StructuredContent) > 0 {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, RoleStyle.Render("[structured]"))
+		var pretty bytes.Buffer
+		if err := json.Indent(&pretty, result.StructuredContent, "", "  "); err != nil {
+			lines = append(lines, string(result.StructuredContent))
+		} else {
+			lines = append(lines, strings.Split(pretty.String(), "\n")...)
+		}
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "[empty result]")
+	}
+	return lines
+}
@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"mcop/src/config"
+)
+
+// buildMcop compiles the mcop command, for tests that run it the way a
+// shell script would
+func buildMcop(t *testing.T) string {
+	t.Helper()
+	// Another test changes the working directory, so find the module from here
+	_, file, _, _ := runtime.Caller(0)
+	bin := filepath.Join(t.TempDir(), "mcop")
+	cmd := exec.Command("go", "build", "-o", bin, "./cmd/mcop")
+	cmd.Dir = filepath.Join(filepath.Dir(file), "..")
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	return bin
+}
+
+// runMcop runs the mcop binary and returns its stdout, stderr and exit status
+func runMcop(t *testing.T, bin, stdin string, args ...string) (string, string, int) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(bin, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return stdout.String(), stderr.String(), exitErr.ExitCode()
+	}
+	require.NoError(t, err)
+	return stdout.String(), stderr.String(), 0
+}
+
+func TestCallCommand(t *testing.T) {
+	bin := buildMcop(t)
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.json")
+	cfg := &config.AppConfig{Servers: []config.MCPServer{{ID: "fake", Name: "Fake", URL: fakeServerURL("ok")}}}
+	require.NoError(t, cfg.SaveConfig(cfgPath))
+	argsPath := filepath.Join(dir, "args.json")
+	require.NoError(t, os.WriteFile(argsPath, []byte(`{"from": "file"}`), 0644))
+
+	call := func(stdin string, args ...string) (string, string, int) {
+		return runMcop(t, bin, stdin, append([]string{"--config", cfgPath, "call"}, args...)...)
+	}
+
+	t.Run("inline arguments", func(t *testing.T) {
+		stdout, stderr, code := call("", "fake", "echo", "--args", `{"from": "inline"}`)
+		assert.Equal(t, 0, code, stderr)
+		assert.Contains(t, stdout, `{"from":"inline"}`)
+	})
+
+	t.Run("arguments from a file", func(t *testing.T) {
+		stdout, stderr, code := call("", "fake", "echo", "--args", "@"+argsPath)
+		assert.Equal(t, 0, code, stderr)
+		assert.Contains(t, stdout, `{"from":"file"}`)
+	})
+
+	t.Run("arguments from stdin", func(t *testing.T) {
+		stdout, stderr, code := call(`{"from": "stdin"}`, "fake", "echo", "--args", "-", "-o", "json")
+		assert.Equal(t, 0, code, stderr)
+		assert.Contains(t, stdout, `"structuredContent"`)
+		assert.Contains(t, stdout, "stdin")
+	})
+
+	t.Run("arguments that are not an object", func(t *testing.T) {
+		_, stderr, code := call("", "fake", "echo", "--args", `[1, 2]`)
+		assert.Equal(t, 2, code)
+		assert.Contains(t, stderr, "expected a JSON object")
+	})
+
+	t.Run("tool error", func(t *testing.T) {
+		_, stderr, code := call("", "fake", "fail", "--args", `{}`)
+		assert.Equal(t, 1, code)
+		assert.Contains(t, stderr, "reported an error")
+	})
+
+	t.Run("unknown server", func(t *testing.T) {
+		_, stderr, code := call("", "missing", "echo")
+		assert.Equal(t, 2, code)
+		assert.Contains(t, stderr, "not found")
+	})
+}
@@ -0,0 +1,330 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"mcop/src/mcp"
+	"mcop/src/types"
+)
+
+// fakeServerURL returns a stdio URL that re-executes the test binary as a fake MCP server
+func fakeServerURL(mode string) string {
+	return fmt.Sprintf("stdio://%s -test.run=^TestFakeServer$ -- fake-server %s", os.Args[0], mode)
+}
+
+// TestFakeServer is not a real test: it acts as a stdio MCP server when the
+// test binary is re-executed by fakeServerURL
+func TestFakeServer(t *testing.T) {
+	args := os.Args
+	mode := ""
+	for i, arg := range args {
+		if arg == "fake-server" && i+1 < len(args) {
+			mode = args[i+1]
+		}
+	}
+	if mode == "" {
+		t.Skip("helper process for client tests")
+	}
+
+	out := json.NewEncoder(os.Stdout)
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		messages, err := mcp.ParseMessages(scanner.Bytes())
+		if err != nil {
+			continue
+		}
+		req, ok := messages[0].(*mcp.MCPRequest)
+		if !ok {
+			continue
+		}
+
+		if req.Method == "crash" {
+			os.Exit(1)
+		}
+		if req.Method == "touch_tools" {
+			notification, _ := mcp.NewNotification("notifications/tools/list_changed", nil)
+			out.Encode(notification)
+		}
+		if req.Method == "noise" {
+			fmt.Println("not json-rpc")
+		}
+		if req.Method == "log" {
+			fmt.Fprintln(os.Stderr, "WARN: disk almost full")
+			notification, _ := mcp.NewNotification("notifications/message", map[string]interface{}{
+				"level": "error", "logger": "db", "data": map[string]int{"retries": 3},
+			})
+			out.Encode(notification)
+		}
+		if resp := fakeResponse(mode, req); resp != nil {
+			out.Encode(resp)
+		}
+	}
+	os.Exit(0)
+}
+
+// fakeResponse answers a request the way the fake servers used in these tests do;
+// it returns nil for requests that should never be answered
+func fakeResponse(mode string, req *mcp.MCPRequest) *mcp.MCPResponse {
+	var result interface{}
+	switch req.Method {
+	case "initialize":
+		version := mcp.LatestProtocolVersion
+		if mode == "bad-version" {
+			version = "1999-01-01"
+		}
+		result = mcp.InitializeResult{
+			ProtocolVersion: version,
+			Capabilities:    mcp.ServerCapabilities{Tools: &mcp.ToolsCapability{ListChanged: true}},
+			ServerInfo:      mcp.Implementation{Name: "fake", Version: "0.0.1"},
+		}
+	case "echo":
+		result = req.Params
+	case "fail":
+		resp := mcp.NewErrorResponse(req.ID, mcp.ErrCodeInvalidParams, "bad params")
+		resp.Error.Data = json.RawMessage(`{"field":"x"}`)
+		return resp
+	case "hang":
+		return nil
+	case "ping":
+		if mode == "unresponsive" {
+			return nil
+		}
+		result = struct{}{}
+	case "touch_tools", "log", "logging/setLevel", "noise":
+		result = struct{}{}
+	case "tools/list":
+		var params mcp.PaginatedParams
+		json.Unmarshal(req.Params, &params)
+		if params.Cursor == "" {
+			result = mcp.ListToolsResult{
+				Tools:      []types.Tool{{Name: "echo", InputSchema: json.RawMessage(`{"type":"object"}`)}, {Name: "fail"}},
+				NextCursor: "page-2",
+			}
+		} else {
+			result = mcp.ListToolsResult{Tools: []types.Tool{{Name: "hang", Title: "Hang forever"}}}
+		}
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		json.Unmarshal(req.Params, &params)
+		result = mcp.CallToolResult{
+			Content:           []mcp.Content{{Type: "text", Text: string(params.Arguments)}},
+			StructuredContent: params.Arguments,
+			IsError:           params.Name == "fail",
+		}
+	case "resources/list":
+		result = mcp.ListResourcesResult{Resources: []mcp.Resource{{URI: "file:///readme.md", Name: "readme.md", MimeType: "text/markdown"}}}
+	case "resources/templates/list":
+		result = mcp.ListResourceTemplatesResult{ResourceTemplates: []mcp.ResourceTemplate{{URITemplate: "file:///{path}", Name: "files"}}}
+	case "resources/read":
+		var params mcp.ResourceParams
+		json.Unmarshal(req.Params, &params)
+		result = mcp.ReadResourceResult{Contents: []mcp.ResourceContents{
+			{URI: params.URI, MimeType: "text/markdown", Text: "# Hello"},
+			{URI: params.URI + "#logo", MimeType: "image/png", Blob: "iVBORw0KGgo="},
+		}}
+	case "prompts/list":
+		result = mcp.ListPromptsResult{Prompts: []mcp.Prompt{{
+			Name:      "greet",
+			Arguments: []mcp.PromptArgument{{Name: "name", Required: true}},
+		}}}
+	case "prompts/get":
+		var params mcp.GetPromptParams
+		json.Unmarshal(req.Params, &params)
+		if params.Arguments["name"] == "" {
+			return mcp.NewErrorResponse(req.ID, mcp.ErrCodeInvalidParams, "missing argument: name")
+		}
+		result = mcp.GetPromptResult{Messages: []mcp.PromptMessage{{
+			Role:    "user",
+			Content: mcp.Content{Type: "text", Text: "Hello, " + params.Arguments["name"]},
+		}}}
+	default:
+		return mcp.NewErrorResponse(req.ID, mcp.ErrCodeMethodNotFound, "method not found")
+	}
+	resp, _ := mcp.NewResultResponse(req.ID, result)
+	return resp
+}
+
+func TestConnectPerformsHandshake(t *testing.T) {
+	client := mcp.NewMCPClient(types.MCPServer{ID: "fake", URL: fakeServerURL("ok")})
+	require.NoError(t, client.Connect())
+	defer client.Disconnect()
+
+	assert.True(t, client.IsConnected())
+	assert.Equal(t, "fake", client.ServerInfo().Name)
+	assert.Equal(t, mcp.LatestProtocolVersion, client.ProtocolVersion())
+	assert.Equal(t, []string{"tools"}, client.ServerCapabilities().Names())
+}
+
+func TestConnectRejectsUnsupportedProtocolVersion(t *testing.T) {
+	client := mcp.NewMCPClient(types.MCPServer{ID: "fake", URL: fakeServerURL("bad-version")})
+	err := client.Connect()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported protocol version")
+	assert.False(t, client.IsConnected())
+}
+
+func TestCallCorrelatesResponses(t *testing.T) {
+	client := mcp.NewMCPClient(types.MCPServer{ID: "fake", URL: fakeServerURL("ok")})
+	require.NoError(t, client.Connect())
+	defer client.Disconnect()
+
+	results := make(chan string, 10)
+	for i := 0; i < 10; i++ {
+		go func(i int) {
+			var out map[string]int
+			err := client.CallResult(context.Background(), "echo", map[string]int{"n": i}, &out)
+			if err != nil || out["n"] != i {
+				results <- fmt.Sprintf("call %d: got %v, %v", i, out, err)
+				return
+			}
+			results <- ""
+		}(i)
+	}
+	for i := 0; i < 10; i++ {
+		assert.Empty(t, <-results)
+	}
+}
+
+func TestCallPropagatesErrors(t *testing.T) {
+	client := mcp.NewMCPClient(types.MCPServer{ID: "fake", URL: fakeServerURL("ok")})
+	require.NoError(t, client.Connect())
+	defer client.Disconnect()
+
+	_, err := client.Call(context.Background(), "fail", nil)
+	var mcpErr *mcp.MCPError
+	require.True(t, errors.As(err, &mcpErr))
+	assert.Equal(t, mcp.ErrCodeInvalidParams, mcpErr.Code)
+	assert.JSONEq(t, `{"field":"x"}`, string(mcpErr.Data))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = client.Call(ctx, "hang", nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, err = client.Call(context.Background(), "crash", nil)
+	assert.ErrorIs(t, err, mcp.ErrConnectionClosed)
+	<-client.Done()
+	assert.False(t, client.IsConnected())
+}
+
+func TestPingMeasuresRoundTrip(t *testing.T) {
+	client := mcp.NewMCPClient(types.MCPServer{ID: "fake", URL: fakeServerURL("ok")})
+	require.NoError(t, client.Connect())
+	defer client.Disconnect()
+
+	rtt, err := client.Ping(context.Background())
+	require.NoError(t, err)
+	assert.Greater(t, rtt, time.Duration(0))
+
+	slow := mcp.NewMCPClient(types.MCPServer{ID: "slow", URL: fakeServerURL("unresponsive")})
+	require.NoError(t, slow.Connect())
+	defer slow.Disconnect()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = slow.Ping(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestListToolsFollowsPagination(t *testing.T) {
+	client := mcp.NewMCPClient(types.MCPServer{ID: "fake", URL: fakeServerURL("ok")})
+	require.NoError(t, client.Connect())
+	defer client.Disconnect()
+
+	changed := make(chan struct{}, 1)
+	client.OnNotification("notifications/tools/list_changed", func(*mcp.MCPNotification) {
+		changed <- struct{}{}
+	})
+
+	tools, err := client.ListTools(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"echo", "fail", "hang"}, types.ToolNames(tools))
+	assert.JSONEq(t, `{"type":"object"}`, string(tools[0].InputSchema))
+	assert.Equal(t, "Hang forever", tools[2].DisplayName())
+
+	_, err = client.Call(context.Background(), "touch_tools", nil)
+	require.NoError(t, err)
+	select {
+	case <-changed:
+	case <-time.After(time.Second):
+		t.Fatal("list_changed notification was not dispatched")
+	}
+}
+
+func TestResourceMethods(t *testing.T) {
+	client := mcp.NewMCPClient(types.MCPServer{ID: "fake", URL: fakeServerURL("ok")})
+	require.NoError(t, client.Connect())
+	defer client.Disconnect()
+
+	ctx := context.Background()
+	resources, err := client.ListResources(ctx)
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+	assert.Equal(t, "readme.md", resources[0].DisplayName())
+
+	templates, err := client.ListResourceTemplates(ctx)
+	require.NoError(t, err)
+	require.Len(t, templates, 1)
+	assert.Equal(t, "file:///{path}", templates[0].URITemplate)
+
+	contents, err := client.ReadResource(ctx, resources[0].URI)
+	require.NoError(t, err)
+	require.Len(t, contents, 2)
+	assert.False(t, contents[0].IsBinary())
+	assert.Equal(t, "# Hello", contents[0].Text)
+	assert.True(t, contents[1].IsBinary())
+	assert.Equal(t, 8, contents[1].BlobSize())
+}
+
+func TestPromptMethods(t *testing.T) {
+	client := mcp.NewMCPClient(types.MCPServer{ID: "fake", URL: fakeServerURL("ok")})
+	require.NoError(t, client.Connect())
+	defer client.Disconnect()
+
+	ctx := context.Background()
+	prompts, err := client.ListPrompts(ctx)
+	require.NoError(t, err)
+	require.Len(t, prompts, 1)
+	assert.True(t, prompts[0].Arguments[0].Required)
+
+	result, err := client.GetPrompt(ctx, "greet", map[string]string{"name": "Ada"})
+	require.NoError(t, err)
+	require.Len(t, result.Messages, 1)
+	assert.Equal(t, "user", result.Messages[0].Role)
+	assert.Equal(t, "Hello, Ada", result.Messages[0].Content.Text)
+
+	_, err = client.GetPrompt(ctx, "greet", nil)
+	var rpcErr *mcp.MCPError
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, mcp.ErrCodeInvalidParams, rpcErr.Code)
+}
+
+func TestCallTool(t *testing.T) {
+	client := mcp.NewMCPClient(types.MCPServer{ID: "fake", URL: fakeServerURL("ok")})
+	require.NoError(t, client.Connect())
+	defer client.Disconnect()
+
+	ctx := context.Background()
+	result, err := client.CallTool(ctx, "echo", map[string]interface{}{"count": 2})
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	require.Len(t, result.Content, 1)
+	assert.JSONEq(t, `{"count":2}`, result.Content[0].Text)
+	assert.JSONEq(t, `{"count":2}`, string(result.StructuredContent))
+
+	result, err = client.CallTool(ctx, "fail", json.RawMessage(`{}`))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
@@ -0,0 +1,267 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"mcop/src/config"
+	"mcop/src/types"
+)
+
+func TestLoadConfigMigratesStdioURLs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	legacy := `{
+		"servers": [
+			{"id": "github", "name": "GitHub", "url": "stdio://npx -y '@scope/server github'", "description": ""},
+			{"id": "remote", "name": "Remote", "url": "https://example.com/mcp", "description": ""}
+		],
+		"server_configs": {
+			"github": {"environment": {"TOKEN": "t"}, "working_dir": "/srv", "restart_policy": "always"}
+		}
+	}`
+	require.NoError(t, os.WriteFile(path, []byte(legacy), 0644))
+
+	cfg, err := config.LoadConfig(path)
+	require.NoError(t, err)
+	github := cfg.GetServer("github")
+	assert.Equal(t, types.TransportStdio, github.Transport)
+	assert.Equal(t, "npx", github.Command)
+	assert.Equal(t, []string{"-y", "@scope/server github"}, github.Args)
+	assert.Equal(t, map[string]string{"TOKEN": "t"}, github.Env)
+	assert.Equal(t, "/srv", github.Cwd)
+	assert.Empty(t, github.URL)
+	assert.Equal(t, "always", cfg.GetServerConfig("github").RestartPolicy)
+	assert.Equal(t, types.TransportHTTP, cfg.GetServer("remote").Transport)
+
+	runtime := github.RuntimeServer()
+	assert.Equal(t, `stdio://npx -y "@scope/server github"`, runtime.Endpoint())
+
+	require.NoError(t, cfg.SaveConfig(path))
+	saved, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(saved), "stdio://")
+	assert.NotContains(t, string(saved), "working_dir")
+
+	reloaded, err := config.LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, cfg.Servers, reloaded.Servers)
+}
+
+func TestMigrateKeepsServerSettings(t *testing.T) {
+	cfg := &config.AppConfig{
+		Servers: []config.MCPServer{{
+			ID:      "srv",
+			Command: "server",
+			Env:     map[string]string{"LEVEL": "debug"},
+			Cwd:     "/opt/srv",
+		}},
+		ServerConfigs: map[string]config.ServerConfig{
+			"srv": {Environment: map[string]string{"LEVEL": "info", "HOME": "/tmp"}, WorkingDir: "/old"},
+		},
+	}
+	cfg.Migrate()
+
+	server := cfg.GetServer("srv")
+	assert.Equal(t, map[string]string{"LEVEL": "debug", "HOME": "/tmp"}, server.Env)
+	assert.Equal(t, "/opt/srv", server.Cwd)
+	assert.NotContains(t, cfg.ServerConfigs, "srv")
+}
+
+// configLayers points the system, user and project configuration at a
+// temporary directory and returns the paths of the three files
+func configLayers(t *testing.T) (system, user, project string) {
+	root := t.TempDir()
+	previous := config.SystemConfigDir
+	config.SystemConfigDir = filepath.Join(root, "etc")
+	t.Cleanup(func() { config.SystemConfigDir = previous })
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(root, "home"))
+	t.Setenv("MCOP_CONFIG", "")
+
+	workdir := filepath.Join(root, "project", "sub")
+	require.NoError(t, os.MkdirAll(workdir, 0755))
+	t.Chdir(workdir)
+
+	system = filepath.Join(root, "etc", "config.json")
+	user = filepath.Join(root, "home", "mcop", "config.json")
+	project = filepath.Join(root, "project", ".mcop", "config.json")
+	return system, user, project
+}
+
+func writeConfig(t *testing.T, path, data string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(data), 0644))
+}
+
+func TestLoadConfigMergesLayers(t *testing.T) {
+	system, user, project := configLayers(t)
+	writeConfig(t, system, `{
+		"servers": [{"id": "shared", "name": "Shared", "command": "shared-v1"}],
+		"refresh_rate": 30,
+		"default_theme": "dark"
+	}`)
+	writeConfig(t, user, `{
+		"servers": [{"id": "mine", "name": "Mine", "command": "mine"}],
+		"refresh_rate": 10
+	}`)
+	writeConfig(t, project, `{
+		"servers": [{"id": "shared", "name": "Shared", "command": "shared-v2"}],
+		"auto_refresh": true
+	}`)
+
+	cfg, err := config.LoadConfig("")
+	require.NoError(t, err)
+	require.Len(t, cfg.Layers(), 3)
+	assert.Equal(t, config.ScopeProject, cfg.Layers()[2].Scope)
+	assert.Equal(t, project, cfg.Path())
+
+	require.Len(t, cfg.Servers, 2)
+	assert.Equal(t, "shared-v2", cfg.GetServer("shared").Command)
+	assert.Equal(t, project, cfg.Origin("shared"))
+	assert.Equal(t, user, cfg.Origin("mine"))
+	assert.Equal(t, 10, cfg.RefreshRate)
+	assert.Equal(t, user, cfg.SettingOrigin("refresh_rate"))
+	assert.Equal(t, "dark", cfg.DefaultTheme)
+	assert.True(t, cfg.AutoRefresh)
+
+	explicit, err := config.LoadConfig(user)
+	require.NoError(t, err)
+	require.Len(t, explicit.Servers, 1)
+	assert.Equal(t, "mine", explicit.Servers[0].ID)
+}
+
+func TestSaveWritesOnlyTheTopLayer(t *testing.T) {
+	_, user, project := configLayers(t)
+	writeConfig(t, user, `{
+		"servers": [{"id": "mine", "name": "Mine", "command": "mine"}],
+		"refresh_rate": 10
+	}`)
+	writeConfig(t, project, `{"servers": []}`)
+
+	cfg, err := config.LoadConfig("")
+	require.NoError(t, err)
+	cfg.AddServer(config.MCPServer{ID: "local", Name: "Local", Command: "local"})
+	require.NoError(t, cfg.Save())
+
+	saved, err := os.ReadFile(project)
+	require.NoError(t, err)
+	assert.Contains(t, string(saved), `"local"`)
+	assert.NotContains(t, string(saved), `"mine"`)
+	assert.NotContains(t, string(saved), "refresh_rate")
+
+	reloaded, err := config.LoadConfig("")
+	require.NoError(t, err)
+	assert.Len(t, reloaded.Servers, 2)
+	assert.Equal(t, 10, reloaded.RefreshRate)
+
+	reloaded.RemoveServer("mine")
+	assert.ErrorContains(t, reloaded.Save(), "cannot be removed")
+}
+
+func TestLoadConfigWithoutFilesSavesToUserConfig(t *testing.T) {
+	_, user, _ := configLayers(t)
+
+	cfg, err := config.LoadConfig("")
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Layers())
+	assert.Equal(t, user, cfg.Path())
+	assert.NotEmpty(t, cfg.Servers)
+	assert.Empty(t, cfg.Origin(cfg.Servers[0].ID))
+}
+
+func TestSaveNeverWritesTheSystemConfig(t *testing.T) {
+	system, user, _ := configLayers(t)
+	shared := `{"servers": [{"id": "shared", "name": "Shared", "command": "shared"}]}`
+	writeConfig(t, system, shared)
+
+	cfg, err := config.LoadConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, user, cfg.Path())
+	cfg.AddServer(config.MCPServer{ID: "mine", Name: "Mine", Command: "mine"})
+	require.NoError(t, cfg.Save())
+
+	data, err := os.ReadFile(system)
+	require.NoError(t, err)
+	assert.Equal(t, shared, string(data))
+	data, err = os.ReadFile(user)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"mine"`)
+	assert.NotContains(t, string(data), `"shared"`)
+}
+
+func TestYAMLConfigKeepsComments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "servers.yaml")
+	writeConfig(t, path, `# Team inventory
+servers:
+  # Code hosting
+  - id: github
+    name: GitHub
+    command: npx # pinned
+    args: ["-y", "@modelcontextprotocol/server-github"]
+    description: ""
+refresh_rate: 10 # seconds
+`)
+
+	cfg, err := config.LoadConfig(path)
+	require.NoError(t, err)
+	require.Len(t, cfg.Servers, 1)
+	assert.Equal(t, []string{"-y", "@modelcontextprotocol/server-github"}, cfg.Servers[0].Args)
+	assert.Equal(t, 10, cfg.RefreshRate)
+
+	cfg.AddServer(config.MCPServer{ID: "search", Name: "Search", URL: "https://search.example.com/mcp"})
+	require.NoError(t, cfg.SaveConfig(path))
+	saved, err := os.ReadFile(path)
+	require.NoError(t, err)
+	for _, comment := range []string{"# Team inventory", "# Code hosting", "# pinned", "# seconds"} {
+		assert.Contains(t, string(saved), comment)
+	}
+
+	reloaded, err := config.LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, cfg.Servers, reloaded.Servers)
+}
+
+func TestTOMLConfigRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "servers.toml")
+	writeConfig(t, path, `# Team inventory
+
+refresh_rate = 10
+
+[[servers]]
+id = "github"
+name = "GitHub"
+command = "npx"
+args = ["-y", "@modelcontextprotocol/server-github"]
+description = ""
+`)
+
+	cfg, err := config.LoadConfig(path)
+	require.NoError(t, err)
+	require.Len(t, cfg.Servers, 1)
+	assert.Equal(t, "npx", cfg.Servers[0].Command)
+	assert.Equal(t, 10, cfg.RefreshRate)
+
+	require.NoError(t, cfg.SaveConfig(path))
+	saved, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(saved), "# Team inventory\n"))
+	assert.Contains(t, string(saved), "refresh_rate = 10\n")
+
+	reloaded, err := config.LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, cfg.Servers, reloaded.Servers)
+}
+
+func TestLoadConfigFindsYAMLLayers(t *testing.T) {
+	_, user, _ := configLayers(t)
+	yamlPath := strings.TrimSuffix(user, ".json") + ".yml"
+	writeConfig(t, yamlPath, "servers:\n  - id: mine\n    name: Mine\n    command: mine\n")
+
+	cfg, err := config.LoadConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, yamlPath, cfg.Path())
+	assert.Equal(t, yamlPath, cfg.Origin("mine"))
+}
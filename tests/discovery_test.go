@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"mcop/src/discovery"
+	"mcop/src/mcp"
+	"mcop/src/types"
+)
+
+func TestDiscoverFromConfigUsesServerSettings(t *testing.T) {
+	t.Setenv("DISCOVERY_TOKEN", "secret")
+	// Each stream gets its own session, since discovery probes the URL first
+	var mu sync.Mutex
+	sessions := make(map[string]chan []byte)
+	authorized := func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return false
+		}
+		return true
+	}
+
+	// An SSE server at a path the URL convention would take for Streamable HTTP
+	mux := http.NewServeMux()
+	mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(w, r) {
+			return
+		}
+		mu.Lock()
+		session := fmt.Sprint(len(sessions))
+		events := make(chan []byte, 10)
+		sessions[session] = events
+		mu.Unlock()
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "event: endpoint\ndata: /messages?session=%s\n\n", session)
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case data := <-events:
+				fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+				w.(http.Flusher).Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	})
+	mux.HandleFunc("/messages", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(w, r) {
+			return
+		}
+		if req, ok := readRequest(t, r).(*mcp.MCPRequest); ok {
+			data, _ := json.Marshal(fakeResponse("ok", req))
+			mu.Lock()
+			events := sessions[r.URL.Query().Get("session")]
+			mu.Unlock()
+			events <- data
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	servers, err := discovery.NewDiscoveryService().DiscoverFromConfig([]types.MCPServer{{
+		ID:        "remote",
+		Name:      "Remote",
+		URL:       server.URL + "/events",
+		Transport: types.TransportSSE,
+		Headers:   map[string]string{"Authorization": "Bearer ${DISCOVERY_TOKEN}"},
+	}})
+	require.NoError(t, err)
+	require.Len(t, servers, 1)
+	assert.Equal(t, "running", servers[0].Status)
+	assert.Equal(t, []string{"echo", "fail", "hang"}, servers[0].Tools)
+}
@@ -0,0 +1,227 @@
+package tests
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"mcop/src/config"
+	"mcop/src/mcp"
+	"mcop/src/types"
+)
+
+func TestFormatStdioURLRoundTrips(t *testing.T) {
+	args := []string{"-y", "@scope/server", "/path with spaces", `say "hi"`, "it's", `'"'`}
+	url, err := mcp.FormatStdioURL("npx", args)
+	require.NoError(t, err)
+
+	command, parsed, err := mcp.ParseStdioURL(url)
+	require.NoError(t, err)
+	assert.Equal(t, "npx", command)
+	assert.Equal(t, args, parsed)
+
+	_, err = mcp.FormatStdioURL("server", []string{""})
+	assert.Error(t, err)
+}
+
+func TestDetectFormat(t *testing.T) {
+	format, err := config.DetectFormat("claude_desktop_config.json", []byte(`{"mcpServers": {}}`))
+	require.NoError(t, err)
+	assert.Equal(t, config.FormatClaude, format)
+
+	format, err = config.DetectFormat("/work/.cursor/mcp.json", []byte(`{"mcpServers": {}}`))
+	require.NoError(t, err)
+	assert.Equal(t, config.FormatCursor, format)
+
+	format, err = config.DetectFormat(".vscode/mcp.json", []byte(`{"servers": {}}`))
+	require.NoError(t, err)
+	assert.Equal(t, config.FormatVSCode, format)
+
+	format, err = config.DetectFormat("settings.json", []byte(`{"mcp": {"servers": {}}}`))
+	require.NoError(t, err)
+	assert.Equal(t, config.FormatVSCode, format)
+
+	_, err = config.DetectFormat("other.json", []byte(`{"editor.fontSize": 12}`))
+	assert.Error(t, err)
+}
+
+func TestImportVSCodeFileWithComments(t *testing.T) {
+	data := []byte(`{
+	// Servers for this workspace
+	"servers": {
+		/* the "docs" server */
+		"docs": {
+			"command": "npx",
+			"args": ["-y", "docs-mcp", "https://example.com/a//b", "/*not a comment*/",],
+		},
+	},
+}`)
+	format, err := config.DetectFormat(".vscode/mcp.json", data)
+	require.NoError(t, err)
+	assert.Equal(t, config.FormatVSCode, format)
+
+	imported, err := config.ImportServers(data, format)
+	require.NoError(t, err)
+	require.Len(t, imported, 1)
+	assert.Equal(t, "docs", imported[0].Server.ID)
+	assert.Equal(t, []string{"-y", "docs-mcp", "https://example.com/a//b", "/*not a comment*/"}, imported[0].Server.Args)
+}
+
+func TestImportClaudeDesktopServers(t *testing.T) {
+	data := []byte(`{"mcpServers": {
+		"github": {"command": "npx", "args": ["-y", "@modelcontextprotocol/server-github"], "env": {"GITHUB_TOKEN": "t"}},
+		"my files": {"command": "uvx", "args": ["mcp-files", "/home/me/My Documents"]}
+	}}`)
+	imported, err := config.ImportServers(data, config.FormatClaude)
+	require.NoError(t, err)
+	require.Len(t, imported, 2)
+
+	github := imported[0].Server
+	assert.Equal(t, "github", github.ID)
+	assert.Equal(t, types.TransportStdio, github.Transport)
+	assert.Equal(t, "npx", github.Command)
+	assert.Equal(t, []string{"-y", "@modelcontextprotocol/server-github"}, github.Args)
+	assert.Equal(t, map[string]string{"GITHUB_TOKEN": "t"}, github.Env)
+
+	files := imported[1].Server
+	assert.Equal(t, "my-files", files.ID)
+	assert.Equal(t, "my files", files.Name)
+	assert.Equal(t, []string{"mcp-files", "/home/me/My Documents"}, files.Args)
+}
+
+func TestImportVSCodeServers(t *testing.T) {
+	data := []byte(`{
+		"inputs": [{"id": "token", "type": "promptString"}],
+		"servers": {
+			"remote": {"type": "sse", "url": "https://example.com/events", "headers": {"Authorization": "Bearer x"}},
+			"local": {"type": "stdio", "command": "node", "args": ["server.js"], "env": {"TOKEN": "${input:token}"}, "cwd": "/srv"}
+		}
+	}`)
+	imported, err := config.ImportServers(data, config.FormatVSCode)
+	require.NoError(t, err)
+	require.Len(t, imported, 2)
+
+	assert.Equal(t, "node", imported[0].Server.Command)
+	assert.Equal(t, "/srv", imported[0].Server.Cwd)
+	assert.Equal(t, []string{"TOKEN refers to a VS Code input and must be filled in by hand"}, imported[0].Warnings)
+
+	remote := imported[1].Server
+	assert.Equal(t, "https://example.com/events", remote.URL)
+	assert.Equal(t, types.TransportSSE, remote.Transport)
+	assert.Equal(t, map[string]string{"Authorization": "Bearer x"}, remote.Headers)
+	assert.Empty(t, imported[1].Warnings)
+
+	_, err = config.ImportServers([]byte(`{"servers": {"bad": {"type": "stdio"}}}`), config.FormatVSCode)
+	assert.Error(t, err)
+}
+
+func TestImportGivesCollidingNamesDistinctIDs(t *testing.T) {
+	data := []byte(`{"mcpServers": {
+		"my files": {"command": "files-a"},
+		"my-files": {"command": "files-b"},
+		"my_files": {"command": "files-c"}
+	}}`)
+	imported, err := config.ImportServers(data, config.FormatClaude)
+	require.NoError(t, err)
+	require.Len(t, imported, 3)
+
+	ids := make(map[string]string)
+	for _, entry := range imported {
+		ids[entry.Server.ID] = entry.Server.Name
+	}
+	assert.Equal(t, map[string]string{"my-files": "my files", "my-files-2": "my-files", "my_files": "my_files"}, ids)
+	assert.Equal(t, []string{`ID my-files is already used by "my files"`}, imported[1].Warnings)
+}
+
+func TestMergeServersReportsConflicts(t *testing.T) {
+	cfg := &config.AppConfig{Servers: []config.MCPServer{
+		{ID: "github", URL: "stdio://npx -y @modelcontextprotocol/server-github"},
+		{ID: "files", Command: "old-files"},
+	}}
+	cfg.SetServerConfig("files", config.ServerConfig{RestartPolicy: "always"})
+
+	imported := []config.ImportedServer{
+		{Server: config.MCPServer{ID: "files", Command: "new-files", Cwd: "/data"}},
+		// The shorthand and the structured form are the same server
+		{Server: config.MCPServer{ID: "github", Command: "npx", Args: []string{"-y", "@modelcontextprotocol/server-github"}}},
+		{Server: config.MCPServer{ID: "memory", Command: "mcp-memory"}},
+	}
+	cfg.Migrate()
+	report := cfg.MergeServers(imported, false)
+	assert.Equal(t, []string{"memory"}, report.Added)
+	assert.Equal(t, []string{"github"}, report.Unchanged)
+	assert.Equal(t, []string{"files"}, report.Conflicts)
+	assert.Equal(t, "old-files", cfg.GetServer("files").Command)
+
+	report = cfg.MergeServers(imported, true)
+	assert.Equal(t, []string{"files"}, report.Replaced)
+	assert.Equal(t, []string{"github", "memory"}, report.Unchanged)
+	assert.Equal(t, "new-files", cfg.GetServer("files").Command)
+	assert.Equal(t, "/data", cfg.GetServer("files").Cwd)
+	// Settings an import cannot express are kept
+	assert.Equal(t, "always", cfg.GetServerConfig("files").RestartPolicy)
+}
+
+func TestExportServers(t *testing.T) {
+	cfg := &config.AppConfig{Servers: []config.MCPServer{
+		{ID: "files", Command: "uvx", Args: []string{"mcp-files", "/home/me/My Documents"}, Env: map[string]string{"DEBUG": "1"}},
+		{ID: "remote", URL: "https://example.com/sse", Headers: map[string]string{"X-Team": "core"}},
+	}}
+
+	data, warnings, err := cfg.ExportServers(config.FormatClaude)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"remote: remote servers cannot be expressed in claude format"}, warnings)
+	assert.JSONEq(t, `{"mcpServers": {
+		"files": {"command": "uvx", "args": ["mcp-files", "/home/me/My Documents"], "env": {"DEBUG": "1"}}
+	}}`, string(data))
+
+	data, warnings, err = cfg.ExportServers(config.FormatVSCode)
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+	var file struct {
+		Servers map[string]config.ExternalServer `json:"servers"`
+	}
+	require.NoError(t, json.Unmarshal(data, &file))
+	assert.Equal(t, "stdio", file.Servers["files"].Type)
+	assert.Equal(t, config.ExternalServer{
+		Type:    "sse",
+		URL:     "https://example.com/sse",
+		Headers: map[string]string{"X-Team": "core"},
+	}, file.Servers["remote"])
+
+	// Exported files import back to the same servers
+	imported, err := config.ImportServers(data, config.FormatVSCode)
+	require.NoError(t, err)
+	report := cfg.MergeServers(imported, false)
+	assert.Equal(t, []string{"files", "remote"}, report.Unchanged)
+}
+
+func TestImportCommandFailsOnConflicts(t *testing.T) {
+	bin := buildMcop(t)
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.json")
+	cfg := &config.AppConfig{Servers: []config.MCPServer{{ID: "files", Name: "files", Command: "old-files"}}}
+	require.NoError(t, cfg.SaveConfig(cfgPath))
+	importPath := filepath.Join(dir, "claude_desktop_config.json")
+	require.NoError(t, os.WriteFile(importPath, []byte(`{"mcpServers": {
+		"files": {"command": "new-files"},
+		"memory": {"command": "mcp-memory"}
+	}}`), 0644))
+
+	stdout, stderr, code := runMcop(t, bin, "", "--config", cfgPath, "import", importPath)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stdout, "conflict   files")
+	assert.Contains(t, stderr, "skipped 1 conflicting servers")
+
+	// The servers that did not conflict are still imported
+	saved, err := config.LoadConfig(cfgPath)
+	require.NoError(t, err)
+	assert.NotNil(t, saved.GetServer("memory"))
+	assert.Equal(t, "old-files", saved.GetServer("files").Command)
+
+	_, stderr, code = runMcop(t, bin, "", "--config", cfgPath, "import", "--overwrite", importPath)
+	assert.Equal(t, 0, code, stderr)
+}
@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"mcop/src/config"
+	"mcop/src/types"
+)
+
+func TestResolverExpand(t *testing.T) {
+	env := map[string]string{"HOST": "example.com", "EMPTY": ""}
+	resolver := &config.Resolver{
+		LookupEnv: func(name string) (string, bool) {
+			value, ok := env[name]
+			return value, ok
+		},
+		LoadSecrets: func() (map[string]string, error) {
+			return map[string]string{"github-token": "ghp_123"}, nil
+		},
+	}
+
+	cases := map[string]string{
+		"plain $HOST value":             "plain $HOST value",
+		"https://${HOST}/mcp":           "https://example.com/mcp",
+		"${PORT:-8080}":                 "8080",
+		"${EMPTY:-fallback}":            "fallback",
+		"${HOST:-other}":                "example.com",
+		"Bearer ${secret:github-token}": "Bearer ghp_123",
+		"$${HOST} stays":                "${HOST} stays",
+	}
+	for value, want := range cases {
+		got, err := resolver.Expand(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, want, got, value)
+	}
+
+	for _, value := range []string{"${MISSING}", "${secret:other}", "${HOST", "${1BAD}", "${secret:a b}"} {
+		_, err := resolver.Expand(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestReferences(t *testing.T) {
+	refs, err := config.References("${USER}:${secret:pw}@${HOST:-localhost}")
+	require.NoError(t, err)
+	assert.Equal(t, []config.Reference{
+		{Kind: config.RefEnv, Name: "USER"},
+		{Kind: config.RefSecret, Name: "pw"},
+		{Kind: config.RefEnv, Name: "HOST", Default: "localhost", HasDefault: true},
+	}, refs)
+	assert.Equal(t, "${HOST:-localhost}", refs[2].String())
+}
+
+func TestResolveServerUsesSecretsFile(t *testing.T) {
+	t.Setenv("MCOP_SECRETS_FILE", filepath.Join(t.TempDir(), "secrets.json"))
+	t.Setenv("MCOP_TEST_ROOT", "/data")
+	require.NoError(t, config.SetSecret("token", "s3cret"))
+
+	server := types.MCPServer{
+		ID:      "srv",
+		Command: "server",
+		Args:    []string{"--root", "${MCOP_TEST_ROOT}"},
+		Env:     map[string]string{"TOKEN": "${secret:token}"},
+		Headers: map[string]string{"Authorization": "Bearer ${secret:token}"},
+	}
+	resolved, err := config.ResolveServer(server)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"--root", "/data"}, resolved.Args)
+	assert.Equal(t, "s3cret", resolved.Env["TOKEN"])
+	assert.Equal(t, "Bearer s3cret", resolved.Headers["Authorization"])
+	// The original keeps its references
+	assert.Equal(t, "${secret:token}", server.Env["TOKEN"])
+	assert.Equal(t, "${MCOP_TEST_ROOT}", server.Args[1])
+
+	names, err := config.SecretNames()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"token"}, names)
+	info, err := os.Stat(os.Getenv("MCOP_SECRETS_FILE"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	removed, err := config.RemoveSecret("token")
+	require.NoError(t, err)
+	assert.True(t, removed)
+	_, err = config.ResolveServer(server)
+	assert.ErrorContains(t, err, `server srv: env.TOKEN: secret "token" is not set`)
+}
+
+func TestSaveConfigKeepsReferences(t *testing.T) {
+	t.Setenv("MODEL_API_KEY", "sk-live")
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"servers": [
+		{"id": "srv", "name": "srv", "command": "server", "env": {"TOKEN": "${API_TOKEN:-dev}"}, "description": ""}
+	]}`), 0644))
+
+	cfg, err := config.LoadConfig(path)
+	require.NoError(t, err)
+	require.NoError(t, cfg.SaveConfig(path))
+	saved, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(saved), "sk-live")
+	assert.NotContains(t, string(saved), "MODEL_API_KEY", "environment fallbacks are not saved")
+	assert.Contains(t, string(saved), "${API_TOKEN:-dev}")
+}
+
+func TestResolveServerFallsBackToDefaultURL(t *testing.T) {
+	env := map[string]string{"DEFAULT_MCP_URL": "http://localhost:9000/mcp"}
+	resolver := &config.Resolver{LookupEnv: func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}}
+
+	server, err := resolver.ResolveServer(types.MCPServer{ID: "bare"})
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:9000/mcp", server.URL)
+
+	server, err = resolver.ResolveServer(types.MCPServer{ID: "local", Command: "server"})
+	require.NoError(t, err)
+	assert.Empty(t, server.URL)
+}
@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"mcop/src/logs"
+	"mcop/src/mcp"
+	"mcop/src/types"
+)
+
+func TestLogRingKeepsNewest(t *testing.T) {
+	ring := logs.NewRing(3)
+	for i := 0; i < 5; i++ {
+		ring.Add(logs.Entry{Text: fmt.Sprint(i)})
+	}
+	require.Equal(t, 3, ring.Len())
+
+	var texts []string
+	for _, entry := range ring.Entries() {
+		texts = append(texts, entry.Text)
+	}
+	assert.Equal(t, []string{"2", "3", "4"}, texts)
+}
+
+func TestLogStoreFilters(t *testing.T) {
+	appended := 0
+	store := logs.NewStore(2, func() { appended++ })
+	store.Append(logs.Entry{ServerID: "a", Level: logs.LevelDebug, Text: "a1"})
+	store.Append(logs.Entry{ServerID: "b", Level: logs.LevelError, Text: "b1"})
+	store.Append(logs.Entry{ServerID: "a", Level: logs.LevelWarning, Text: "a2"})
+	store.Append(logs.Entry{ServerID: "a", Level: logs.LevelInfo, Text: "a3"})
+	assert.Equal(t, 4, appended)
+
+	texts := func(filter logs.Filter) []string {
+		var texts []string
+		for _, entry := range store.Entries(filter) {
+			texts = append(texts, entry.Text)
+		}
+		return texts
+	}
+	// Each server keeps its own capacity, and entries stay in append order
+	assert.Equal(t, []string{"b1", "a2", "a3"}, texts(logs.Filter{}))
+	assert.Equal(t, []string{"a2", "a3"}, texts(logs.Filter{ServerID: "a"}))
+	assert.Equal(t, []string{"b1", "a2"}, texts(logs.Filter{MinLevel: logs.LevelWarning}))
+}
+
+func TestStderrWriterSplitsLines(t *testing.T) {
+	store := logs.NewStore(10, nil)
+	w := store.StderrWriter("srv")
+	fmt.Fprint(w, "starting\nERROR: conn")
+	fmt.Fprint(w, "ection refused\r\n\n")
+
+	entries := store.Entries(logs.Filter{})
+	require.Len(t, entries, 2)
+	assert.Equal(t, "starting", entries[0].Text)
+	assert.Equal(t, logs.LevelInfo, entries[0].Level)
+	assert.Equal(t, "ERROR: connection refused", entries[1].Text)
+	assert.Equal(t, logs.LevelError, entries[1].Level)
+	assert.Equal(t, logs.SourceStderr, entries[1].Source)
+	assert.Equal(t, "srv", entries[1].ServerID)
+}
+
+func TestParseLevel(t *testing.T) {
+	level, ok := logs.ParseLevel("Warning")
+	assert.True(t, ok)
+	assert.Equal(t, logs.LevelWarning, level)
+
+	_, ok = logs.ParseLevel("verbose")
+	assert.False(t, ok)
+	assert.Equal(t, logs.LevelCritical, logs.GuessLevel("panic: runtime error"))
+}
+
+func TestServerLogsAreCaptured(t *testing.T) {
+	server := types.MCPServer{ID: "fake", URL: fakeServerURL("ok")}
+	transport, err := mcp.NewTransport(server)
+	require.NoError(t, err)
+	store := logs.NewStore(10, nil)
+	transport.(*mcp.StdioTransport).Stderr = store.StderrWriter("fake")
+
+	client := mcp.NewMCPClientWithTransport(server, transport)
+	messages := make(chan mcp.LoggingMessageParams, 1)
+	client.OnNotification("notifications/message", func(n *mcp.MCPNotification) {
+		var params mcp.LoggingMessageParams
+		json.Unmarshal(n.Params, &params)
+		messages <- params
+	})
+	require.NoError(t, client.Connect())
+	defer client.Disconnect()
+
+	ctx := context.Background()
+	require.NoError(t, client.SetLoggingLevel(ctx, "debug"))
+	_, err = client.Call(ctx, "log", nil)
+	require.NoError(t, err)
+
+	select {
+	case params := <-messages:
+		assert.Equal(t, "error", params.Level)
+		assert.Equal(t, "db", params.Logger)
+		assert.Equal(t, `{"retries":3}`, params.Text())
+	case <-time.After(5 * time.Second):
+		t.Fatal("log notification was not received")
+	}
+
+	assert.Eventually(t, func() bool {
+		entries := store.Entries(logs.Filter{ServerID: "fake", MinLevel: logs.LevelWarning})
+		return len(entries) == 1 && entries[0].Text == "WARN: disk almost full"
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,58 @@
+package tests
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"mcop/src/procstat"
+)
+
+func TestProcessTreeIncludesDescendants(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("process metrics read /proc")
+	}
+	// The shell stays alive as the parent of sleep, like npx does for a server
+	cmd := exec.Command("sh", "-c", "sleep 30 & wait")
+	require.NoError(t, cmd.Start())
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+
+	var usage procstat.Usage
+	require.Eventually(t, func() bool {
+		var err error
+		usage, err = procstat.Tree(cmd.Process.Pid)
+		return err == nil && usage.Processes == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, cmd.Process.Pid, usage.PID)
+	assert.Greater(t, usage.RSS, int64(0))
+	assert.GreaterOrEqual(t, usage.Threads, 2)
+	assert.GreaterOrEqual(t, usage.FDs, 2)
+}
+
+func TestSamplerComputesCPUPercent(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("process metrics read /proc")
+	}
+	sampler := procstat.NewSampler()
+	start := time.Now()
+	first, err := sampler.Sample("self", os.Getpid(), start)
+	require.NoError(t, err)
+	assert.Zero(t, first.CPUPercent)
+
+	// Burn some CPU so the second sample has something to measure
+	for time.Since(start) < 100*time.Millisecond {
+	}
+	second, err := sampler.Sample("self", os.Getpid(), time.Now())
+	require.NoError(t, err)
+	assert.Greater(t, second.CPUPercent, 0.0)
+
+	_, err = procstat.Tree(-1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"mcop/src/config"
+	"mcop/src/model"
+)
+
+func profileConfig() *config.AppConfig {
+	return &config.AppConfig{
+		Servers: []config.MCPServer{
+			{ID: "git", Name: "Git", Command: "git-mcp", Group: "code"},
+			{ID: "llm", Name: "LLM", Command: "llm-mcp", Tags: []string{"llm"}},
+			{ID: "lint", Name: "Lint", Command: "lint-mcp", Group: "code", Tags: []string{"review"}},
+			{ID: "db", Name: "DB", Command: "db-mcp", Group: "data"},
+		},
+		Profiles: map[string]config.Profile{
+			"dev":      {Groups: []string{"code"}, Servers: []string{"db"}},
+			"llm-only": {Tags: []string{"llm"}},
+		},
+	}
+}
+
+func TestProfileServersSelectsByIDGroupAndTag(t *testing.T) {
+	cfg := profileConfig()
+
+	servers, err := cfg.ProfileServers("dev")
+	require.NoError(t, err)
+	var ids []string
+	for _, server := range servers {
+		ids = append(ids, server.ID)
+	}
+	assert.Equal(t, []string{"git", "lint", "db"}, ids)
+
+	servers, err = cfg.ProfileServers("llm-only")
+	require.NoError(t, err)
+	require.Len(t, servers, 1)
+	assert.Equal(t, "llm", servers[0].ID)
+
+	servers, err = cfg.ProfileServers("")
+	require.NoError(t, err)
+	assert.Len(t, servers, 4)
+
+	_, err = cfg.ProfileServers("review")
+	assert.ErrorContains(t, err, "available: dev, llm-only")
+}
+
+func TestProfilesMergeAcrossLayers(t *testing.T) {
+	_, user, project := configLayers(t)
+	writeConfig(t, user, `{
+		"servers": [{"id": "git", "command": "git-mcp", "group": "code"}],
+		"profiles": {"dev": {"groups": ["code"]}, "all": {"groups": ["code"]}}
+	}`)
+	writeConfig(t, project, `{
+		"servers": [{"id": "db", "command": "db-mcp", "tags": ["data"]}],
+		"profiles": {"dev": {"servers": ["db"]}}
+	}`)
+
+	cfg, err := config.LoadConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"all", "dev"}, cfg.ProfileNames())
+	assert.Equal(t, []string{"db"}, cfg.Profiles["dev"].Servers)
+
+	cfg.SetProfile("data", config.Profile{Tags: []string{"data"}})
+	require.NoError(t, cfg.Save())
+	data, err := os.ReadFile(project)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"data"`)
+	assert.NotContains(t, string(data), `"all"`, "inherited profiles are not copied")
+}
+
+func TestUseProfileGroupsServers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, profileConfig().SaveConfig(path))
+
+	m := model.NewAppModel(path)
+	assert.Equal(t, []string{"git", "lint", "db", "llm"}, serverIDs(m), "servers are listed by group")
+	assert.True(t, m.HasGroups())
+
+	require.NoError(t, m.UseProfile("llm-only"))
+	assert.Equal(t, []string{"llm"}, serverIDs(m))
+	assert.False(t, m.HasGroups())
+	assert.Equal(t, "llm-only", m.Profile())
+
+	assert.Error(t, m.UseProfile("missing"))
+	assert.Equal(t, "llm-only", m.Profile())
+}
+
+func TestGroupStartAndStop(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	cfg := &config.AppConfig{Servers: []config.MCPServer{
+		{ID: "a", Name: "A", URL: fakeServerURL("ok"), Group: "pair"},
+		{ID: "b", Name: "B", URL: fakeServerURL("ok"), Group: "pair"},
+		{ID: "c", Name: "C", Command: "true"},
+	}}
+	require.NoError(t, cfg.SaveConfig(path))
+	m := model.NewAppModel(path)
+	t.Cleanup(func() {
+		for _, client := range m.State.MCPConnections {
+			client.Disconnect()
+		}
+	})
+
+	cmd := m.ToggleGroup(0)
+	assert.Equal(t, "connecting", m.State.Servers[0].Status)
+	runCmd(m, cmd)
+	assert.True(t, m.State.Servers[0].IsRunning())
+	assert.True(t, m.State.Servers[1].IsRunning())
+	assert.Equal(t, "stopped", m.State.Servers[2].Status, "other groups are left alone")
+
+	assert.Nil(t, m.ToggleGroup(1))
+	assert.Equal(t, "stopped", m.State.Servers[0].Status)
+	assert.Equal(t, "stopped", m.State.Servers[1].Status)
+	assert.Empty(t, m.State.MCPConnections)
+
+	// A start that completes after the group was stopped is discarded
+	cmd = m.StartGroup("pair")
+	m.StopGroup("pair")
+	runCmd(m, cmd)
+	assert.Equal(t, "stopped", m.State.Servers[0].Status)
+	assert.Empty(t, m.State.MCPConnections)
+}
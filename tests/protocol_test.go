@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"mcop/src/mcp"
+)
+
+func TestRequestIDRoundTrip(t *testing.T) {
+	for _, raw := range []string{`1`, `"abc"`, `null`} {
+		var id mcp.RequestID
+		require.NoError(t, json.Unmarshal([]byte(raw), &id))
+		out, err := json.Marshal(id)
+		require.NoError(t, err)
+		assert.Equal(t, raw, string(out))
+	}
+
+	// Numeric and string IDs with the same text must not collide
+	assert.NotEqual(t, mcp.NewNumberID(1), mcp.NewStringID("1"))
+}
+
+func TestNewRequestWireFormat(t *testing.T) {
+	req, err := mcp.NewRequest(mcp.NewNumberID(7), "tools/list", map[string]string{"cursor": "next"})
+	require.NoError(t, err)
+
+	data, err := json.Marshal(req)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":7,"method":"tools/list","params":{"cursor":"next"}}`, string(data))
+
+	notification, err := mcp.NewNotification("notifications/initialized", nil)
+	require.NoError(t, err)
+	data, err = json.Marshal(notification)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","method":"notifications/initialized"}`, string(data))
+}
+
+func TestParseMessages(t *testing.T) {
+	messages, err := mcp.ParseMessages([]byte(`{"jsonrpc":"2.0","id":"a","method":"ping"}`))
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.IsType(t, &mcp.MCPRequest{}, messages[0])
+
+	messages, err = mcp.ParseMessages([]byte(`{"jsonrpc":"2.0","method":"notifications/tools/list_changed"}`))
+	require.NoError(t, err)
+	assert.IsType(t, &mcp.MCPNotification{}, messages[0])
+
+	messages, err = mcp.ParseMessages([]byte(`[{"jsonrpc":"2.0","id":1,"result":{}},` +
+		`{"jsonrpc":"2.0","id":2,"error":{"code":-32601,"message":"nope","data":{"method":"x"}}}]`))
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	resp := messages[1].(*mcp.MCPResponse)
+	assert.Equal(t, mcp.NewNumberID(2), resp.ID)
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, mcp.ErrCodeMethodNotFound, resp.Error.Code)
+	assert.JSONEq(t, `{"method":"x"}`, string(resp.Error.Data))
+
+	_, err = mcp.ParseMessages([]byte(`{"method":"ping","id":1,"version":"1.0"}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"mcop/src/mcp"
+	"mcop/src/proxy"
+	"mcop/src/types"
+)
+
+// startBridge connects a bridge to an in-process fake stdio server
+func startBridge(t *testing.T) *proxy.Bridge {
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	t.Cleanup(func() {
+		stdinWriter.Close()
+		stdoutWriter.Close()
+	})
+
+	go func() {
+		scanner := bufio.NewScanner(stdinReader)
+		out := json.NewEncoder(stdoutWriter)
+		for scanner.Scan() {
+			msg, err := mcp.ParseMessage(scanner.Bytes())
+			if err != nil {
+				continue
+			}
+			if req, ok := msg.(*mcp.MCPRequest); ok {
+				if resp := fakeResponse("ok", req); resp != nil {
+					out.Encode(resp)
+				}
+			}
+		}
+	}()
+
+	bridge := proxy.NewBridge(stdinWriter)
+	go bridge.Run(stdoutReader)
+	return bridge
+}
+
+func TestBridgeServesStreamableHTTP(t *testing.T) {
+	srv := httptest.NewServer(startBridge(t))
+	defer srv.Close()
+
+	client := mcp.NewMCPClient(types.MCPServer{ID: "bridged", URL: srv.URL + "/mcp"})
+	require.NoError(t, client.Connect())
+	defer client.Disconnect()
+
+	tools, err := client.ListTools(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"echo", "fail", "hang"}, types.ToolNames(tools))
+}
+
+func TestBridgeServesTCP(t *testing.T) {
+	bridge := startBridge(t)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go bridge.Serve(ctx, "tcp", ln)
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	req, err := mcp.NewRequest(mcp.NewStringID("a"), "echo", map[string]int{"n": 1})
+	require.NoError(t, err)
+	require.NoError(t, json.NewEncoder(conn).Encode(req))
+
+	reply, err := bufio.NewReader(conn).ReadBytes('\n')
+	require.NoError(t, err)
+	msg, err := mcp.ParseMessage(reply)
+	require.NoError(t, err)
+	resp, ok := msg.(*mcp.MCPResponse)
+	require.True(t, ok)
+	assert.Equal(t, mcp.NewStringID("a"), resp.ID)
+	assert.JSONEq(t, `{"n":1}`, string(resp.Result))
+}
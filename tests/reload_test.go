@@ -0,0 +1,151 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"mcop/src/config"
+	"mcop/src/mcp"
+	"mcop/src/model"
+	"mcop/src/types"
+)
+
+// runCmd executes a command and feeds the messages it produces back into m
+func runCmd(m *model.AppModel, cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	switch msg := cmd().(type) {
+	case tea.BatchMsg:
+		for _, c := range msg {
+			runCmd(m, c)
+		}
+	case nil:
+	default:
+		_, next := m.Update(msg)
+		runCmd(m, next)
+	}
+}
+
+func serverIDs(m *model.AppModel) []string {
+	var ids []string
+	for _, server := range m.State.Servers {
+		ids = append(ids, server.ID)
+	}
+	return ids
+}
+
+func TestReloadConfigAppliesChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	cfg := &config.AppConfig{Servers: []config.MCPServer{
+		{ID: "keep", Name: "Keep", URL: fakeServerURL("ok")},
+		{ID: "gone", Name: "Gone", URL: fakeServerURL("ok")},
+		{ID: "idle", Name: "Idle", Command: "true"},
+	}}
+	require.NoError(t, cfg.SaveConfig(path))
+
+	m := model.NewAppModel(path)
+	cmd := m.ToggleServer(0)
+	assert.Equal(t, "connecting", m.State.Servers[0].Status, "servers start in the background")
+	runCmd(m, cmd)
+	require.True(t, m.State.Servers[0].IsRunning())
+	runCmd(m, m.ToggleServer(1))
+	require.True(t, m.State.Servers[1].IsRunning())
+	gone := m.State.MCPConnections["gone"]
+	t.Cleanup(func() {
+		for _, client := range m.State.MCPConnections {
+			client.Disconnect()
+		}
+	})
+
+	cfg.Servers = []config.MCPServer{
+		{ID: "keep", Name: "Keep", URL: fakeServerURL("changed")},
+		{ID: "idle", Name: "Idle", Command: "false"},
+		{ID: "fresh", Name: "Fresh", Command: "true"},
+	}
+	require.NoError(t, cfg.SaveConfig(path))
+	m.Update(model.ConfigChangedMsg{})
+
+	assert.Equal(t, []string{"keep", "idle", "fresh"}, serverIDs(m))
+	assert.Equal(t, "false", m.State.Servers[1].Command)
+	assert.NotContains(t, m.State.MCPConnections, "gone")
+	select {
+	case <-gone.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("removed server was not stopped")
+	}
+
+	assert.Equal(t, []string{"keep"}, m.State.PendingRestart)
+	assert.Equal(t, []string{"Keep"}, m.PendingRestartNames())
+	old := m.State.MCPConnections["keep"]
+	runCmd(m, m.RestartPending())
+	assert.Empty(t, m.State.PendingRestart)
+	require.True(t, m.State.Servers[0].IsRunning())
+	assert.NotSame(t, old, m.State.MCPConnections["keep"])
+	assert.Contains(t, m.State.MCPConnections["keep"].Server.Args, "changed")
+}
+
+func TestStdioShutdownLetsTheServerExit(t *testing.T) {
+	server := types.MCPServer{ID: "fake", URL: fakeServerURL("ok")}
+	transport, err := mcp.NewTransport(server)
+	require.NoError(t, err)
+	client := mcp.NewMCPClientWithTransport(server, transport)
+	require.NoError(t, client.Connect())
+
+	start := time.Now()
+	require.NoError(t, client.Shutdown(5*time.Second))
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	stdio := transport.(*mcp.StdioTransport)
+	<-stdio.Exited()
+	assert.NoError(t, stdio.ExitErr(), "the server should exit on its own when stdin closes")
+}
+
+func TestReloadConfigKeepsServersWhenTheFileIsMissing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	cfg := &config.AppConfig{Servers: []config.MCPServer{{ID: "mine", Name: "Mine", Command: "true"}}}
+	require.NoError(t, cfg.SaveConfig(path))
+	m := model.NewAppModel(path)
+
+	require.NoError(t, os.Remove(path))
+	m.Update(model.ConfigChangedMsg{})
+	assert.Equal(t, []string{"mine"}, serverIDs(m), "a missing file must not reload as the defaults")
+
+	require.NoError(t, cfg.SaveConfig(path))
+	m.Update(model.ConfigChangedMsg{})
+	assert.Equal(t, []string{"mine"}, serverIDs(m))
+}
+
+func TestReloadConfigRestartsConnectingServers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	cfg := &config.AppConfig{Servers: []config.MCPServer{{ID: "slow", Name: "Slow", URL: fakeServerURL("ok")}}}
+	require.NoError(t, cfg.SaveConfig(path))
+
+	m := model.NewAppModel(path)
+	launch := m.ToggleServer(0)
+	require.Equal(t, "connecting", m.State.Servers[0].Status)
+	t.Cleanup(func() {
+		for _, client := range m.State.MCPConnections {
+			client.Disconnect()
+		}
+	})
+
+	cfg.Servers = []config.MCPServer{{ID: "slow", Name: "Slow", URL: fakeServerURL("changed")}}
+	require.NoError(t, cfg.SaveConfig(path))
+	m.Update(model.ConfigChangedMsg{})
+	assert.Equal(t, []string{"slow"}, m.State.PendingRestart, "the launch in flight uses the old settings")
+
+	relaunch := m.RestartPending()
+	assert.Empty(t, m.State.PendingRestart)
+	// The superseded launch finishing first does not win
+	runCmd(m, launch)
+	assert.Equal(t, "connecting", m.State.Servers[0].Status)
+	runCmd(m, relaunch)
+	require.True(t, m.State.Servers[0].IsRunning())
+	assert.Contains(t, m.State.MCPConnections["slow"].Server.Args, "changed")
+}
@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"mcop/src/mcp"
+	"mcop/src/model"
+)
+
+const searchSchema = `{
+	"type": "object",
+	"properties": {
+		"query": {"type": "string", "minLength": 2},
+		"limit": {"type": "integer", "default": 10, "maximum": 100},
+		"sort": {"enum": ["relevance", "date"]},
+		"exact": {"type": "boolean"},
+		"tags": {"type": "array", "items": {"type": "string"}},
+		"filter": {
+			"type": "object",
+			"properties": {
+				"owner": {"type": "string"},
+				"stars": {"type": ["number", "null"]}
+			},
+			"required": ["owner"]
+		},
+		"extra": {"type": "object"}
+	},
+	"required": ["query", "filter"]
+}`
+
+func TestParseSchemaKeepsPropertyOrder(t *testing.T) {
+	schema, err := mcp.ParseSchema(json.RawMessage(searchSchema))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"query", "limit", "sort", "exact", "tags", "filter", "extra"}, schema.PropertyNames())
+	assert.Equal(t, "string", schema.Properties["sort"].PrimaryType())
+	assert.Equal(t, "number", schema.Properties["filter"].Properties["stars"].PrimaryType())
+
+	empty, err := mcp.ParseSchema(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "object", empty.PrimaryType())
+}
+
+func TestSchemaValidate(t *testing.T) {
+	schema, err := mcp.ParseSchema(json.RawMessage(searchSchema))
+	require.NoError(t, err)
+
+	assert.Empty(t, schema.Validate(map[string]interface{}{
+		"query":  "mcp",
+		"filter": map[string]interface{}{"owner": "me", "stars": nil},
+	}))
+
+	problems := schema.Validate(map[string]interface{}{
+		"query":  "x",
+		"limit":  int64(500),
+		"sort":   "name",
+		"tags":   []interface{}{"a", 1.0},
+		"filter": map[string]interface{}{},
+	})
+	assert.ElementsMatch(t, []string{
+		"query: must be at least 2 characters",
+		"limit: must be <= 100",
+		`sort: must be one of "relevance", "date"`,
+		"tags[1]: must be of type string",
+		`filter: missing required property "owner"`,
+	}, problems)
+}
+
+func TestSchemaFormBuildsArguments(t *testing.T) {
+	schema, err := mcp.ParseSchema(json.RawMessage(searchSchema))
+	require.NoError(t, err)
+
+	form := model.NewSchemaForm(schema)
+	labels := make([]string, len(form.Fields))
+	for i, field := range form.Fields {
+		labels[i] = field.Label
+	}
+	assert.Equal(t, []string{"query", "limit", "sort", "exact", "tags", "filter.owner", "filter.stars", "extra"}, labels)
+	assert.True(t, form.Fields[0].Required)
+	assert.Equal(t, "10", form.Fields[1].Value)
+	assert.Equal(t, model.FieldEnum, form.Fields[2].Kind)
+	assert.Equal(t, model.FieldList, form.Fields[4].Kind)
+	assert.True(t, form.Fields[5].Required)
+	assert.Equal(t, model.FieldJSON, form.Fields[7].Kind)
+
+	_, err = form.Arguments()
+	assert.Error(t, err, "required fields are empty")
+
+	form.Fields[0].Value = "mcp"
+	form.Fields[2].Value = "date"
+	form.Fields[3].Value = "true"
+	form.Fields[4].Value = "go, tui"
+	form.Fields[5].Value = "me"
+	form.Fields[7].Value = `{"a": 1}`
+	args, err := form.Arguments()
+	require.NoError(t, err)
+
+	encoded, err := json.Marshal(args)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"query": "mcp",
+		"limit": 10,
+		"sort": "date",
+		"exact": true,
+		"tags": ["go", "tui"],
+		"filter": {"owner": "me"},
+		"extra": {"a": 1}
+	}`, string(encoded))
+
+	form.Fields[1].Value = "ten"
+	_, err = form.Arguments()
+	assert.EqualError(t, err, "limit: must be an integer")
+}
@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"mcop/src/mcp"
+	"mcop/src/types"
+)
+
+// recordSession records a session with the fake server and returns its frames
+func recordSession(t *testing.T) []mcp.SessionFrame {
+	server := types.MCPServer{ID: "fake", URL: fakeServerURL("ok")}
+	transport, err := mcp.NewTransport(server)
+	require.NoError(t, err)
+
+	var recording bytes.Buffer
+	recorder := mcp.NewRecordingTransport(transport, &recording)
+	client := mcp.NewMCPClientWithTransport(server, recorder)
+	require.NoError(t, client.Connect())
+
+	ctx := context.Background()
+	_, err = client.Call(ctx, "echo", map[string]int{"n": 1})
+	require.NoError(t, err)
+	_, err = client.Call(ctx, "echo", map[string]int{"n": 2})
+	require.NoError(t, err)
+	_, err = client.Call(ctx, "fail", nil)
+	require.Error(t, err)
+	_, err = client.Call(ctx, "noise", nil)
+	require.NoError(t, err)
+	client.Disconnect()
+	require.NoError(t, recorder.Err())
+
+	frames, err := mcp.LoadSession(&recording)
+	require.NoError(t, err)
+	return frames
+}
+
+func TestRecordingTransportWritesFrames(t *testing.T) {
+	frames := recordSession(t)
+
+	var directions []string
+	for _, frame := range frames {
+		directions = append(directions, frame.Direction)
+		assert.True(t, json.Valid(frame.Message))
+		assert.GreaterOrEqual(t, frame.OffsetMS, 0.0)
+	}
+	// initialize, initialized, four requests; the noise line is not recorded
+	assert.Equal(t, []string{
+		mcp.FrameSent, mcp.FrameReceived, mcp.FrameSent,
+		mcp.FrameSent, mcp.FrameReceived, mcp.FrameSent, mcp.FrameReceived,
+		mcp.FrameSent, mcp.FrameReceived, mcp.FrameSent, mcp.FrameReceived,
+	}, directions)
+	for i := 1; i < len(frames); i++ {
+		assert.GreaterOrEqual(t, frames[i].OffsetMS, frames[i-1].OffsetMS)
+	}
+}
+
+func TestReplayTransportAnswersFromRecording(t *testing.T) {
+	frames := recordSession(t)
+
+	server := types.MCPServer{ID: "replay"}
+	client := mcp.NewMCPClientWithTransport(server, mcp.NewReplayTransport(frames))
+	require.NoError(t, client.Connect())
+	defer client.Disconnect()
+	assert.Equal(t, "fake", client.ServerInfo().Name)
+
+	// Requests are matched by params regardless of order
+	ctx := context.Background()
+	var out map[string]int
+	require.NoError(t, client.CallResult(ctx, "echo", map[string]int{"n": 2}, &out))
+	assert.Equal(t, 2, out["n"])
+	// Params that were not recorded are not answered from another recording
+	_, err := client.Call(ctx, "echo", map[string]int{"n": 3})
+	var rpcErr *mcp.MCPError
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, mcp.ErrCodeMethodNotFound, rpcErr.Code)
+	assert.Contains(t, rpcErr.Message, "no recorded response for echo")
+
+	_, err = client.Call(ctx, "fail", nil)
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, mcp.ErrCodeInvalidParams, rpcErr.Code)
+}
+
+func TestReplayTransportLooseMatching(t *testing.T) {
+	replay := mcp.NewReplayTransport(recordSession(t))
+	replay.Loose = true
+	client := mcp.NewMCPClientWithTransport(types.MCPServer{ID: "replay"}, replay)
+	require.NoError(t, client.Connect())
+	defer client.Disconnect()
+
+	// Falls back to the remaining recordings of the method
+	ctx := context.Background()
+	var out map[string]int
+	require.NoError(t, client.CallResult(ctx, "echo", map[string]int{"n": 3}, &out))
+	assert.Equal(t, 1, out["n"])
+	require.NoError(t, client.CallResult(ctx, "echo", map[string]int{"n": 4}, &out))
+	assert.Equal(t, 2, out["n"])
+
+	_, err := client.Call(ctx, "echo", nil)
+	var rpcErr *mcp.MCPError
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Contains(t, rpcErr.Message, "no recorded response for echo")
+}
+
+func TestRelayWaitsForResponses(t *testing.T) {
+	frames := recordSession(t)
+
+	in := bytes.NewBufferString(`{"jsonrpc":"2.0","id":"a","method":"echo","params":{"n":1}}` + "\n" +
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}` + "\n")
+	reader, writer := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- mcp.Relay(context.Background(), mcp.NewReplayTransport(frames), in, writer)
+		writer.Close()
+	}()
+
+	scanner := bufio.NewScanner(reader)
+	require.True(t, scanner.Scan())
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":"a","result":{"n":1}}`, scanner.Text())
+	assert.False(t, scanner.Scan())
+	require.NoError(t, <-done)
+}
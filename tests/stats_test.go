@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"mcop/src/config"
+	"mcop/src/mcp"
+	"mcop/src/model"
+	"mcop/src/stats"
+	"mcop/src/types"
+)
+
+func TestSeriesSummary(t *testing.T) {
+	var series stats.Series
+	now := time.Date(2025, 1, 1, 12, 0, 55, 0, time.UTC)
+	for i := 1; i <= 100; i++ {
+		at := now.Add(-time.Duration(i%50) * time.Second)
+		series.Record(at, time.Duration(i)*time.Millisecond, i%10 == 0)
+	}
+	// Older than the window
+	series.Record(now.Add(-5*time.Minute), time.Hour, true)
+
+	summary := series.Summary(now, time.Minute)
+	assert.Equal(t, 100, summary.Requests)
+	assert.Equal(t, 10, summary.Errors)
+	assert.InDelta(t, 100, summary.RequestsPerMinute, 0.001)
+	assert.InDelta(t, 0.1, summary.ErrorRate, 0.001)
+	assert.Equal(t, 50*time.Millisecond, summary.P50)
+	assert.Equal(t, 95*time.Millisecond, summary.P95)
+	assert.Equal(t, 99*time.Millisecond, summary.P99)
+}
+
+func TestSeriesHistory(t *testing.T) {
+	var series stats.Series
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	series.Record(now.Add(-25*time.Second), 10*time.Millisecond, false)
+	series.Record(now, 30*time.Millisecond, true)
+	series.Record(now, 20*time.Millisecond, false)
+
+	history := series.History(now, 4)
+	require.Len(t, history, 4)
+	assert.Equal(t, 1, history[0].Requests)
+	assert.Equal(t, 0, history[1].Requests)
+	assert.Equal(t, 0, history[2].Requests)
+	assert.Equal(t, 2, history[3].Requests)
+	assert.Equal(t, 1, history[3].Errors)
+	assert.Equal(t, 30*time.Millisecond, history[3].P95)
+
+	// Ten minutes later every bucket has been recycled
+	later := now.Add(stats.HistoryBuckets * stats.BucketWidth)
+	series.Record(later, time.Millisecond, false)
+	assert.Equal(t, 1, series.Summary(later, time.Duration(stats.HistoryBuckets)*stats.BucketWidth).Requests)
+}
+
+func TestClientReportsCalls(t *testing.T) {
+	client := mcp.NewMCPClient(types.MCPServer{ID: "fake", URL: fakeServerURL("ok")})
+	require.NoError(t, client.Connect())
+	defer client.Disconnect()
+
+	store := stats.NewStore()
+	var methods []string
+	client.OnCall(func(method string, latency time.Duration, err error) {
+		methods = append(methods, method)
+		store.Record("fake", time.Now(), latency, err != nil)
+	})
+
+	ctx := context.Background()
+	_, err := client.Call(ctx, "echo", nil)
+	require.NoError(t, err)
+	_, err = client.Call(ctx, "fail", nil)
+	require.Error(t, err)
+
+	assert.Equal(t, []string{"echo", "fail"}, methods)
+	summary := store.Summary("fake", time.Now(), time.Minute)
+	assert.Equal(t, 2, summary.Requests)
+	assert.InDelta(t, 0.5, summary.ErrorRate, 0.001)
+	assert.Greater(t, summary.P99, time.Duration(0))
+}
+
+func TestModelStatsLeaveOutPings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	cfg := &config.AppConfig{Servers: []config.MCPServer{{ID: "fake", Name: "Fake", URL: fakeServerURL("ok")}}}
+	require.NoError(t, cfg.SaveConfig(path))
+	m := model.NewAppModel(path)
+	runCmd(m, m.ToggleServer(0))
+	client := m.State.MCPConnections["fake"]
+	require.NotNil(t, client)
+	defer client.Disconnect()
+
+	before := m.Stats.Summary("fake", time.Now(), time.Minute).Requests
+	ctx := context.Background()
+	_, err := client.Ping(ctx)
+	require.NoError(t, err)
+	_, err = client.Call(ctx, "echo", nil)
+	require.NoError(t, err)
+	assert.Equal(t, before+1, m.Stats.Summary("fake", time.Now(), time.Minute).Requests)
+}
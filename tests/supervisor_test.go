@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"mcop/src/config"
+	"mcop/src/mcp"
+	"mcop/src/supervisor"
+	"mcop/src/types"
+)
+
+func TestSupervisorPolicies(t *testing.T) {
+	s := supervisor.New()
+	now := time.Now()
+
+	s.Configure("never", supervisor.ConfigFor(config.ServerConfig{}))
+	assert.False(t, s.Exited("never", true, now).Restart)
+
+	s.Configure("on-failure", supervisor.ConfigFor(config.ServerConfig{RestartPolicy: "on-failure"}))
+	assert.False(t, s.Exited("on-failure", false, now).Restart)
+	assert.True(t, s.Exited("on-failure", true, now).Restart)
+
+	s.Configure("always", supervisor.ConfigFor(config.ServerConfig{RestartPolicy: "always"}))
+	assert.True(t, s.Exited("always", false, now).Restart)
+
+	_, err := supervisor.ParsePolicy("sometimes")
+	assert.Error(t, err)
+}
+
+func TestSupervisorBackoffAndLimit(t *testing.T) {
+	s := supervisor.New()
+	s.Configure("srv", supervisor.ConfigFor(config.ServerConfig{
+		RestartPolicy:     "always",
+		MaxRestarts:       4,
+		RestartWindow:     60,
+		RestartBackoff:    100,
+		RestartMaxBackoff: 300,
+	}))
+
+	now := time.Now()
+	var delays []time.Duration
+	for i := 0; i < 4; i++ {
+		decision := s.Exited("srv", true, now)
+		require.True(t, decision.Restart)
+		assert.Equal(t, i+1, decision.Attempt)
+		assert.True(t, s.TakeRestart("srv"))
+		delays = append(delays, decision.Delay)
+	}
+	assert.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond}, delays)
+
+	decision := s.Exited("srv", true, now)
+	assert.False(t, decision.Restart)
+	assert.True(t, decision.GaveUp)
+
+	// Restarts older than the window no longer count
+	decision = s.Exited("srv", true, now.Add(2*time.Minute))
+	assert.True(t, decision.Restart)
+	assert.Equal(t, 100*time.Millisecond, decision.Delay)
+
+	s.Cancel("srv")
+	assert.False(t, s.TakeRestart("srv"))
+}
+
+func TestStdioTransportReportsExit(t *testing.T) {
+	client := mcp.NewMCPClient(types.MCPServer{ID: "fake", URL: fakeServerURL("ok")})
+	require.NoError(t, client.Connect())
+	defer client.Disconnect()
+
+	stdio, ok := client.Transport().(*mcp.StdioTransport)
+	require.True(t, ok)
+
+	_, err := client.Call(context.Background(), "crash", nil)
+	assert.ErrorIs(t, err, mcp.ErrConnectionClosed)
+
+	select {
+	case <-stdio.Exited():
+	case <-time.After(5 * time.Second):
+		t.Fatal("process exit was not reported")
+	}
+	var exitErr *exec.ExitError
+	require.True(t, errors.As(stdio.ExitErr(), &exitErr))
+	assert.Equal(t, 1, exitErr.ExitCode())
+}
@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"mcop/src/mcp"
+	"mcop/src/model"
+	"mcop/src/types"
+)
+
+func TestClientTracesFrames(t *testing.T) {
+	client := mcp.NewMCPClient(types.MCPServer{ID: "fake", URL: fakeServerURL("ok")})
+	var mu sync.Mutex
+	var frames []mcp.Frame
+	client.OnFrame(func(frame mcp.Frame) {
+		mu.Lock()
+		frames = append(frames, frame)
+		mu.Unlock()
+	})
+	require.NoError(t, client.Connect())
+	defer client.Disconnect()
+
+	ctx := context.Background()
+	_, err := client.Call(ctx, "fail", nil)
+	require.Error(t, err)
+	_, err = client.Call(ctx, "noise", nil)
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	var summary []string
+	for _, frame := range frames {
+		summary = append(summary, frame.Direction+" "+frame.Kind+" "+frame.Method)
+	}
+	assert.Equal(t, []string{
+		"sent request initialize",
+		"received response initialize",
+		"sent notification notifications/initialized",
+		"sent request fail",
+		"received response fail",
+		"sent request noise",
+		"received invalid ",
+		"received response noise",
+	}, summary)
+
+	initialized := frames[1]
+	assert.Equal(t, "1", initialized.ID)
+	assert.Greater(t, initialized.Latency, time.Duration(0))
+	assert.Equal(t, len(initialized.Data), initialized.Size)
+	assert.True(t, frames[4].IsError)
+	assert.True(t, frames[6].IsError)
+	assert.Equal(t, "not json-rpc", string(frames[6].Data))
+}
+
+func TestTrafficInspectorFilters(t *testing.T) {
+	inspector := model.TrafficInspector{MethodFilter: "TOOLS"}
+	assert.True(t, inspector.Match(mcp.Frame{Method: "tools/list"}))
+	assert.False(t, inspector.Match(mcp.Frame{Method: "ping"}))
+
+	inspector = model.TrafficInspector{ErrorsOnly: true}
+	assert.True(t, inspector.Match(mcp.Frame{Method: "tools/call", IsError: true}))
+	assert.False(t, inspector.Match(mcp.Frame{Method: "tools/call"}))
+}
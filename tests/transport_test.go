@@ -0,0 +1,184 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"mcop/src/mcp"
+	"mcop/src/types"
+)
+
+// readRequest decodes the single frame POSTed in r
+func readRequest(t *testing.T, r *http.Request) mcp.Message {
+	body, err := io.ReadAll(r.Body)
+	require.NoError(t, err)
+	msg, err := mcp.ParseMessage(body)
+	require.NoError(t, err)
+	return msg
+}
+
+func TestNewTransportSelectsByURL(t *testing.T) {
+	cases := map[string]interface{}{
+		"stdio://npx @modelcontextprotocol/server-github": &mcp.StdioTransport{},
+		"http://localhost:8000/sse":                       &mcp.SSETransport{},
+		"https://example.com/mcp":                         &mcp.StreamableHTTPTransport{},
+	}
+	for url, want := range cases {
+		transport, err := mcp.NewTransport(types.MCPServer{URL: url})
+		require.NoError(t, err, url)
+		assert.IsType(t, want, transport, url)
+	}
+
+	_, err := mcp.NewTransport(types.MCPServer{URL: "ftp://example.com"})
+	assert.Error(t, err)
+}
+
+func TestNewTransportUsesLaunchSettings(t *testing.T) {
+	transport, err := mcp.NewTransport(types.MCPServer{
+		Command: "node",
+		Args:    []string{"server.js", "--root", "/my docs"},
+		Env:     map[string]string{"TOKEN": "t"},
+		Cwd:     "/srv",
+	})
+	require.NoError(t, err)
+	stdio := transport.(*mcp.StdioTransport)
+	assert.Equal(t, "node", stdio.Command)
+	assert.Equal(t, []string{"server.js", "--root", "/my docs"}, stdio.Args)
+	assert.Equal(t, "TOKEN=t", stdio.Env[len(stdio.Env)-1])
+	assert.Equal(t, "/srv", stdio.Dir)
+
+	// An explicit transport wins over the URL convention
+	transport, err = mcp.NewTransport(types.MCPServer{
+		Transport: types.TransportSSE,
+		URL:       "https://example.com/events",
+		Headers:   map[string]string{"Authorization": "Bearer x"},
+	})
+	require.NoError(t, err)
+	sse := transport.(*mcp.SSETransport)
+	assert.Equal(t, "Bearer x", sse.Headers.Get("Authorization"))
+
+	_, err = mcp.NewTransport(types.MCPServer{Transport: "websocket", URL: "wss://example.com"})
+	assert.ErrorContains(t, err, `unknown transport "websocket"`)
+	_, err = mcp.NewTransport(types.MCPServer{Transport: types.TransportHTTP, URL: "stdio://node"})
+	assert.Error(t, err)
+}
+
+func TestStreamableHTTPTransport(t *testing.T) {
+	var mu sync.Mutex
+	var sessionHeaders []string
+	deleted := false
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		case http.MethodDelete:
+			mu.Lock()
+			deleted = r.Header.Get(mcp.SessionIDHeader) == "session-1"
+			mu.Unlock()
+			return
+		}
+
+		msg := readRequest(t, r)
+		req, ok := msg.(*mcp.MCPRequest)
+		if !ok {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		mu.Lock()
+		sessionHeaders = append(sessionHeaders, r.Header.Get(mcp.SessionIDHeader))
+		mu.Unlock()
+
+		data, _ := json.Marshal(fakeResponse("ok", req))
+		if req.Method == "initialize" {
+			w.Header().Set(mcp.SessionIDHeader, "session-1")
+		}
+		if req.Method == "echo" {
+			// Answer over an SSE stream, preceded by an unrelated notification
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\"}\n\n")
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	client := mcp.NewMCPClient(types.MCPServer{ID: "http", URL: server.URL + "/mcp"})
+	require.NoError(t, client.Connect())
+	assert.Equal(t, "fake", client.ServerInfo().Name)
+
+	var out map[string]string
+	require.NoError(t, client.CallResult(context.Background(), "echo", map[string]string{"a": "b"}, &out))
+	assert.Equal(t, "b", out["a"])
+
+	_, err := client.Call(context.Background(), "fail", nil)
+	assert.Error(t, err)
+
+	require.NoError(t, client.Disconnect())
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"", "session-1", "session-1"}, sessionHeaders)
+	assert.True(t, deleted)
+}
+
+func TestSSETransport(t *testing.T) {
+	events := make(chan []byte, 10)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sse", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: endpoint\ndata: /messages?session=abc\n\n")
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case data := <-events:
+				fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+				w.(http.Flusher).Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	})
+	mux.HandleFunc("/messages", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "abc", r.URL.Query().Get("session"))
+		if req, ok := readRequest(t, r).(*mcp.MCPRequest); ok {
+			data, _ := json.Marshal(fakeResponse("ok", req))
+			events <- data
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := mcp.NewMCPClient(types.MCPServer{ID: "sse", URL: server.URL + "/sse"})
+	require.NoError(t, client.Connect())
+	defer client.Disconnect()
+
+	var out map[string]int
+	require.NoError(t, client.CallResult(context.Background(), "echo", map[string]int{"n": 3}, &out))
+	assert.Equal(t, 3, out["n"])
+}
+
+func TestSSETransportWithoutEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+	}))
+	defer server.Close()
+
+	transport := mcp.NewSSETransport(server.URL + "/sse")
+	err := transport.Start(context.Background())
+	assert.ErrorContains(t, err, "closed before the server announced its endpoint")
+}
@@ -0,0 +1,189 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"mcop/src/config"
+)
+
+// problemLines maps each problem's path to the line it was reported at
+func problemLines(problems []config.Problem) map[string]int {
+	lines := make(map[string]int)
+	for _, problem := range problems {
+		lines[problem.Path] = problem.Line
+	}
+	return lines
+}
+
+func TestPublishedSchemaIsUpToDate(t *testing.T) {
+	// Another test changes the working directory, so find the file from here
+	_, file, _, _ := runtime.Caller(0)
+	published, err := os.ReadFile(filepath.Join(filepath.Dir(file), "..", "config", "schema.json"))
+	require.NoError(t, err)
+	generated, err := config.SchemaJSON()
+	require.NoError(t, err)
+	assert.Equal(t, string(generated), string(published), "run go generate ./src/config")
+}
+
+func TestValidateDataReportsPositions(t *testing.T) {
+	data := `{
+  "servers": [
+    {"id": "api", "command": "node", "args": ["api.js", "--port", "8080"]},
+    {"id": "api", "url": "ftp://example.com"},
+    {"id": "web", "url": "http://localhost:8080/mcp", "transport": "websocket"},
+    {"id": "db", "command": "db", "env": {"TOKEN": "${UNSET_TOKEN}"}, "colour": "red"}
+  ],
+  "refresh_rate": 0,
+  "server_configs": {"api": {"restart_policy": "sometimes"}}
+}`
+	resolver := &config.Resolver{LookupEnv: func(string) (string, bool) { return "", false }}
+	problems := config.ValidateData("config.json", []byte(data), resolver)
+
+	assert.Equal(t, map[string]int{
+		"servers[1].id":                     4,
+		"servers[1].url":                    4,
+		"servers[2].url":                    5,
+		"servers[2].transport":              5,
+		"servers[3].env.TOKEN":              6,
+		"servers[3].colour":                 6,
+		"refresh_rate":                      8,
+		"server_configs.api.restart_policy": 9,
+	}, problemLines(problems))
+	for _, problem := range problems {
+		assert.Equal(t, "config.json", problem.File)
+		assert.Equal(t, config.SeverityError, problem.Severity)
+	}
+	assert.Contains(t, problems[0].String(), `config.json:4:6: error: servers[1].id: duplicate server ID "api"`)
+
+	// Without a resolver the environment is not consulted
+	problems = config.ValidateData("config.json", []byte(data), nil)
+	assert.NotContains(t, problemLines(problems), "servers[3].env.TOKEN")
+}
+
+func TestValidateDataLocatesYAMLAndTOML(t *testing.T) {
+	yamlData := `servers:
+  - id: a
+    command: x
+  # the same ID again
+  - id: a
+    transport: sse
+    url: https://example.com/sse
+refresh_rate: often
+`
+	assert.Equal(t, map[string]int{"servers[1].id": 5, "refresh_rate": 8},
+		problemLines(config.ValidateData("config.yaml", []byte(yamlData), nil)))
+
+	tomlData := `# mcop
+refresh_rate = 5
+
+[[servers]]
+id = "a"
+command = "x"
+
+[[servers]]
+id = "b"
+transport = "sse"
+command = "y"
+
+[server_configs.a]
+restart_policy = "never"
+restart_backoff_ms = 1000
+restart_max_backoff_ms = 500
+`
+	problems := config.ValidateData("config.toml", []byte(tomlData), nil)
+	assert.Equal(t, map[string]int{
+		"servers[1].transport":                    10,
+		"server_configs.a.restart_max_backoff_ms": 16,
+	}, problemLines(problems))
+	require.Len(t, problems, 2)
+	assert.Equal(t, config.SeverityWarning, problems[1].Severity)
+}
+
+func TestValidateDataReportsSyntaxErrors(t *testing.T) {
+	problems := config.ValidateData("config.json", []byte("{\n  \"servers\": [\n    {\"id\": }\n  ]\n}"), nil)
+	require.Len(t, problems, 1)
+	assert.Equal(t, 3, problems[0].Line)
+
+	problems = config.ValidateData("config.toml", []byte("refresh_rate = 5\nservers = [\n"), nil)
+	require.Len(t, problems, 1)
+	assert.Equal(t, 2, problems[0].Line)
+}
+
+func TestValidateAllowsValidConfig(t *testing.T) {
+	cfg := config.DefaultConfig()
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Servers = append(cfg.Servers, config.MCPServer{ID: cfg.Servers[0].ID, Command: "x"})
+	assert.ErrorContains(t, cfg.Validate(), "duplicate server ID")
+}
+
+func TestValidateAcceptsServersReachedAtDefaultURL(t *testing.T) {
+	data := []byte(`{"servers": [{"id": "remote", "name": "Remote"}]}`)
+	lookup := func(value string) *config.Resolver {
+		return &config.Resolver{LookupEnv: func(name string) (string, bool) {
+			if name == "DEFAULT_MCP_URL" && value != "" {
+				return value, true
+			}
+			return "", false
+		}}
+	}
+
+	assert.Empty(t, config.ValidateData("config.json", data, lookup("http://localhost:9000/mcp")))
+
+	problems := config.ValidateData("config.json", data, nil)
+	require.Len(t, problems, 1)
+	assert.Equal(t, config.SeverityWarning, problems[0].Severity)
+	assert.Contains(t, problems[0].Message, "DEFAULT_MCP_URL")
+	assert.NoError(t, (&config.AppConfig{Servers: []config.MCPServer{{ID: "remote"}}}).Validate())
+
+	problems = config.ValidateData("config.json", data, lookup(""))
+	require.Len(t, problems, 1)
+	assert.Equal(t, config.SeverityError, problems[0].Severity)
+	assert.Contains(t, problems[0].Message, "unless DEFAULT_MCP_URL is set")
+}
+
+func TestValidateLayersChecksTheMergedConfig(t *testing.T) {
+	dir := t.TempDir()
+	user := filepath.Join(dir, "user.json")
+	require.NoError(t, os.WriteFile(user, []byte(`{"servers": [
+  {"id": "db", "name": "db", "command": "db", "args": ["--port", "8080"]}
+]}`), 0644))
+	project := filepath.Join(dir, "project.yaml")
+	require.NoError(t, os.WriteFile(project, []byte(`servers:
+  - id: cache
+    name: cache
+    command: cache
+  - id: api
+    name: api
+    command: api
+    args: ["--port=8080"]
+profiles:
+  dev:
+    servers: [db, api, nope]
+`), 0644))
+
+	problems, err := config.ValidateLayers([]config.Layer{
+		{Scope: config.ScopeUser, Path: user},
+		{Scope: config.ScopeProject, Path: project},
+	}, nil)
+	require.NoError(t, err)
+	require.Len(t, problems, 2)
+
+	// The port claimed in another file conflicts
+	assert.Equal(t, project, problems[0].File)
+	assert.Equal(t, "servers[1].args[0]", problems[0].Path)
+	assert.Equal(t, 8, problems[0].Line)
+	assert.Equal(t, config.SeverityError, problems[0].Severity)
+	assert.Contains(t, problems[0].Message, "conflicts with server db")
+
+	// Servers of other files are known to profiles
+	assert.Equal(t, project, problems[1].File)
+	assert.Equal(t, "profiles.dev.servers[2]", problems[1].Path)
+	assert.Equal(t, 11, problems[1].Line)
+	assert.Equal(t, `no server has ID "nope"`, problems[1].Message)
+}